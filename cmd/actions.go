@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"mdc/internal/config"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var actionsCmd = &cobra.Command{
+	Use:   "actions [config-name]",
+	Short: "List the actions defined in a config",
+	Long: `List the actions that can be run with "mdc run", along with the projects
+that define them. up and down are listed too; they are run with "mdc up" and
+"mdc down".`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		actions := listActions(cfg)
+		if jsonOutput() {
+			printJSON(actions)
+			return
+		}
+		printActions(actions)
+	},
+}
+
+type actionJSON struct {
+	Name     string   `json:"name"`
+	Projects []string `json:"projects"`
+}
+
+func listActions(cfg *config.Config) []actionJSON {
+	actions := []actionJSON{}
+	for _, name := range cfg.ActionNames() {
+		actions = append(actions, actionJSON{Name: name, Projects: cfg.ProjectsWithAction(name)})
+	}
+	return actions
+}
+
+func printActions(actions []actionJSON) {
+	if len(actions) == 0 {
+		fmt.Println("No actions defined.")
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"ACTION", "PROJECTS"})
+	for _, a := range actions {
+		t.AppendRow(table.Row{a.Name, strings.Join(a.Projects, ", ")})
+	}
+	t.Render()
+}
+
+func init() {
+	rootCmd.AddCommand(actionsCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"mdc/internal/pidfile"
+	"mdc/internal/runner"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+// crashNoticeLines is how many log lines a crash notice shows.
+const crashNoticeLines = 5
+
+// notifyCrashes records the exit of tracked background processes that
+// stopped running since the last mdc command, and prints a notice for each
+// one that crashed: exited with a non-zero code, was killed by a signal (e.g.
+// SIGKILL by the OOM killer) or disappeared without a trace. Each crash is
+// reported once. Notices go to stderr so that they do not mix with the
+// command's own (possibly JSON) output.
+func notifyCrashes(cmd *cobra.Command) {
+	if !wantsCrashNotice(cmd) {
+		return
+	}
+	configNames, err := pidfile.ConfigNames()
+	if err != nil {
+		return
+	}
+	for _, configName := range configNames {
+		exited, err := pidfile.RecordExits(configName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to record exited processes of %q: %v\n", configName, err)
+		}
+		for _, x := range exited {
+			if x.Entry.Exit.Crashed() {
+				printCrashNotice(configName, x)
+			}
+		}
+	}
+}
+
+// wantsCrashNotice excludes commands that are not run by the user directly:
+// the exec helper, shell completion, and the supervisor, which handles
+// crashes itself and may be running detached.
+func wantsCrashNotice(cmd *cobra.Command) bool {
+	if cmd.Hidden || cmd == superviseCmd {
+		return false
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if strings.HasPrefix(c.Name(), "__") || c.Name() == "completion" {
+			return false
+		}
+	}
+	return true
+}
+
+func printCrashNotice(configName string, x pidfile.Exited) {
+	e := x.Entry
+	red := text.Colors{text.FgRed, text.Bold}
+	headline := fmt.Sprintf("💥 %s/%s: %q (PID %d) crashed: %s", configName, x.Project, e.Command, e.PID, e.Exit)
+	if e.Exit.Known() {
+		headline += " at " + e.Exit.ExitedAt.Local().Format(timeLayout)
+	}
+	if d := e.Runtime(e.Exit.ExitedAt); d > 0 {
+		headline += " after " + formatDuration(d)
+	}
+	fmt.Fprintln(os.Stderr, red.Sprint(headline))
+
+	if logPath, err := pidfile.ProcLogFilePath(configName, x.Project, e.PID); err == nil {
+		if tail := runner.TailFile(logPath, crashNoticeLines); tail != "" {
+			for _, line := range strings.Split(tail, "\n") {
+				fmt.Fprintf(os.Stderr, "   %s %s\n", text.Colors{text.Faint}.Sprint("│"), line)
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "   Run \"mdc proc inspect %d\" for details.\n", e.PID)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import "testing"
+
+func TestWantsCrashNotice(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"proc", "list"}, true},
+		{[]string{"up", "demo"}, true},
+		{[]string{"supervise", "demo"}, false},
+		{[]string{"__exec", "-log", "x"}, false},
+	}
+	for _, tt := range tests {
+		cmd, _, err := rootCmd.Find(tt.args)
+		if err != nil {
+			t.Fatalf("Find(%v) error: %v", tt.args, err)
+		}
+		if got := wantsCrashNotice(cmd); got != tt.want {
+			t.Errorf("wantsCrashNotice(%s) = %v, want %v", cmd.CommandPath(), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/dashboard"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+	"mdc/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+// dashboardAttachTail is how much history "attach" shows before streaming.
+const dashboardAttachTail = 100
+
+var dashboardInterval time.Duration
+
+var dashboardCmd = &cobra.Command{
+	Use:     "dashboard <config-name>",
+	Aliases: []string{"dash"},
+	Short:   "Interactive dashboard of containers, background processes and logs",
+	Long: `Open a full-screen view of a config that combines the containers from
+"docker compose ps", the background processes tracked by mdc and a log pane
+for the selected process.
+
+Keys:
+  ↑/↓, j/k   select a row
+  s          stop the selected background process
+  r          restart the selected background process
+  a, Enter   attach to the selected background process (Ctrl-C to return)
+  u / d      run "up" / "down" for the selected project
+  /          filter rows (Enter to apply, Esc to clear)
+  R          refresh now
+  q          quit`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if dashboardInterval <= 0 {
+			fmt.Fprintln(os.Stderr, "--interval must be positive")
+			os.Exit(1)
+		}
+		configName := args[0]
+		cfg, err := config.Load(configName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		err = dashboard.Run(dashboard.Options{
+			ConfigName: configName,
+			Config:     cfg,
+			Interval:   dashboardInterval,
+			Attach: func(project string, entry pidfile.Entry) error {
+				return attachProcess(configName, project, entry, dashboardAttachTail, true)
+			},
+			Up: func(project string) error {
+				release, err := lockConfig(configName)
+				if err != nil {
+					return err
+				}
+				defer release()
+				return runProjectAction(configName, project, "up")
+			},
+			Down: func(project string) error {
+				release, err := lockConfig(configName)
+				if err != nil {
+					return err
+				}
+				defer release()
+				if err := runProjectAction(configName, project, "down"); err != nil {
+					return err
+				}
+				return pidfile.KillProjectsWithCallback(configName, []string{project}, logger.Stop, cfg.Logs.KeepStopped)
+			},
+			Lock: func() (func(), error) {
+				return lockConfig(configName)
+			},
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// runProjectAction runs an action for a single project of the config.
+func runProjectAction(configName, project, action string) error {
+	cfg, err := loadSelected(configName, config.Selector{Only: []string{project}})
+	if err != nil {
+		return err
+	}
+	return runner.Run(cfg, action, configName)
+}
+
+func init() {
+	dashboardCmd.Flags().DurationVar(&dashboardInterval, "interval", 2*time.Second, "Refresh interval")
+	rootCmd.AddCommand(dashboardCmd)
+}
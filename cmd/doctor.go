@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"mdc/internal/doctor"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check mdc state and the host tools for problems",
+	Long: `Cross-check the configs, PID files and proc logs in ~/.config/mdc with the
+running processes and the tools mdc relies on. Reported problems:
+
+  - entries of background processes that are no longer running
+  - PID files and logs of configs that were removed (e.g. with "mdc rm")
+  - pending logs left by failed starts and logs of unknown projects
+  - configs that cannot be loaded and project paths that do not exist
+  - a missing docker binary or Compose plugin, and Docker Compose V1
+
+With --fix, leftover state is cleaned up: dead entries and orphan logs are
+removed, and processes of removed configs are stopped. The other problems
+need to be fixed by hand. The command exits with status 1 while errors remain.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		findings, err := doctor.Diagnose()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if doctorFix {
+			doctor.Fix(findings)
+		}
+
+		if jsonOutput() {
+			printJSON(doctorJSON(findings))
+		} else {
+			printDoctorReport(findings)
+		}
+		for _, f := range findings {
+			if f.Severity == doctor.SeverityError && !f.Fixed {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+type doctorFindingJSON struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Config   string `json:"config,omitempty"`
+	Project  string `json:"project,omitempty"`
+	PID      int    `json:"pid,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+	Fixable  bool   `json:"fixable"`
+	Fixed    bool   `json:"fixed"`
+	FixError string `json:"fix_error,omitempty"`
+}
+
+func doctorJSON(findings []*doctor.Finding) []doctorFindingJSON {
+	result := make([]doctorFindingJSON, 0, len(findings))
+	for _, f := range findings {
+		j := doctorFindingJSON{
+			Check:    string(f.Check),
+			Severity: string(f.Severity),
+			Config:   f.Config,
+			Project:  f.Project,
+			PID:      f.PID,
+			Path:     f.Path,
+			Message:  f.Message,
+			Fixable:  f.Fixable(),
+			Fixed:    f.Fixed,
+		}
+		if f.FixErr != nil {
+			j.FixError = f.FixErr.Error()
+		}
+		result = append(result, j)
+	}
+	return result
+}
+
+func printDoctorReport(findings []*doctor.Finding) {
+	if len(findings) == 0 {
+		fmt.Println("✅ No problems found.")
+		return
+	}
+
+	fixable := 0
+	fixed := 0
+	for _, f := range findings {
+		icon := "⚠️ "
+		if f.Severity == doctor.SeverityError {
+			icon = "❌"
+		}
+		fmt.Printf("%s %s %s\n", icon, text.Colors{text.Faint}.Sprintf("[%s]", f.Check), doctorSubject(f)+f.Message)
+		if f.Path != "" {
+			fmt.Printf("     %s\n", shortenHome(f.Path))
+		}
+		switch {
+		case f.Fixed:
+			fixed++
+			fmt.Println(text.Colors{text.FgGreen}.Sprint("     🧹 Fixed"))
+		case f.FixErr != nil:
+			fmt.Println(text.Colors{text.FgRed}.Sprintf("     Fix failed: %v", f.FixErr))
+		case f.Fixable():
+			fixable++
+		}
+	}
+
+	fmt.Println()
+	summary := fmt.Sprintf("%d problem(s) found", len(findings))
+	if doctorFix {
+		summary += fmt.Sprintf(", %d fixed", fixed)
+	} else if fixable > 0 {
+		summary += fmt.Sprintf(`, %d can be fixed with "mdc doctor --fix"`, fixable)
+	}
+	fmt.Println(summary)
+}
+
+// doctorSubject names what a finding is about, e.g. "myproject/web (PID 123): ".
+func doctorSubject(f *doctor.Finding) string {
+	subject := f.Config
+	if f.Project != "" {
+		subject += "/" + f.Project
+	}
+	if f.PID != 0 {
+		subject += fmt.Sprintf(" (PID %d)", f.PID)
+	}
+	if subject == "" {
+		return ""
+	}
+	return subject + ": "
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Clean up dead entries and orphan logs, and stop processes of removed configs")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -6,40 +6,80 @@ import (
 
 	"mdc/internal/logger"
 	"mdc/internal/pidfile"
+	"mdc/internal/runner"
 
 	"github.com/spf13/cobra"
 )
 
-var downDryRun bool
+var (
+	downDryRun bool
+	downSelect selectionFlags
+	downLock   lockFlags
+)
 
 var downCmd = &cobra.Command{
 	Use:   "down [config-name]",
 	Short: "Stop all projects defined in a config",
-	Args:  cobra.ExactArgs(1),
+	Long: `Stop all projects defined in a config: run their down commands, then stop
+the background processes mdc started for them.
+
+` + runLockUsage,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		configName := args[0]
-		loadAndRun(configName, "down", downDryRun)
+		sel := downSelect.selector()
+		if !downDryRun {
+			downLock.lock(configName)
+		}
+		loadAndRun(configName, "down", downDryRun, sel, runner.Options{})
+
+		// Without a selection, every tracked process of the config is
+		// stopped, including those of projects no longer in the config.
+		var projectNames []string
+		if !sel.IsEmpty() {
+			cfg, err := loadSelected(configName, sel)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			projectNames = cfg.ProjectNames()
+		}
 
 		if downDryRun {
-			printDryRunStopEntries(configName)
+			printDryRunStopEntries(configName, projectNames)
 			return
 		}
 
-		if err := pidfile.KillAllWithCallback(configName, logger.Stop); err != nil {
+		keepLogs := keepStoppedLogs(configName)
+		var err error
+		if projectNames == nil {
+			err = pidfile.KillAllWithCallback(configName, logger.Stop, keepLogs)
+		} else {
+			err = pidfile.KillProjectsWithCallback(configName, projectNames, logger.Stop, keepLogs)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to clean up background processes: %v\n", err)
 		}
 	},
 }
 
-func printDryRunStopEntries(configName string) {
+// printDryRunStopEntries lists the running processes that "down" would stop.
+// A nil projectNames means all projects.
+func printDryRunStopEntries(configName string, projectNames []string) {
 	projects, err := pidfile.LoadAll(configName)
 	if err != nil || len(projects) == 0 {
 		return
 	}
+	if projectNames != nil {
+		projects = filterProjects(projects, projectNames)
+		if len(projects) == 0 {
+			return
+		}
+	}
 	logger.DryRunStopHeader()
 	for projectName, entries := range projects {
 		for _, e := range entries {
-			if pidfile.IsRunning(e.PID) {
+			if e.Status() == pidfile.StatusRunning {
 				logger.DryRunStopEntry(projectName, e.Command, e.PID)
 			}
 		}
@@ -48,5 +88,7 @@ func printDryRunStopEntries(configName string) {
 
 func init() {
 	downCmd.Flags().BoolVar(&downDryRun, "dry-run", false, "Print execution plan without running commands")
+	downSelect.register(downCmd)
+	downLock.register(downCmd)
 	rootCmd.AddCommand(downCmd)
 }
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"os"
+
+	"mdc/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+// execCmd is the helper that runs background commands; it is started by mdc
+// itself and not meant to be run by hand.
+var execCmd = &cobra.Command{
+	Use:                runner.ExecHelperCommand + " -log <file> -- <command>",
+	Short:              "Run a background command with its output logged (internal)",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runner.RunExecHelper(args))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+}
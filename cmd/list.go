@@ -20,6 +20,13 @@ var listCmd = &cobra.Command{
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
+		if jsonOutput() {
+			if files == nil {
+				files = []string{}
+			}
+			printJSON(files)
+			return
+		}
 		if len(files) == 0 {
 			fmt.Println("No config files found in ~/.config/mdc/")
 			return
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"mdc/internal/pidfile"
+
+	"github.com/spf13/cobra"
+)
+
+// runLockUsage describes the run lock in the help of the commands taking it.
+const runLockUsage = `Only one mdc command at a time can start or stop the processes of a config:
+while "up", "down", "restart", "run" or "proc stop/restart" works on it, others
+fail with an error naming the command holding the lock, or wait for it with
+--wait (and --timeout). The lock is released when its holder exits, even when
+it crashes.`
+
+// lockFlags holds the --wait and --timeout flags of commands that take the
+// run lock of a config, so that two of them never change the same
+// environment at once.
+type lockFlags struct {
+	wait    bool
+	timeout time.Duration
+}
+
+func (f *lockFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.wait, "wait", false, "Wait for another mdc command working on the config to finish")
+	cmd.Flags().DurationVar(&f.timeout, "timeout", 0, "Give up waiting after this long (e.g. 2m; implies --wait)")
+}
+
+// heldRunLocks keeps the run locks taken by lockFlags.lock until mdc exits;
+// an unreferenced lock file would be closed, and unlocked, by the garbage
+// collector.
+var heldRunLocks []*pidfile.RunLock
+
+// lock takes the run lock of each config, in name order so that two
+// commands locking several configs cannot deadlock. It exits when a lock is
+// held by another mdc command and --wait was not given or timed out. The
+// locks are released when mdc exits.
+func (f *lockFlags) lock(configNames ...string) {
+	names := slices.Clone(configNames)
+	slices.Sort(names)
+	for _, name := range slices.Compact(names) {
+		lock, err := f.acquire(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		heldRunLocks = append(heldRunLocks, lock)
+	}
+}
+
+// releaseRunLocks releases the locks taken by lockFlags.lock. Commands that
+// exit with an error leave it to the operating system, which releases the
+// lock but keeps the owner in the lock file until the next command takes it.
+func releaseRunLocks() {
+	for _, lock := range heldRunLocks {
+		_ = lock.Release()
+	}
+	heldRunLocks = nil
+}
+
+func (f *lockFlags) acquire(configName string) (*pidfile.RunLock, error) {
+	wait := f.wait || f.timeout > 0
+	lock, err := pidfile.AcquireRunLock(configName, false, 0)
+	var locked *pidfile.LockedError
+	if !wait || !errors.As(err, &locked) {
+		return lock, err
+	}
+	owner := "another mdc process"
+	if locked.Owner != nil {
+		owner = locked.Owner.String()
+	}
+	fmt.Fprintf(os.Stderr, "⏳ Waiting for %s to finish with %q...\n", owner, configName)
+	return pidfile.AcquireRunLock(configName, true, f.timeout)
+}
+
+// lockConfig takes the run lock of a config without waiting, for actions
+// that report errors instead of exiting, and returns the function releasing
+// it.
+func lockConfig(configName string) (func(), error) {
+	lock, err := pidfile.AcquireRunLock(configName, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := lock.Release(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to release the lock of %q: %v\n", configName, err)
+		}
+	}, nil
+}
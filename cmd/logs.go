@@ -0,0 +1,391 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+	"mdc/internal/runner"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+const (
+	logsPollInterval = 100 * time.Millisecond
+	// logsDiscoverEvery is how many polls pass between checks for started,
+	// restarted and stopped processes.
+	logsDiscoverEvery = 5
+)
+
+var (
+	logsSince    string
+	logsTail     int
+	logsGrep     string
+	logsNoColor  bool
+	logsNoFollow bool
+	logsCompose  bool
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <config-name> [project...]",
+	Short: "Stream the logs of all background processes of a config",
+	Long: `Follow the proc logs of every background process of a config at once, each
+line prefixed with its project. Processes started or restarted while streaming
+are picked up automatically. With --compose, the output of
+"docker compose logs" of each project directory is merged in.
+
+--since filters proc logs by the timestamp mdc records on each line. Logs
+written by earlier versions have none; they are skipped as a whole when they
+were last written before the given time.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configName := args[0]
+		cfg, err := loadSelected(configName, config.Selector{Only: args[1:]})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		since, err := parseSince(logsSince, time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		filter := &logFilter{strip: logsNoColor || jsonOutput(), since: since}
+		if logsGrep != "" {
+			if filter.grep, err = regexp.Compile(logsGrep); err != nil {
+				fmt.Fprintf(os.Stderr, "invalid --grep pattern: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if logsNoColor {
+			text.DisableColors()
+		}
+
+		var projects map[string]bool
+		if len(args) > 1 {
+			projects = make(map[string]bool, len(cfg.Projects))
+			for _, name := range cfg.ProjectNames() {
+				projects[name] = true
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		s := &logStreamer{
+			configName: configName,
+			projects:   projects,
+			tail:       logsTail,
+			since:      since,
+			follow:     !logsNoFollow,
+			filter:     filter,
+		}
+		s.run(ctx, cfg)
+	},
+}
+
+// parseSince accepts a duration relative to now (e.g. "10m") or an RFC 3339
+// timestamp. An empty string means no limit.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q: use a duration such as 10m or an RFC 3339 timestamp", s)
+}
+
+// logFilter decides which lines are shown and how.
+type logFilter struct {
+	grep *regexp.Regexp
+	// strip removes escape sequences (colors, cursor movement) from the
+	// lines themselves.
+	strip bool
+	// since drops proc log lines written before it.
+	since time.Time
+}
+
+// logLineWriter splits a stream into lines and writes each line that passes
+// the filter with the project prefix. Each writer is used by a single
+// goroutine; the logger serializes the output of all writers.
+type logLineWriter struct {
+	project string
+	pid     int
+	filter  *logFilter
+	buf     []byte
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a trailing line that has no newline yet.
+func (w *logLineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *logLineWriter) emit(b []byte) {
+	t, line, ok := pidfile.SplitLogLine(strings.TrimRight(string(b), "\r"))
+	if ok && t.Before(w.filter.since) {
+		return
+	}
+	plain := text.StripEscape(line)
+	if w.filter.grep != nil && !w.filter.grep.MatchString(plain) {
+		return
+	}
+	if w.filter.strip {
+		line = plain
+	}
+	logger.LogLine(w.project, w.pid, line)
+}
+
+// procLogFollower reads the proc log of one background process. It is tied
+// to the file rather than the path: the supervisor renames the log of a
+// restarted process after the new PID, and the follower keeps reading it.
+// When the log is rotated, the follower moves on to the new file (see
+// switchTo).
+type procLogFollower struct {
+	project string
+	file    *os.File
+	info    os.FileInfo
+	w       *logLineWriter
+}
+
+// poll writes what was appended since the last call.
+func (f *procLogFollower) poll() {
+	_, _ = io.Copy(f.w, f.file)
+}
+
+// switchTo finishes the current file and continues with the log at path
+// from its start, after the log was rotated.
+func (f *procLogFollower) switchTo(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.poll()
+	_ = f.file.Close()
+	f.file, f.info = file, info
+	return nil
+}
+
+func (f *procLogFollower) close() {
+	f.poll()
+	f.w.Flush()
+	_ = f.file.Close()
+}
+
+// logStreamer multiplexes the proc logs of a config.
+type logStreamer struct {
+	configName string
+	// projects limits the output to these projects; nil shows all.
+	projects  map[string]bool
+	tail      int
+	since     time.Time
+	follow    bool
+	filter    *logFilter
+	followers []*procLogFollower
+}
+
+func (s *logStreamer) run(ctx context.Context, cfg *config.Config) {
+	s.discover(true)
+
+	var wg sync.WaitGroup
+	if logsCompose {
+		opts := runner.ComposeLogsOptions{Follow: s.follow, Tail: s.tail, Since: logsSince, NoColor: s.filter.strip}
+		for _, p := range cfg.Projects {
+			wg.Add(1)
+			go func(p config.Project) {
+				defer wg.Done()
+				w := &logLineWriter{project: p.Name, filter: s.filter}
+				err := runner.StreamComposeLogs(ctx, p.Path, opts, w)
+				w.Flush()
+				if err != nil {
+					logger.Warn(p.Name, fmt.Sprintf("docker compose logs failed: %v", err))
+				}
+			}(p)
+		}
+	}
+
+	if s.follow {
+		ticker := time.NewTicker(logsPollInterval)
+		defer ticker.Stop()
+	loop:
+		for n := 1; ; n++ {
+			select {
+			case <-ctx.Done():
+				break loop
+			case <-ticker.C:
+				if n%logsDiscoverEvery == 0 {
+					s.discover(false)
+				}
+				for _, f := range s.followers {
+					f.poll()
+				}
+			}
+		}
+	}
+
+	for _, f := range s.followers {
+		f.close()
+	}
+	wg.Wait()
+}
+
+// discover starts following the logs of tracked processes that are not
+// followed yet and stops following the logs of processes that are no longer
+// tracked. On the initial call the existing content is limited by --tail and
+// --since; logs that show up later are read from the start.
+func (s *logStreamer) discover(initial bool) {
+	tracked, err := pidfile.LoadAll(s.configName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to load PID files: %v\n", err)
+		return
+	}
+	projectNames := make([]string, 0, len(tracked))
+	for name := range tracked {
+		if s.projects == nil || s.projects[name] {
+			projectNames = append(projectNames, name)
+		}
+	}
+	sort.Strings(projectNames)
+
+	var next []*procLogFollower
+	for _, project := range projectNames {
+		for _, e := range tracked[project] {
+			path, err := pidfile.ProcLogFilePath(s.configName, project, e.PID)
+			if err != nil {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if f := s.find(info); f != nil {
+				f.w.pid = e.PID
+				next = append(next, f)
+				continue
+			}
+			// A new file for a followed PID: the log was rotated.
+			if f := s.findPID(e.PID); f != nil {
+				if err := f.switchTo(path); err == nil {
+					next = append(next, f)
+					continue
+				}
+			}
+			f, err := s.open(project, e, path, initial)
+			if err != nil {
+				continue
+			}
+			if !initial {
+				logger.Attach(project, e.Command, e.PID)
+			}
+			f.poll()
+			next = append(next, f)
+		}
+	}
+
+	for _, f := range s.followers {
+		if !containsFollower(next, f) {
+			f.close()
+			logger.ProcessExited(f.project, f.w.pid)
+		}
+	}
+	s.followers = next
+}
+
+func (s *logStreamer) find(info os.FileInfo) *procLogFollower {
+	for _, f := range s.followers {
+		if os.SameFile(f.info, info) {
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *logStreamer) findPID(pid int) *procLogFollower {
+	for _, f := range s.followers {
+		if f.w.pid == pid {
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *logStreamer) open(project string, e pidfile.Entry, path string, initial bool) (*procLogFollower, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	w := &logLineWriter{project: project, pid: e.PID, filter: s.filter}
+	if initial {
+		switch {
+		case !s.since.IsZero() && info.ModTime().Before(s.since):
+			_, _ = file.Seek(0, io.SeekEnd)
+		case s.tail > 0:
+			if got := seekToLastNLines(file, s.tail); got < s.tail {
+				writeRotatedTail(w, path, s.tail-got, s.since)
+			}
+		}
+	}
+	return &procLogFollower{project: project, file: file, info: info, w: w}, nil
+}
+
+func containsFollower(followers []*procLogFollower, f *procLogFollower) bool {
+	for _, other := range followers {
+		if other == f {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Only show logs written since a duration ago (e.g. 10m) or an RFC 3339 timestamp")
+	logsCmd.Flags().IntVar(&logsTail, "tail", 0, "Number of lines to show from the end of each log (0 shows the whole log)")
+	logsCmd.Flags().StringVar(&logsGrep, "grep", "", "Only show lines matching this regular expression")
+	logsCmd.Flags().BoolVar(&logsNoColor, "no-color", false, "Disable colors, including those in the logs themselves")
+	logsCmd.Flags().BoolVar(&logsNoFollow, "no-follow", false, "Print existing logs and exit without streaming")
+	logsCmd.Flags().BoolVar(&logsCompose, "compose", false, `Merge in "docker compose logs" of each project directory`)
+	rootCmd.AddCommand(logsCmd)
+}
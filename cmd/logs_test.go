@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+func captureLogLines(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	text.DisableColors()
+	logger.SetOutput(&buf)
+	t.Cleanup(func() {
+		logger.SetOutput(os.Stdout)
+		text.EnableColors()
+	})
+	return &buf
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "", want: time.Time{}},
+		{in: "10m", want: now.Add(-10 * time.Minute)},
+		{in: "2026-01-01T09:00:00Z", want: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{in: "-5m", wantErr: true},
+		{in: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSince(tt.in, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseSince(%q) = %v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSince(%q) error: %v", tt.in, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogLineWriter(t *testing.T) {
+	buf := captureLogLines(t)
+	w := &logLineWriter{
+		project: "web",
+		filter:  &logFilter{grep: regexp.MustCompile(`error|warn`), strip: true},
+	}
+
+	_, _ = w.Write([]byte("info: starting\n\x1b[31merror: boom\x1b[0m\r\nwar"))
+	_, _ = w.Write([]byte("n: slow\ntrailing error"))
+	if got, want := buf.String(), "[web] error: boom\n[web] warn: slow\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	w.Flush()
+	if !strings.HasSuffix(buf.String(), "[web] trailing error\n") {
+		t.Errorf("Flush() should write the trailing line, got %q", buf.String())
+	}
+}
+
+func TestLogLineWriterSince(t *testing.T) {
+	buf := captureLogLines(t)
+	w := &logLineWriter{
+		project: "web",
+		filter:  &logFilter{since: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	_, _ = w.Write([]byte("2026-01-01T11:59:59.999Z old\n" +
+		"2026-01-01T12:00:00.000Z new\n" +
+		"no timestamp\n"))
+	if got, want := buf.String(), "[web] new\n[web] no timestamp\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestLogStreamerDiscover(t *testing.T) {
+	buf := captureLogLines(t)
+	old := pidfile.BaseDir
+	pidfile.BaseDir = t.TempDir()
+	defer func() { pidfile.BaseDir = old }()
+
+	writeProcLog := func(project string, pid int, content string) string {
+		t.Helper()
+		path, err := pidfile.ProcLogFilePath("cfg", project, pid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	webLog := writeProcLog("web", 100, "w1\nw2\nw3\n")
+	writeProcLog("api", 200, "a1\n")
+	writeProcLog("worker", 300, "k1\n")
+	for project, pid := range map[string]int{"web": 100, "api": 200, "worker": 300} {
+		if err := pidfile.Save("cfg", project, []pidfile.Entry{{PID: pid, Command: "run " + project}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &logStreamer{
+		configName: "cfg",
+		projects:   map[string]bool{"web": true, "api": true},
+		tail:       2,
+		follow:     true,
+		filter:     &logFilter{},
+	}
+	s.discover(true)
+	if got, want := buf.String(), "[api] a1\n[web] w2\n[web] w3\n"; got != want {
+		t.Fatalf("initial output = %q, want %q", got, want)
+	}
+
+	// A restart renames the log after the new PID: the same file keeps
+	// being followed without repeating its content.
+	buf.Reset()
+	restarted := filepath.Join(filepath.Dir(webLog), "101.log")
+	if err := os.Rename(webLog, restarted); err != nil {
+		t.Fatal(err)
+	}
+	if err := pidfile.Save("cfg", "web", []pidfile.Entry{{PID: 101, Command: "run web"}}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(restarted, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("w4\n")
+	_ = f.Close()
+
+	// A process started while streaming is read from the start.
+	writeProcLog("api", 201, "b1\n")
+	if err := pidfile.Append("cfg", "api", pidfile.Entry{PID: 201, Command: "run api2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.discover(false)
+	for _, f := range s.followers {
+		f.poll()
+	}
+	out := buf.String()
+	for _, want := range []string{"[web] w4\n", "Attached: run api2 (PID: 201)", "[api] b1\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q: %q", want, out)
+		}
+	}
+	if strings.Contains(out, "w3") || strings.Contains(out, "k1") {
+		t.Errorf("output repeats old lines or shows unselected projects: %q", out)
+	}
+
+	// A rotation renames the log and starts a new one: the rest of the old
+	// file is shown before the new one is followed from its start.
+	buf.Reset()
+	f, err = os.OpenFile(restarted, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("w5\n")
+	_ = f.Close()
+	if err := os.Rename(restarted, restarted+".1"); err != nil {
+		t.Fatal(err)
+	}
+	writeProcLog("web", 101, "w6\n")
+	s.discover(false)
+	for _, f := range s.followers {
+		f.poll()
+	}
+	if got := buf.String(); !strings.Contains(got, "[web] w5\n[web] w6\n") || strings.Contains(got, "Process exited") {
+		t.Errorf("output after rotation = %q, want w5 then w6", got)
+	}
+
+	// A process that is no longer tracked is dropped.
+	buf.Reset()
+	if err := pidfile.RemoveEntry("cfg", "api", 200); err != nil {
+		t.Fatal(err)
+	}
+	s.discover(false)
+	if !strings.Contains(buf.String(), "Process exited (PID: 200)") {
+		t.Errorf("output = %q, want process exited notice", buf.String())
+	}
+	if len(s.followers) != 2 {
+		t.Errorf("got %d followers, want 2", len(s.followers))
+	}
+	for _, f := range s.followers {
+		f.close()
+	}
+}
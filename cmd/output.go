@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var outputFormat string
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, `Output format: "text" or "json"`)
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := applyOutputFormat(); err != nil {
+			return err
+		}
+		// Recorded in the process history as the cause of what this run does.
+		pidfile.Trigger = strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+		notifyCrashes(cmd)
+		return nil
+	}
+}
+
+func applyOutputFormat() error {
+	switch outputFormat {
+	case outputText:
+		logger.SetRenderer(logger.TextRenderer{})
+	case outputJSON:
+		logger.SetRenderer(logger.JSONRenderer{})
+	default:
+		return fmt.Errorf("invalid output format %q (must be %q or %q)", outputFormat, outputText, outputJSON)
+	}
+	return nil
+}
+
+func jsonOutput() bool {
+	return outputFormat == outputJSON
+}
+
+// printJSON writes v to stdout as a single JSON document.
+func printJSON(v any) {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
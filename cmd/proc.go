@@ -3,8 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
+	"mdc/internal/config"
 	"mdc/internal/pidfile"
 
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -23,6 +26,8 @@ Running "mdc proc" without a subcommand is equivalent to "mdc proc list".`,
 	},
 }
 
+var procListSelect selectionFlags
+
 var procListCmd = &cobra.Command{
 	Use:   "list [config-name]",
 	Short: "List background processes managed by mdc",
@@ -56,6 +61,16 @@ func printProcTable(args []string) {
 		}
 	}
 
+	sel := procListSelect.selector()
+	if !sel.IsEmpty() {
+		allData = selectProcEntries(allData, sel)
+	}
+
+	if jsonOutput() {
+		printJSON(procJSON(allData))
+		return
+	}
+
 	if len(allData) == 0 {
 		fmt.Println("No background processes found.")
 		return
@@ -63,22 +78,181 @@ func printProcTable(args []string) {
 
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"CONFIG", "PROJECT", "COMMAND", "DIR", "PID", "STATUS"})
+	t.AppendHeader(table.Row{"CONFIG", "PROJECT", "NAME", "COMMAND", "DIR", "PID", "RESTARTS", "RUNTIME", "STATUS"})
+	now := time.Now()
 	for configName, projects := range allData {
 		for projectName, entries := range projects {
 			for _, e := range entries {
-				status := text.Colors{text.FgRed}.Sprint("Dead")
-				if pidfile.IsRunning(e.PID) {
-					status = text.Colors{text.FgGreen}.Sprint("Running")
+				s := e.Status()
+				status := colorStatus(s)
+				if exit, ok := exitStatus(configName, projectName, e, s); ok {
+					status = text.Colors{text.FgRed}.Sprint(describeExit(exit, now))
+					e.Exit = &exit
 				}
 				command := text.Colors{text.FgCyan}.Sprint(e.Command)
-				t.AppendRow(table.Row{configName, projectName, command, shortenHome(e.Dir), e.PID, status})
+				t.AppendRow(table.Row{configName, projectName, e.Name, command, shortenHome(e.Dir), e.PID, e.Restarts, formatDuration(e.Runtime(now)), status})
 			}
 		}
 	}
 	t.Render()
 }
 
+type procEntryJSON struct {
+	Config   string `json:"config"`
+	Project  string `json:"project"`
+	Name     string `json:"name,omitempty"`
+	Command  string `json:"command"`
+	Dir      string `json:"dir"`
+	PID      int    `json:"pid"`
+	Restarts int    `json:"restarts"`
+	Running  bool   `json:"running"`
+	Status   string `json:"status"`
+	// ExitCode and Signal are set for processes whose exit was recorded.
+	ExitCode  *int      `json:"exit_code,omitempty"`
+	Signal    string    `json:"signal,omitempty"`
+	StartedAt time.Time `json:"started_at,omitzero"`
+	ExitedAt  time.Time `json:"exited_at,omitzero"`
+	// RuntimeSeconds is how long the process ran, or has been running.
+	RuntimeSeconds int64 `json:"runtime_seconds,omitempty"`
+}
+
+// exitStatus returns the recorded exit status of a tracked process that is
+// no longer running.
+func exitStatus(configName, projectName string, e pidfile.Entry, s pidfile.Status) (pidfile.ExitStatus, bool) {
+	if s == pidfile.StatusRunning {
+		return pidfile.ExitStatus{}, false
+	}
+	return e.LastExit(configName, projectName)
+}
+
+// describeExit renders an exit status with how long ago the process ended,
+// e.g. "Exited (code 1) 5m0s ago".
+func describeExit(exit pidfile.ExitStatus, now time.Time) string {
+	if !exit.Known() {
+		return exit.String()
+	}
+	return fmt.Sprintf("%s %s ago", exit, formatDuration(now.Sub(exit.ExitedAt)))
+}
+
+// formatDuration rounds a duration to seconds for display. Unknown
+// durations are shown as "-".
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+// colorStatus renders a process status for the table: a stale entry's PID
+// is alive but belongs to an unrelated process.
+func colorStatus(s pidfile.Status) string {
+	switch s {
+	case pidfile.StatusRunning:
+		return text.Colors{text.FgGreen}.Sprint(s)
+	case pidfile.StatusStale:
+		return text.Colors{text.FgYellow}.Sprint(s)
+	default:
+		return text.Colors{text.FgRed}.Sprint(s)
+	}
+}
+
+// procJSON flattens tracked processes into a list sorted by config and
+// project, so the output is stable across runs.
+func procJSON(allData map[string]map[string][]pidfile.Entry) []procEntryJSON {
+	result := []procEntryJSON{}
+	now := time.Now()
+	for configName, projects := range allData {
+		for projectName, entries := range projects {
+			for _, e := range entries {
+				result = append(result, procEntryToJSON(configName, projectName, e, now))
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Config != result[j].Config {
+			return result[i].Config < result[j].Config
+		}
+		return result[i].Project < result[j].Project
+	})
+	return result
+}
+
+func procEntryToJSON(configName, projectName string, e pidfile.Entry, now time.Time) procEntryJSON {
+	status := e.Status()
+	j := procEntryJSON{
+		Config:    configName,
+		Project:   projectName,
+		Name:      e.Name,
+		Command:   e.Command,
+		Dir:       e.Dir,
+		PID:       e.PID,
+		Restarts:  e.Restarts,
+		Running:   status == pidfile.StatusRunning,
+		Status:    string(status),
+		StartedAt: e.StartedAt,
+	}
+	if exit, ok := exitStatus(configName, projectName, e, status); ok {
+		j.Signal = exit.Signal
+		if exit.Signal == "" && exit.Known() {
+			j.ExitCode = &exit.Code
+		}
+		j.ExitedAt = exit.ExitedAt
+		e.Exit = &exit
+	}
+	j.RuntimeSeconds = int64(e.Runtime(now) / time.Second)
+	return j
+}
+
+// selectProcEntries narrows tracked processes down to the projects matched by
+// sel. Tags are resolved through each config file; configs that cannot be
+// loaded (e.g. removed files) only match by project name.
+func selectProcEntries(allData map[string]map[string][]pidfile.Entry, sel config.Selector) map[string]map[string][]pidfile.Entry {
+	result := make(map[string]map[string][]pidfile.Entry)
+	for configName, projects := range allData {
+		var names []string
+		if cfg, err := config.Load(configName); err == nil {
+			if selected, err := cfg.Select(sel); err == nil {
+				names = selected.ProjectNames()
+			}
+		} else if len(sel.Tags) == 0 {
+			names = matchProjectNames(projects, sel)
+		}
+		if filtered := filterProjects(projects, names); len(filtered) > 0 {
+			result[configName] = filtered
+		}
+	}
+	return result
+}
+
+func matchProjectNames(projects map[string][]pidfile.Entry, sel config.Selector) []string {
+	only := make(map[string]bool)
+	for _, n := range sel.Only {
+		only[n] = true
+	}
+	except := make(map[string]bool)
+	for _, n := range sel.Except {
+		except[n] = true
+	}
+	var names []string
+	for name := range projects {
+		if (len(only) == 0 || only[name]) && !except[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// filterProjects keeps only the entries of the named projects.
+func filterProjects(projects map[string][]pidfile.Entry, names []string) map[string][]pidfile.Entry {
+	result := make(map[string][]pidfile.Entry)
+	for _, name := range names {
+		if entries, ok := projects[name]; ok {
+			result[name] = entries
+		}
+	}
+	return result
+}
+
 func shortenHome(path string) string {
 	home, err := os.UserHomeDir()
 	if err != nil || home == "" {
@@ -94,6 +268,8 @@ func shortenHome(path string) string {
 }
 
 func init() {
+	procListSelect.register(procCmd)
+	procListSelect.register(procListCmd)
 	procCmd.AddCommand(procListCmd)
 	rootCmd.AddCommand(procCmd)
 }
@@ -6,7 +6,6 @@ import (
 	"io"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
@@ -22,113 +21,146 @@ var (
 )
 
 var procAttachCmd = &cobra.Command{
-	Use:   "attach <PID>",
+	Use:   "attach <process>",
 	Short: "Stream stdout/stderr of a background process",
 	Long: `Attach to a background process and stream its log output.
-Press Ctrl-C to detach (the process keeps running).`,
-	Args: cobra.ExactArgs(1),
+Press Ctrl-C to detach (the process keeps running).
+
+` + procRefUsage,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProcRefs,
 	Run: func(cmd *cobra.Command, args []string) {
-		pid, err := strconv.Atoi(args[0])
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "invalid PID: %s\n", args[0])
-			os.Exit(1)
-		}
+		ref := resolveProcArg(args[0])
 
-		configName, projectName, entry, err := pidfile.FindByPID(pid)
-		if err != nil {
+		if err := attachProcess(ref.Config, ref.Project, ref.Entry, tailLines, !noFollow); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
+	},
+}
 
-		logPath, err := pidfile.ProcLogFilePath(configName, projectName, pid)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to resolve log path: %v\n", err)
-			os.Exit(1)
-		}
+// attachProcess prints the log of a background process and, when follow is
+// set, keeps streaming it until the process exits or Ctrl-C is pressed.
+func attachProcess(configName, projectName string, entry pidfile.Entry, tail int, follow bool) error {
+	pid := entry.PID
+	logPath, err := pidfile.ProcLogFilePath(configName, projectName, pid)
+	if err != nil {
+		return fmt.Errorf("failed to resolve log path: %w", err)
+	}
 
-		if _, err := os.Stat(logPath); os.IsNotExist(err) {
-			fallback, fbErr := pidfile.ProcLogTmpPath(configName, projectName)
-			if fbErr == nil {
-				if _, stErr := os.Stat(fallback); stErr == nil {
-					logPath = fallback
-				}
-			}
-			if _, err := os.Stat(logPath); os.IsNotExist(err) {
-				fmt.Fprintf(os.Stderr, "log file not found: %s\n", logPath)
-				fmt.Fprintln(os.Stderr, "This process may have been started before log capture was enabled.")
-				os.Exit(1)
+	if _, err := os.Stat(logPath); os.IsNotExist(err) {
+		fallback, fbErr := pidfile.ProcLogTmpPath(configName, projectName)
+		if fbErr == nil {
+			if _, stErr := os.Stat(fallback); stErr == nil {
+				logPath = fallback
 			}
 		}
+		if _, err := os.Stat(logPath); os.IsNotExist(err) {
+			return fmt.Errorf("log file not found: %s\nThis process may have been started before log capture was enabled", logPath)
+		}
+	}
 
-		logger.Attach(projectName, entry.Command, pid)
+	logger.Attach(projectName, entry.Command, pid)
 
-		f, err := os.Open(logPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to open log file: %v\n", err)
-			os.Exit(1)
-		}
-		defer func() { _ = f.Close() }()
+	f, err := os.Open(logPath)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	out := pidfile.NewTimestampStripper(os.Stdout)
+	defer func() { _ = out.Flush() }()
 
-		if tailLines > 0 {
-			seekToLastNLines(f, tailLines)
+	if tail > 0 {
+		if got := seekToLastNLines(f, tail); got < tail {
+			writeRotatedTail(out, logPath, tail-got, time.Time{})
 		}
+	}
 
-		if noFollow {
-			if _, err := io.Copy(os.Stdout, f); err != nil {
-				fmt.Fprintf(os.Stderr, "read error: %v\n", err)
-				os.Exit(1)
-			}
-			return
+	if !follow {
+		if _, err := io.Copy(out, f); err != nil {
+			return fmt.Errorf("read error: %w", err)
 		}
+		return nil
+	}
 
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		defer signal.Stop(sigCh)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
 
-		reader := bufio.NewReader(f)
-		for {
-			line, err := reader.ReadBytes('\n')
-			if err == nil {
-				_, _ = os.Stdout.Write(line)
-				continue
-			}
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == nil {
+			_, _ = out.Write(line)
+			continue
+		}
 
-			if err != io.EOF {
-				fmt.Fprintf(os.Stderr, "\nread error: %v\n", err)
-				break
-			}
+		if err != io.EOF {
+			return fmt.Errorf("read error: %w", err)
+		}
 
-			if len(line) > 0 {
-				_, _ = os.Stdout.Write(line)
-			}
+		if len(line) > 0 {
+			_, _ = out.Write(line)
+			// Show a partial line (e.g. a prompt) while waiting.
+			_ = out.Flush()
+		}
 
-			if !pidfile.IsRunning(pid) {
-				remaining, _ := io.ReadAll(reader)
-				if len(remaining) > 0 {
-					_, _ = os.Stdout.Write(remaining)
-				}
-				logger.ProcessExited(projectName, pid)
-				break
-			}
+		// The log was rotated (renamed, with a new log in its place):
+		// finish the old file and continue with the new one.
+		if next, ok := reopenRotated(f, logPath); ok {
+			_, _ = io.Copy(out, reader)
+			_ = f.Close()
+			f = next
+			reader.Reset(f)
+			continue
+		}
 
-			select {
-			case <-sigCh:
-				logger.Detach(projectName)
-				return
-			case <-time.After(100 * time.Millisecond):
+		if entry.Status() != pidfile.StatusRunning {
+			remaining, _ := io.ReadAll(reader)
+			if len(remaining) > 0 {
+				_, _ = out.Write(remaining)
 			}
+			logger.ProcessExited(projectName, pid)
+			return nil
 		}
-	},
+
+		select {
+		case <-sigCh:
+			logger.Detach(projectName)
+			return nil
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// reopenRotated opens the file now at logPath when it is no longer the file
+// f being read, i.e. when the log has been rotated.
+func reopenRotated(f *os.File, logPath string) (*os.File, bool) {
+	current, err := os.Stat(logPath)
+	if err != nil {
+		return nil, false
+	}
+	info, err := f.Stat()
+	if err != nil || os.SameFile(current, info) {
+		return nil, false
+	}
+	next, err := os.Open(logPath)
+	if err != nil {
+		return nil, false
+	}
+	return next, true
 }
 
 // seekToLastNLines positions the file so that the last n lines remain
-// to be read. Scans backward from EOF in chunks for memory efficiency.
-func seekToLastNLines(f *os.File, n int) {
+// to be read, and returns how many lines that is (fewer than n when the
+// file is shorter). Scans backward from EOF in chunks for memory efficiency.
+func seekToLastNLines(f *os.File, n int) int {
 	const chunkSize = 8192
 
 	fi, err := f.Stat()
 	if err != nil || fi.Size() == 0 {
-		return
+		return 0
 	}
 
 	size := fi.Size()
@@ -147,7 +179,7 @@ func seekToLastNLines(f *os.File, n int) {
 		nRead, err := f.ReadAt(buf[:readSize], offset)
 		if err != nil && err != io.EOF {
 			_, _ = f.Seek(0, io.SeekStart)
-			return
+			return newlines + 1
 		}
 
 		startIdx := nRead - 1
@@ -161,13 +193,43 @@ func seekToLastNLines(f *os.File, n int) {
 				newlines++
 				if newlines >= n {
 					_, _ = f.Seek(offset+int64(i)+1, io.SeekStart)
-					return
+					return n
 				}
 			}
 		}
 	}
 
 	_, _ = f.Seek(0, io.SeekStart)
+	return newlines + 1
+}
+
+// writeRotatedTail writes up to n lines that precede the current log, taken
+// from its rotated segments (newest first until n lines are collected).
+// Segments last written before since are skipped.
+func writeRotatedTail(w io.Writer, logPath string, n int, since time.Time) {
+	segments := pidfile.LogSegments(logPath)
+	var chunks [][]byte
+	for i := len(segments) - 1; i >= 0 && n > 0; i-- {
+		if segments[i] == logPath {
+			continue
+		}
+		f, err := os.Open(segments[i])
+		if err != nil {
+			continue
+		}
+		if fi, err := f.Stat(); err != nil || fi.ModTime().Before(since) {
+			// Segments only get older from here on.
+			_ = f.Close()
+			break
+		}
+		n -= seekToLastNLines(f, n)
+		data, _ := io.ReadAll(f)
+		_ = f.Close()
+		chunks = append(chunks, data)
+	}
+	for i := len(chunks) - 1; i >= 0; i-- {
+		_, _ = w.Write(chunks[i])
+	}
 }
 
 func init() {
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestSeekToLastNLines(t *testing.T) {
@@ -120,3 +121,34 @@ func TestSeekToLastNLinesLargeFile(t *testing.T) {
 		t.Errorf("got %d lines, want 3", len(lines))
 	}
 }
+
+func TestWriteRotatedTail(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "100.log")
+	files := map[string]string{
+		"100.log.2": "a1\na2\n",
+		"100.log.1": "b1\nb2\n",
+		"100.log":   "c1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{n: 1, expected: "b2\n"},
+		{n: 3, expected: "a2\nb1\nb2\n"},
+		{n: 10, expected: "a1\na2\nb1\nb2\n"},
+	}
+	for _, tt := range tests {
+		var buf strings.Builder
+		writeRotatedTail(&buf, logPath, tt.n, time.Time{})
+		if buf.String() != tt.expected {
+			t.Errorf("writeRotatedTail(%d) = %q, want %q", tt.n, buf.String(), tt.expected)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+	"mdc/internal/runner"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+// procTargetFlags select the processes "proc stop" and "proc restart" act
+// on when they are given more than a single process.
+type procTargetFlags struct {
+	configs  []string
+	projects []string
+	command  string
+	all      bool
+	dead     bool
+}
+
+func (f *procTargetFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(&f.configs, "config", "c", nil, "Select processes of these configs (globs, comma-separated or repeated)")
+	cmd.Flags().StringSliceVarP(&f.projects, "project", "p", nil, "Select processes of these projects (globs, comma-separated or repeated)")
+	cmd.Flags().StringVar(&f.command, "command", "", `Select processes whose command matches a glob (e.g. "npm run *")`)
+	cmd.Flags().BoolVar(&f.dead, "dead", false, "Select processes that are no longer running")
+	cmd.Flags().BoolVar(&f.all, "all", false, "Select all tracked processes")
+}
+
+func (f *procTargetFlags) filter() pidfile.RefFilter {
+	return pidfile.RefFilter{Configs: f.configs, Projects: f.projects, Command: f.command, Dead: f.dead}
+}
+
+// bulk reports whether the command acts on a selection of processes rather
+// than on the single process given as argument.
+func (f *procTargetFlags) bulk(args []string) bool {
+	return len(args) != 1 || f.all || !f.filter().IsEmpty()
+}
+
+// selectProcs returns the processes given as arguments, or all tracked
+// processes when there are none, narrowed down by the filter flags.
+func (f *procTargetFlags) selectProcs(args []string) ([]pidfile.Ref, error) {
+	if f.all && len(args) > 0 {
+		return nil, errors.New("--all cannot be combined with process arguments")
+	}
+	if len(args) == 0 && !f.all && f.filter().IsEmpty() {
+		return nil, errors.New("specify a process, or select processes with --config, --project, --command, --dead or --all")
+	}
+
+	var refs []pidfile.Ref
+	if len(args) == 0 {
+		all, err := pidfile.Refs()
+		if err != nil {
+			return nil, err
+		}
+		refs = all
+	} else {
+		seen := make(map[string]bool)
+		for _, arg := range args {
+			matched, err := pidfile.Match(arg)
+			if err != nil {
+				return nil, err
+			}
+			if len(matched) == 0 {
+				return nil, fmt.Errorf("no tracked process matches %q", arg)
+			}
+			for _, r := range matched {
+				key := fmt.Sprintf("%s/%s/%d", r.Config, r.Project, r.Entry.PID)
+				if !seen[key] {
+					seen[key] = true
+					refs = append(refs, r)
+				}
+			}
+		}
+	}
+	return f.filter().Filter(refs), nil
+}
+
+// lockProcs selects the processes like selectProcs and takes the run locks
+// of their configs. They are selected again once locked, since the command
+// holding a lock may have stopped or restarted them.
+func (f *procTargetFlags) lockProcs(args []string, lock *lockFlags) ([]pidfile.Ref, error) {
+	refs, err := f.selectProcs(args)
+	if err != nil || len(refs) == 0 {
+		return refs, err
+	}
+	configNames := make([]string, 0, len(refs))
+	for _, r := range refs {
+		configNames = append(configNames, r.Config)
+	}
+	lock.lock(configNames...)
+	return f.selectProcs(args)
+}
+
+// Outcomes of a bulk proc operation.
+const (
+	procStopped   = "stopped"
+	procRemoved   = "removed"
+	procStale     = "stale"
+	procRestarted = "restarted"
+	procFailed    = "failed"
+)
+
+type procResult struct {
+	Ref    pidfile.Ref
+	Result string
+	NewPID int
+	Err    error
+}
+
+// stopProcs stops the processes concurrently. Processes that were no longer
+// running, or whose PID now belongs to another process, only have their
+// entries removed.
+func stopProcs(refs []pidfile.Ref) []procResult {
+	return runProcs(refs, false, func(r pidfile.Ref) procResult {
+		result := procStopped
+		switch r.Entry.Status() {
+		case pidfile.StatusDead:
+			result = procRemoved
+		case pidfile.StatusStale:
+			result = procStale
+		}
+		runner.StopProcess(r.Config, r.Project, r.Entry)
+		return procResult{Ref: r, Result: result}
+	})
+}
+
+// restartProcs restarts the processes, different projects concurrently.
+func restartProcs(refs []pidfile.Ref) []procResult {
+	return runProcs(refs, true, func(r pidfile.Ref) procResult {
+		next, err := runner.RestartProcess(r.Config, r.Project, r.Entry)
+		if err != nil {
+			return procResult{Ref: r, Result: procFailed, Err: err}
+		}
+		return procResult{Ref: r, Result: procRestarted, NewPID: next.PID}
+	})
+}
+
+// runProcs runs fn for every process concurrently and returns the results
+// in the order of refs. With perProject, the processes of a project are
+// handled one after another, since spawning a project's background commands
+// shares its pending log file.
+func runProcs(refs []pidfile.Ref, perProject bool, fn func(pidfile.Ref) procResult) []procResult {
+	var groups [][]int
+	index := make(map[string]int)
+	for i, r := range refs {
+		key := fmt.Sprint(i)
+		if perProject {
+			key = r.Config + "/" + r.Project
+		}
+		g, ok := index[key]
+		if !ok {
+			g = len(groups)
+			index[key] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	results := make([]procResult, len(refs))
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		go func(group []int) {
+			defer wg.Done()
+			for _, i := range group {
+				results[i] = fn(refs[i])
+			}
+		}(group)
+	}
+	wg.Wait()
+	return results
+}
+
+// reportProcResults prints a summary of a bulk operation and exits with an
+// error when any process failed.
+func reportProcResults(action string, results []procResult) {
+	failed := false
+	for _, r := range results {
+		failed = failed || r.Result == procFailed
+	}
+
+	if jsonOutput() {
+		summary := make([]logger.ProjectResult, 0, len(results))
+		for _, r := range results {
+			summary = append(summary, procSummaryResult(r))
+		}
+		logger.Summary(action, summary)
+	} else {
+		printProcResults(results)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func procSummaryResult(r procResult) logger.ProjectResult {
+	res := logger.ProjectResult{Name: r.Ref.String(), State: logger.StateCompleted, Detail: r.Result}
+	switch {
+	case r.Err != nil:
+		res.State = logger.StateFailed
+		res.Detail = r.Err.Error()
+	case r.NewPID != 0:
+		res.Detail = fmt.Sprintf("%s as PID %d", r.Result, r.NewPID)
+	}
+	return res
+}
+
+func printProcResults(results []procResult) {
+	if len(results) == 0 {
+		fmt.Println("No matching processes.")
+		return
+	}
+
+	fmt.Println()
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"CONFIG", "PROJECT", "PID", "COMMAND", "RESULT"})
+	for _, r := range results {
+		command := text.Colors{text.FgCyan}.Sprint(r.Ref.Entry.Command)
+		t.AppendRow(table.Row{r.Ref.Config, r.Ref.Project, r.Ref.Entry.PID, command, describeProcResult(r)})
+	}
+	t.Render()
+}
+
+func describeProcResult(r procResult) string {
+	switch r.Result {
+	case procFailed:
+		return text.Colors{text.FgRed}.Sprintf("%s: %v", r.Result, r.Err)
+	case procRestarted:
+		return text.Colors{text.FgGreen}.Sprintf("%s (PID %d)", r.Result, r.NewPID)
+	case procRemoved, procStale:
+		return text.Colors{text.FgYellow}.Sprint(r.Result)
+	default:
+		return text.Colors{text.FgGreen}.Sprint(r.Result)
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"mdc/internal/pidfile"
+)
+
+func TestStopProcsResults(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process identity is not recorded on Windows")
+	}
+	old := pidfile.BaseDir
+	pidfile.BaseDir = t.TempDir()
+	defer func() { pidfile.BaseDir = old }()
+
+	start := func() int {
+		cmd := exec.Command("sleep", "30")
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+		return cmd.Process.Pid
+	}
+	running := start()
+	reused := start()
+
+	// The PID of a stale entry was reused by a process started later.
+	stale := pidfile.Entry{PID: reused, Command: "npm run dev", Identity: pidfile.Identify(reused)}
+	stale.PGID++
+	stale.StartTime++
+	entries := []pidfile.Entry{
+		{PID: running, Command: "sleep 30", Identity: pidfile.Identify(running)},
+		stale,
+		{PID: 4242, Command: "go run .", Exit: &pidfile.ExitStatus{Code: 1}},
+	}
+	if err := pidfile.Save("shop", "web", entries); err != nil {
+		t.Fatal(err)
+	}
+
+	var refs []pidfile.Ref
+	for _, e := range entries {
+		refs = append(refs, pidfile.Ref{Config: "shop", Project: "web", Entry: e})
+	}
+	results := stopProcs(refs)
+	for i, want := range []string{procStopped, procStale, procRemoved} {
+		if results[i].Result != want {
+			t.Errorf("result of %q = %q, want %q", entries[i].Command, results[i].Result, want)
+		}
+	}
+	if !pidfile.IsRunning(reused) {
+		t.Error("the process reusing a stale PID must not be signalled")
+	}
+	if left, _ := pidfile.Load("shop", "web"); len(left) != 0 {
+		t.Errorf("entries left after stopping = %+v, want none", left)
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"mdc/internal/pidfile"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyProjects []string
+	historySince    string
+)
+
+var procHistoryCmd = &cobra.Command{
+	Use:   "history [config-name]",
+	Short: "Show when background processes were started, stopped, restarted and exited",
+	Long: `Show the lifecycle events of background processes, oldest first: started,
+stopped, restarted and exited (with the exit code or signal), along with the mdc
+command that caused them (e.g. "up", "down", "proc restart" or "supervise").
+Exits are recorded when mdc notices them, with the time they happened.
+
+The history is kept in ~/.config/mdc/history/<config>.jsonl and, unlike PID
+files and proc logs, survives "mdc down". When config name is omitted, the
+history of all configs is shown.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		since, err := parseSince(historySince, time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		var configNames []string
+		if len(args) == 1 {
+			configNames = args
+		} else if configNames, err = pidfile.HistoryConfigNames(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		events := []procHistoryJSON{}
+		for _, configName := range configNames {
+			history, err := pidfile.LoadHistory(configName)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			for _, ev := range filterHistory(history, historyProjects, since) {
+				events = append(events, procHistoryJSON{Config: configName, HistoryEvent: ev})
+			}
+		}
+		sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+
+		if jsonOutput() {
+			printJSON(events)
+			return
+		}
+		printProcHistory(events)
+	},
+}
+
+type procHistoryJSON struct {
+	Config string `json:"config"`
+	pidfile.HistoryEvent
+}
+
+// filterHistory keeps the events of the given projects (all when empty)
+// that happened at or after since.
+func filterHistory(events []pidfile.HistoryEvent, projects []string, since time.Time) []pidfile.HistoryEvent {
+	wanted := make(map[string]bool)
+	for _, p := range projects {
+		wanted[p] = true
+	}
+	var result []pidfile.HistoryEvent
+	for _, ev := range events {
+		if len(wanted) > 0 && !wanted[ev.Project] {
+			continue
+		}
+		if ev.Time.Before(since) {
+			continue
+		}
+		result = append(result, ev)
+	}
+	return result
+}
+
+func printProcHistory(events []procHistoryJSON) {
+	if len(events) == 0 {
+		fmt.Println("No process history found.")
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"TIME", "CONFIG", "PROJECT", "EVENT", "PID", "COMMAND", "BY"})
+	for _, ev := range events {
+		command := text.Colors{text.FgCyan}.Sprint(ev.Command)
+		t.AppendRow(table.Row{ev.Time.Local().Format(timeLayout), ev.Config, ev.Project, describeHistoryEvent(ev.HistoryEvent), ev.PID, command, ev.By})
+	}
+	t.Render()
+}
+
+// describeHistoryEvent renders an event with its details, e.g.
+// "exited: Exited (code 1)" or "restarted (was PID 123)".
+func describeHistoryEvent(ev pidfile.HistoryEvent) string {
+	switch ev.Action {
+	case pidfile.HistoryStarted:
+		return text.Colors{text.FgGreen}.Sprint(ev.Action)
+	case pidfile.HistoryRestarted:
+		return text.Colors{text.FgYellow}.Sprintf("%s (was PID %d)", ev.Action, ev.PrevPID)
+	case pidfile.HistoryExited:
+		if ev.Exit == nil {
+			return text.Colors{text.FgRed}.Sprint(ev.Action)
+		}
+		if !ev.Exit.Crashed() {
+			return fmt.Sprintf("%s: %s", ev.Action, ev.Exit)
+		}
+		return text.Colors{text.FgRed}.Sprintf("%s: %s", ev.Action, ev.Exit)
+	default:
+		return string(ev.Action)
+	}
+}
+
+func init() {
+	procHistoryCmd.Flags().StringSliceVarP(&historyProjects, "project", "p", nil, "Only show events of these projects (comma-separated or repeated)")
+	procHistoryCmd.Flags().StringVar(&historySince, "since", "", "Only show events since a duration ago (e.g. 24h) or an RFC 3339 timestamp")
+	procCmd.AddCommand(procHistoryCmd)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"mdc/internal/pidfile"
+)
+
+func TestFilterHistory(t *testing.T) {
+	now := time.Now()
+	events := []pidfile.HistoryEvent{
+		{Time: now.Add(-2 * time.Hour), Project: "web", PID: 1},
+		{Time: now.Add(-time.Minute), Project: "web", PID: 2},
+		{Time: now.Add(-time.Minute), Project: "api", PID: 3},
+	}
+
+	tests := []struct {
+		name     string
+		projects []string
+		since    time.Time
+		want     []int
+	}{
+		{"all", nil, time.Time{}, []int{1, 2, 3}},
+		{"project", []string{"web"}, time.Time{}, []int{1, 2}},
+		{"since", nil, now.Add(-time.Hour), []int{2, 3}},
+		{"project and since", []string{"api"}, now.Add(-time.Hour), []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterHistory(events, tt.projects, tt.since)
+			if len(got) != len(tt.want) {
+				t.Fatalf("filterHistory() returned %d events, want %d", len(got), len(tt.want))
+			}
+			for i, ev := range got {
+				if ev.PID != tt.want[i] {
+					t.Errorf("event %d has PID %d, want %d", i, ev.PID, tt.want[i])
+				}
+			}
+		})
+	}
+}
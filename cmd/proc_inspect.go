@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"mdc/internal/pidfile"
+	"mdc/internal/runner"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+// timeLayout is how mdc shows points in time, in local time.
+const timeLayout = "2006-01-02 15:04:05"
+
+var procInspectLines int
+
+var procInspectCmd = &cobra.Command{
+	Use:   "inspect <process>",
+	Short: "Show details of a background process, including how it ended",
+	Long: `Show everything mdc knows about a tracked background process: its command,
+directory, when it started, how long it ran, how it is stopped and where its log
+is. For a process that is no longer running, the exit code or the signal that
+killed it and the time it ended are shown, followed by the last lines of its log.
+
+` + procRefUsage,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProcRefs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ref := resolveProcArg(args[0])
+		info := inspectProcess(ref.Config, ref.Project, ref.Entry, time.Now())
+		if jsonOutput() {
+			printJSON(info)
+			return
+		}
+		printProcInspect(info)
+	},
+}
+
+type procInspectJSON struct {
+	procEntryJSON
+	StopSignal         string   `json:"stop_signal"`
+	StopTimeoutSeconds float64  `json:"stop_timeout_seconds"`
+	LogPath            string   `json:"log_path,omitempty"`
+	LogTail            []string `json:"log_tail"`
+}
+
+func inspectProcess(configName, projectName string, e pidfile.Entry, now time.Time) procInspectJSON {
+	info := procInspectJSON{
+		procEntryJSON:      procEntryToJSON(configName, projectName, e, now),
+		StopSignal:         e.StopSignalName(),
+		StopTimeoutSeconds: e.StopGracePeriod().Seconds(),
+		LogTail:            []string{},
+	}
+	if logPath, err := pidfile.ProcLogFilePath(configName, projectName, e.PID); err == nil {
+		if _, err := os.Stat(logPath); err == nil {
+			info.LogPath = logPath
+			if tail := runner.TailFile(logPath, procInspectLines); tail != "" {
+				info.LogTail = strings.Split(tail, "\n")
+			}
+		}
+	}
+	return info
+}
+
+func printProcInspect(info procInspectJSON) {
+	label := text.Colors{text.Bold}
+	field := func(name, value string) {
+		fmt.Printf("%s %s\n", label.Sprintf("%-10s", name+":"), value)
+	}
+
+	field("Config", info.Config)
+	field("Project", info.Project)
+	if info.Name != "" {
+		field("Name", info.Name)
+	}
+	field("Command", text.Colors{text.FgCyan}.Sprint(info.Command))
+	field("Dir", shortenHome(info.Dir))
+	field("PID", strconv.Itoa(info.PID))
+	field("Status", inspectStatus(info))
+	if !info.StartedAt.IsZero() {
+		field("Started", info.StartedAt.Local().Format(timeLayout))
+	}
+	if !info.ExitedAt.IsZero() {
+		field("Ended", info.ExitedAt.Local().Format(timeLayout))
+	}
+	field("Runtime", formatDuration(time.Duration(info.RuntimeSeconds)*time.Second))
+	field("Restarts", strconv.Itoa(info.Restarts))
+	field("Stop", fmt.Sprintf("%s, then SIGKILL after %s", info.StopSignal, time.Duration(info.StopTimeoutSeconds*float64(time.Second))))
+	if info.LogPath == "" {
+		field("Log", "-")
+		return
+	}
+	field("Log", shortenHome(info.LogPath))
+
+	if len(info.LogTail) > 0 {
+		fmt.Println()
+		fmt.Println(label.Sprint("Last log lines:"))
+		for _, line := range info.LogTail {
+			fmt.Printf("  %s %s\n", text.Colors{text.Faint}.Sprint("│"), line)
+		}
+	}
+}
+
+// inspectStatus colors the status and explains what an exit means.
+func inspectStatus(info procInspectJSON) string {
+	switch {
+	case info.Running:
+		return text.Colors{text.FgGreen}.Sprint(info.Status)
+	case info.Signal == "SIGKILL":
+		return text.Colors{text.FgRed}.Sprintf("Killed (SIGKILL)") + ` — e.g. by the OOM killer or "kill -9"`
+	case info.Signal != "":
+		return text.Colors{text.FgRed}.Sprintf("Killed (%s)", info.Signal)
+	case info.ExitCode != nil && *info.ExitCode == 0:
+		return text.Colors{text.FgYellow}.Sprint("Exited (code 0)")
+	case info.ExitCode != nil:
+		return text.Colors{text.FgRed}.Sprintf("Exited (code %d)", *info.ExitCode)
+	case info.Status == string(pidfile.StatusStale):
+		return text.Colors{text.FgYellow}.Sprint(info.Status) + " — the PID now belongs to another process"
+	default:
+		return text.Colors{text.FgRed}.Sprint(info.Status) + " — the exit status was not recorded"
+	}
+}
+
+func init() {
+	procInspectCmd.Flags().IntVarP(&procInspectLines, "lines", "n", 10, "Number of log lines to show")
+	procCmd.AddCommand(procInspectCmd)
+}
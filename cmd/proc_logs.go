@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/pidfile"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneOlderThan time.Duration
+	pruneDryRun    bool
+)
+
+var procLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Manage background process logs",
+}
+
+var procLogsPruneCmd = &cobra.Command{
+	Use:   "prune [config-name]",
+	Short: "Remove old logs",
+	Long: `Apply the log policy of each config (the "logs" section, or the defaults):
+rotated segments beyond max_files are removed, and rotated segments and logs of
+processes that are no longer tracked (e.g. stopped with "mdc proc stop") are
+removed once they are older than max_age. Logs are rotated at max_size while
+they are written, not by this command. When config name is omitted, all
+configs are pruned.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var configNames []string
+		if len(args) == 1 {
+			configNames = args
+		} else {
+			names, err := pidfile.LogConfigNames()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			configNames = names
+		}
+
+		var total pidfile.PruneResult
+		for _, configName := range configNames {
+			opts := pruneOptions(configName)
+			if cmd.Flags().Changed("older-than") {
+				opts.MaxAge = pruneOlderThan
+			}
+			opts.DryRun = pruneDryRun
+			result, err := pidfile.PruneLogs(configName, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to prune logs of %q: %v\n", configName, err)
+			}
+			total.Removed = append(total.Removed, result.Removed...)
+			total.Freed += result.Freed
+		}
+
+		if jsonOutput() {
+			printJSON(pruneJSON{
+				Removed:    nonNil(total.Removed),
+				FreedBytes: total.Freed,
+				DryRun:     pruneDryRun,
+			})
+			return
+		}
+		printPruneResult(total)
+	},
+}
+
+type pruneJSON struct {
+	Removed    []string `json:"removed"`
+	FreedBytes int64    `json:"freed_bytes"`
+	DryRun     bool     `json:"dry_run"`
+}
+
+// pruneOptions returns the log policy of a config. Configs that cannot be
+// loaded (e.g. removed files) use the defaults.
+func pruneOptions(configName string) pidfile.PruneOptions {
+	var logs config.LogConfig
+	if cfg, err := config.Load(configName); err == nil {
+		logs = cfg.Logs
+	}
+	return pidfile.PruneOptions{Logs: logs, MaxAge: logs.MaxAgeDuration()}
+}
+
+// keepStoppedLogs reports whether the config keeps the logs of processes
+// stopped by "down" (logs.keep_stopped).
+func keepStoppedLogs(configName string) bool {
+	return pruneOptions(configName).Logs.KeepStopped
+}
+
+// autoPruneLogs applies the log policy after "mdc up". Failures only warn.
+func autoPruneLogs(configName string) {
+	if _, err := pidfile.PruneLogs(configName, pruneOptions(configName)); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to prune logs: %v\n", err)
+	}
+}
+
+func printPruneResult(result pidfile.PruneResult) {
+	if len(result.Removed) == 0 {
+		fmt.Println("Nothing to prune.")
+		return
+	}
+	removed := "Removed"
+	if pruneDryRun {
+		removed = "Would remove"
+	}
+	for _, path := range result.Removed {
+		fmt.Printf("🧹 %s: %s\n", removed, shortenHome(path))
+	}
+	fmt.Printf("%s %d file(s) (%s)\n", removed, len(result.Removed), formatSize(result.Freed))
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func init() {
+	procLogsPruneCmd.Flags().DurationVar(&pruneOlderThan, "older-than", 0, "Retention age overriding the config's max_age (0 removes all logs of untracked processes)")
+	procLogsPruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be removed without changing anything")
+	procLogsCmd.AddCommand(procLogsPruneCmd)
+	procCmd.AddCommand(procLogsCmd)
+}
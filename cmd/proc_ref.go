@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"mdc/internal/pidfile"
+
+	"github.com/spf13/cobra"
+)
+
+// procRefUsage describes the process argument of proc subcommands.
+const procRefUsage = `The process is given by its PID, as <config>/<project>/<name> for commands
+with a "name" in the config (which, unlike the PID, stays the same across
+restarts), or as <config>/<project> or <project> when that project has a single
+background process.`
+
+// resolveProcArg finds the tracked process a proc subcommand argument refers
+// to, exiting when there is none or it is ambiguous.
+func resolveProcArg(arg string) pidfile.Ref {
+	ref, err := pidfile.Resolve(arg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return ref
+}
+
+// lockProcArg is resolveProcArg for subcommands that change the process: it
+// takes the run lock of its config, then resolves the argument again, since
+// the command holding the lock may have restarted the process.
+func lockProcArg(arg string, lock *lockFlags) pidfile.Ref {
+	lock.lock(resolveProcArg(arg).Config)
+	return resolveProcArg(arg)
+}
+
+// completeProcRefs completes the process argument of proc subcommands with
+// the identifiers of tracked processes: named commands, projects and PIDs.
+func completeProcRefs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return procRefCandidates(args, toComplete)
+}
+
+// completeProcRefList is completeProcRefs for subcommands that take several
+// processes.
+func completeProcRefList(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return procRefCandidates(args, toComplete)
+}
+
+// procRefCandidates returns the identifiers starting with toComplete, except
+// those already given.
+func procRefCandidates(given []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	refs, err := pidfile.Refs()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var candidates []string
+	seen := make(map[string]bool)
+	for _, id := range given {
+		seen[id] = true
+	}
+	add := func(id, description string) {
+		if seen[id] || !strings.HasPrefix(id, toComplete) {
+			return
+		}
+		seen[id] = true
+		candidates = append(candidates, id+"\t"+description)
+	}
+	for _, r := range refs {
+		if r.Entry.Name != "" {
+			add(r.String(), r.Entry.Command)
+		}
+	}
+	for _, r := range refs {
+		add(r.Config+"/"+r.Project, "project "+r.Project+" of "+r.Config)
+		add(r.Project, "project of "+r.Config)
+	}
+	for _, r := range refs {
+		add(strconv.Itoa(r.Entry.PID), r.Config+"/"+r.Project+": "+r.Entry.Command)
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
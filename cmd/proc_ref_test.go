@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"mdc/internal/pidfile"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteProcRefs(t *testing.T) {
+	old := pidfile.BaseDir
+	pidfile.BaseDir = t.TempDir()
+	defer func() { pidfile.BaseDir = old }()
+
+	if err := pidfile.Save("shop", "web", []pidfile.Entry{{PID: 101, Name: "dev", Command: "npm run dev"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pidfile.Save("shop", "api", []pidfile.Entry{{PID: 201, Command: "go run ."}}); err != nil {
+		t.Fatal(err)
+	}
+
+	complete := func(toComplete string) []string {
+		candidates, directive := completeProcRefs(procStopCmd, nil, toComplete)
+		if directive != cobra.ShellCompDirectiveNoFileComp {
+			t.Errorf("directive = %v, want NoFileComp", directive)
+		}
+		ids := make([]string, len(candidates))
+		for i, c := range candidates {
+			ids[i], _, _ = strings.Cut(c, "\t")
+		}
+		return ids
+	}
+
+	if got, want := complete(""), []string{"shop/web/dev", "shop/api", "api", "shop/web", "web", "201", "101"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completions = %v, want %v", got, want)
+	}
+	if got, want := complete("shop/w"), []string{"shop/web/dev", "shop/web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completions of shop/w = %v, want %v", got, want)
+	}
+	if got, _ := completeProcRefs(procStopCmd, []string{"101"}, ""); len(got) != 0 {
+		t.Errorf("completions after the argument = %v, want none", got)
+	}
+}
+
+func TestSelectProcs(t *testing.T) {
+	old := pidfile.BaseDir
+	pidfile.BaseDir = t.TempDir()
+	defer func() { pidfile.BaseDir = old }()
+
+	if err := pidfile.Save("dev", "Frontend", []pidfile.Entry{{PID: 101, Name: "web", Command: "npm run dev"}, {PID: 102, Command: "npm run storybook"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pidfile.Save("dev", "Backend", []pidfile.Entry{{PID: 201, Command: "go run ."}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		flags   procTargetFlags
+		args    []string
+		want    []int
+		wantErr string
+	}{
+		{name: "all", flags: procTargetFlags{all: true}, want: []int{201, 101, 102}},
+		{name: "project", flags: procTargetFlags{configs: []string{"dev"}, projects: []string{"Frontend"}}, want: []int{101, 102}},
+		{name: "command", flags: procTargetFlags{command: "npm run s*"}, want: []int{102}},
+		{name: "args", args: []string{"dev/Frontend/web", "201", "101"}, want: []int{101, 201}},
+		{name: "args narrowed", flags: procTargetFlags{command: "go *"}, args: []string{"dev/Frontend/web", "201"}, want: []int{201}},
+		{name: "nothing selected", wantErr: "specify a process"},
+		{name: "all with args", flags: procTargetFlags{all: true}, args: []string{"101"}, wantErr: "--all cannot be combined"},
+		{name: "unknown arg", args: []string{"101", "999"}, wantErr: "no tracked process matches"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, err := tt.flags.selectProcs(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("selectProcs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, r := range refs {
+				got = append(got, r.Entry.PID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectProcs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,44 +3,49 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strconv"
-	"time"
 
-	"mdc/internal/logger"
-	"mdc/internal/pidfile"
+	"mdc/internal/runner"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	procStopTargets procTargetFlags
+	procStopLock    lockFlags
+)
+
 var procStopCmd = &cobra.Command{
-	Use:   "stop <PID>",
-	Short: "Stop a background process by PID",
-	Args:  cobra.ExactArgs(1),
+	Use:   "stop [process...]",
+	Short: "Stop background processes",
+	Long: `Stop a background process and all of its descendants.
+
+` + procRefUsage + `
+
+Several processes can be stopped at once by giving more than one, or by
+selecting them with --config, --project, --command, --dead or --all. They are
+stopped concurrently and a summary is printed at the end. "--dead" removes the
+entries of processes that are no longer running.
+
+` + runLockUsage,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeProcRefList,
 	Run: func(cmd *cobra.Command, args []string) {
-		pid, err := strconv.Atoi(args[0])
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "invalid PID: %s\n", args[0])
-			os.Exit(1)
+		if !procStopTargets.bulk(args) {
+			ref := lockProcArg(args[0], &procStopLock)
+			runner.StopProcess(ref.Config, ref.Project, ref.Entry)
+			return
 		}
-
-		configName, projectName, entry, err := pidfile.FindByPID(pid)
+		refs, err := procStopTargets.lockProcs(args, &procStopLock)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
-
-		logger.Stop(projectName, entry.Command, pid)
-
-		_ = pidfile.GracefulKill(pid, 10*time.Second)
-
-		if err := pidfile.RemoveEntry(configName, projectName, pid); err != nil {
-			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to remove PID entry: %v\n", err)
-		}
-
-		logger.Stopped(projectName)
+		reportProcResults("proc stop", stopProcs(refs))
 	},
 }
 
 func init() {
+	procStopTargets.register(procStopCmd)
+	procStopLock.register(procStopCmd)
 	procCmd.AddCommand(procStopCmd)
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"mdc/internal/config"
 	"mdc/internal/runner"
 
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -12,6 +11,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var psSelect selectionFlags
+
 var psCmd = &cobra.Command{
 	Use:   "ps [config-name]",
 	Short: "Show container status for all projects",
@@ -26,17 +27,25 @@ Without arguments, runs "docker ps" to show all containers on the host.`,
 				fmt.Fprintln(os.Stderr, err)
 				os.Exit(1)
 			}
+			if jsonOutput() {
+				printJSON(dockerPSJSON(containers))
+				return
+			}
 			printDockerPSTable(containers)
 			return
 		}
 
-		cfg, err := config.Load(args[0])
+		cfg, err := loadSelected(args[0], psSelect.selector())
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
 
 		results := runner.CollectPS(cfg)
+		if jsonOutput() {
+			printJSON(psJSON(results))
+			return
+		}
 		printPSTable(results)
 	},
 }
@@ -89,6 +98,39 @@ func printDockerPSTable(containers []runner.ContainerInfo) {
 	t.Render()
 }
 
+type containerJSON struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Ports  string `json:"ports"`
+	State  string `json:"state"`
+	Status string `json:"status"`
+}
+
+type projectContainersJSON struct {
+	Project    string          `json:"project"`
+	Containers []containerJSON `json:"containers"`
+	Error      string          `json:"error,omitempty"`
+}
+
+func dockerPSJSON(containers []runner.ContainerInfo) []containerJSON {
+	result := make([]containerJSON, len(containers))
+	for i, c := range containers {
+		result[i] = containerJSON{ID: c.ID, Name: c.Name, Ports: c.Ports, State: c.State, Status: c.Status}
+	}
+	return result
+}
+
+func psJSON(results []runner.ProjectContainers) []projectContainersJSON {
+	out := make([]projectContainersJSON, len(results))
+	for i, r := range results {
+		out[i] = projectContainersJSON{Project: r.ProjectName, Containers: dockerPSJSON(r.Containers)}
+		if r.Err != nil {
+			out[i].Error = r.Err.Error()
+		}
+	}
+	return out
+}
+
 func colorizeState(state, status string) string {
 	switch state {
 	case "running":
@@ -99,5 +141,6 @@ func colorizeState(state, status string) string {
 }
 
 func init() {
+	psSelect.register(psCmd)
 	rootCmd.AddCommand(psCmd)
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"mdc/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	restartKeepGoing bool
+	restartSelect    selectionFlags
+	restartLock      lockFlags
+)
+
+var restartCmd = &cobra.Command{
+	Use:   "restart [config-name]",
+	Short: "Restart the projects defined in a config, one at a time",
+	Long: `Restart the projects of a config one at a time, in dependency order: a
+project is back up before the projects that depend on it are restarted.
+
+A project with "restart" commands runs them instead of "down" and "up", and
+its background processes are then restarted like with "mdc proc restart".
+Any other project runs its down commands, has its background processes
+stopped, and runs its up commands.
+
+The restart stops at the first project that fails. With --keep-going, the
+remaining projects are restarted anyway, except those that depend on a failed
+one.
+
+` + runLockUsage,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configName := args[0]
+		cfg, err := loadSelected(configName, restartSelect.selector())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		restartLock.lock(configName)
+		err = runner.Restart(cfg, configName, restartKeepGoing)
+		autoPruneLogs(configName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	restartCmd.Flags().BoolVar(&restartKeepGoing, "keep-going", false, "Restart the remaining projects when one fails")
+	restartSelect.register(restartCmd)
+	restartLock.register(restartCmd)
+	rootCmd.AddCommand(restartCmd)
+}
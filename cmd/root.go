@@ -1,14 +1,19 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"mdc/internal/config"
 	"mdc/internal/runner"
 	"mdc/internal/version"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var rootCmd = &cobra.Command{
@@ -26,18 +31,25 @@ func init() {
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	releaseRunLocks()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
-func loadAndRun(configName, action string, dryRun bool) {
-	cfg, err := config.Load(configName)
+func loadAndRun(configName, action string, dryRun bool, sel config.Selector, opts runner.Options) {
+	cfg, err := loadSelected(configName, sel)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	runLoaded(cfg, configName, action, dryRun, opts)
+}
+
+// runLoaded runs an action, or prints its plan, for an already loaded config.
+func runLoaded(cfg *config.Config, configName, action string, dryRun bool, opts runner.Options) {
 	if dryRun {
 		if err := runner.DryRun(cfg, action); err != nil {
 			fmt.Fprintln(os.Stderr, err)
@@ -45,8 +57,26 @@ func loadAndRun(configName, action string, dryRun bool) {
 		}
 		return
 	}
-	if err := runner.Run(cfg, action, configName); err != nil {
+	ctx, stop := runner.WithInterrupts(context.Background())
+	defer stop()
+	if opts.ConfirmRollback == nil && !jsonOutput() && term.IsTerminal(int(os.Stdin.Fd())) {
+		opts.ConfirmRollback = confirmRollback
+	}
+	err := runner.RunContext(ctx, cfg, action, configName, opts)
+	if errors.Is(err, runner.ErrInterrupted) {
+		// Like a shell reports a command killed by SIGINT.
+		os.Exit(130)
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// confirmRollback asks whether to run "down" for the projects that were
+// started before "up" was interrupted.
+func confirmRollback(projects []string) bool {
+	fmt.Printf("\nRun down for the projects that were started (%s)? [y/n]: ", strings.Join(projects, ", "))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer) == "y"
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"strings"
+
+	"mdc/internal/config"
+	"mdc/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	runDryRun   bool
+	runFailFast bool
+	runSelect   selectionFlags
+	runLock     lockFlags
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [config-name] [action]",
+	Short: "Run a named action, such as migrate or seed, defined in a config",
+	Long: `Run a command list defined under "commands.actions" in the config, such
+as "migrate", "seed" or "build", the same way "mdc up" runs the up commands:
+according to the execution mode and in dependency order. Projects that do not
+define the action are skipped.
+
+Run "mdc actions <config-name>" to list the available actions.
+
+` + runLockUsage,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeActions,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !runDryRun {
+			runLock.lock(args[0])
+		}
+		loadAndRun(args[0], args[1], runDryRun, runSelect.selector(), runner.Options{FailFast: runFailFast})
+	},
+}
+
+// completeActions completes the action argument with the actions defined in
+// the config given as first argument.
+func completeActions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.Load(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var candidates []string
+	for _, action := range cfg.ActionNames() {
+		if strings.HasPrefix(action, toComplete) {
+			candidates = append(candidates, action)
+		}
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	runCmd.Flags().BoolVar(&runDryRun, "dry-run", false, "Print execution plan without running commands")
+	runCmd.Flags().BoolVar(&runFailFast, "fail-fast", false, "In parallel mode, cancel the other projects as soon as one fails")
+	runSelect.register(runCmd)
+	runLock.register(runCmd)
+	rootCmd.AddCommand(runCmd)
+}
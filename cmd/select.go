@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"mdc/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// selectionFlags holds the --only, --except and --tag flags shared by
+// commands that operate on a subset of a config's projects.
+type selectionFlags struct {
+	only   []string
+	except []string
+	tags   []string
+}
+
+func (f *selectionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&f.only, "only", nil, "Only include these projects (comma-separated)")
+	cmd.Flags().StringSliceVar(&f.except, "except", nil, "Exclude these projects (comma-separated)")
+	cmd.Flags().StringSliceVar(&f.tags, "tag", nil, "Only include projects with one of these tags (comma-separated)")
+}
+
+func (f *selectionFlags) selector() config.Selector {
+	return config.Selector{Only: f.only, Except: f.except, Tags: f.tags}
+}
+
+// loadSelected loads a config and narrows it down to the selected projects.
+func loadSelected(configName string, sel config.Selector) (*config.Config, error) {
+	cfg, err := config.Load(configName)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Select(sel)
+}
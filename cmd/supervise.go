@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+	"mdc/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	superviseInterval time.Duration
+	superviseDetach   bool
+)
+
+var superviseCmd = &cobra.Command{
+	Use:     "supervise <config-name>",
+	Aliases: []string{"daemon"},
+	Short:   "Restart crashed background processes according to their restart policy",
+	Long: `Watch the background processes started by "mdc up" and restart them when
+they exit, according to each command's "restart" policy (no, on-failure,
+always) with exponential backoff and a "max_restarts" limit.
+
+Each restart takes the run lock of the config, so a process stopped by
+"mdc down" or "mdc proc stop" is never brought back; while another mdc
+command holds the lock, restarts wait for the next check.
+
+The supervisor exits when interrupted or when no process is left to
+supervise: none is tracked any more (for example after "mdc down"), or every
+process has exited for good or reached its max_restarts. Use --detach to run
+it in the background.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configName := args[0]
+		cfg, err := config.Load(configName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if superviseDetach {
+			detachSupervisor(configName, cfg.Logs)
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		logger.SupervisorStart(configName)
+		if err := runner.Supervise(ctx, cfg, configName, superviseInterval); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// detachSupervisor re-executes "mdc supervise" as a detached background
+// process whose output is written to the config's proc log directory and
+// rotated according to the config's log settings.
+func detachSupervisor(configName string, logs config.LogConfig) {
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve executable: %v\n", err)
+		os.Exit(1)
+	}
+	logDir, err := pidfile.ProcLogDir(configName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logPath := filepath.Join(logDir, pidfile.SupervisorLogName)
+
+	command := fmt.Sprintf("%s supervise %s --interval %s", shellQuote(exe), shellQuote(configName), superviseInterval)
+	pid, err := runner.StartBackgroundProcess(command, ".", logPath, pidfile.RotationOf(logs))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start supervisor: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Supervisor started (PID: %d, log: %s)\n", pid, config.ContractHome(logPath))
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	superviseCmd.Flags().DurationVar(&superviseInterval, "interval", time.Second, "How often to check process status")
+	superviseCmd.Flags().BoolVarP(&superviseDetach, "detach", "d", false, "Run the supervisor in the background")
+	rootCmd.AddCommand(superviseCmd)
+}
@@ -1,21 +1,92 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"mdc/internal/config"
+	"mdc/internal/runner"
+
 	"github.com/spf13/cobra"
 )
 
-var upDryRun bool
+var (
+	upDryRun    bool
+	upSelect    selectionFlags
+	upIfRunning string
+	upRecreate  bool
+	upFailFast  bool
+	upRollback  bool
+	upLock      lockFlags
+)
 
 var upCmd = &cobra.Command{
 	Use:   "up [config-name]",
 	Short: "Start all projects defined in a config",
-	Args:  cobra.ExactArgs(1),
+	Long: `Start all projects defined in a config.
+
+A background command that is already running in the same project, with the
+same command and directory, is not started twice. By default it is skipped and
+reported in the summary; its "if_running" setting in the config, or
+--if-running, can restart it ("recreate") or make "up" fail instead.
+--recreate is short for --if-running=recreate.
+
+In parallel mode, a failing project does not stop the others. With
+--fail-fast, the commands that are running are canceled (their process groups
+receive SIGTERM) and the projects that have not started are not started. With
+--rollback-on-failure, a failed "up" runs the down commands and stops the
+background processes of the projects that were started.
+
+` + runLockUsage,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		loadAndRun(args[0], "up", upDryRun)
+		policy, err := ifRunningFlag()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		cfg, err := loadSelected(args[0], upSelect.selector())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if policy != "" {
+			cfg.SetIfRunning(policy)
+		}
+		if !upDryRun {
+			upLock.lock(args[0])
+		}
+		runLoaded(cfg, args[0], "up", upDryRun, runner.Options{FailFast: upFailFast, RollbackOnFailure: upRollback})
+		if !upDryRun {
+			autoPruneLogs(args[0])
+		}
 	},
 }
 
+// ifRunningFlag returns the if_running policy given on the command line, or
+// "" to use the policies of the config.
+func ifRunningFlag() (string, error) {
+	if upRecreate {
+		if upIfRunning != "" && upIfRunning != config.IfRunningRecreate {
+			return "", fmt.Errorf("--recreate cannot be combined with --if-running=%s", upIfRunning)
+		}
+		return config.IfRunningRecreate, nil
+	}
+	if upIfRunning != "" && !slices.Contains(config.IfRunningPolicies, upIfRunning) {
+		return "", fmt.Errorf("--if-running must be one of %s, got %q", strings.Join(config.IfRunningPolicies, ", "), upIfRunning)
+	}
+	return upIfRunning, nil
+}
+
 func init() {
 	upCmd.Flags().BoolVar(&upDryRun, "dry-run", false, "Print execution plan without running commands")
+	upCmd.Flags().StringVar(&upIfRunning, "if-running", "", `What to do with background commands that are already running: "skip", "recreate" or "fail" (default: the config's if_running, or "skip")`)
+	upCmd.Flags().BoolVar(&upRecreate, "recreate", false, "Restart background commands that are already running")
+	upCmd.Flags().BoolVar(&upFailFast, "fail-fast", false, "In parallel mode, cancel the other projects as soon as one fails")
+	upCmd.Flags().BoolVar(&upRollback, "rollback-on-failure", false, "When up fails, run down for the projects that were started")
+	upSelect.register(upCmd)
+	upLock.register(upCmd)
 	rootCmd.AddCommand(upCmd)
 }
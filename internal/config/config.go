@@ -2,16 +2,224 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type CommandItem struct {
-	Command    string `yaml:"command"`
-	Background bool   `yaml:"background"`
+	// Name identifies a background command in proc subcommands as
+	// "<config>/<project>/<name>"; unlike its PID, it survives restarts.
+	Name        string       `yaml:"name"`
+	Command     string       `yaml:"command"`
+	Background  bool         `yaml:"background"`
+	HealthCheck *HealthCheck `yaml:"healthcheck"`
+	// Restart is the policy applied by "mdc supervise" when a background
+	// command exits: RestartNo (default), RestartOnFailure or RestartAlways.
+	Restart     string `yaml:"restart"`
+	MaxRestarts int    `yaml:"max_restarts"`
+	// StopSignal is sent to the process tree of a background command when
+	// it is stopped (default: SIGTERM). Processes still alive after
+	// StopTimeout are killed.
+	StopSignal  string   `yaml:"stop_signal"`
+	StopTimeout Duration `yaml:"stop_timeout"`
+	// IfRunning decides what happens when a background command is started
+	// while the same command of the project is already running from the
+	// same directory: IfRunningSkip (default), IfRunningRecreate or
+	// IfRunningFail.
+	IfRunning string `yaml:"if_running"`
+	// ExecPolicy sets the timeout, retries and continue_on_error of the
+	// command.
+	ExecPolicy `yaml:",inline"`
+
+	Env     map[string]string `yaml:"env"`
+	EnvFile []string          `yaml:"env_file"`
+}
+
+// Restart policies for background commands.
+const (
+	RestartNo        = "no"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+// DefaultMaxRestarts is used when a restart policy is set without max_restarts.
+const DefaultMaxRestarts = 5
+
+// RestartPolicy returns the effective restart policy, defaulting to RestartNo.
+func (c CommandItem) RestartPolicy() string {
+	if c.Restart == "" {
+		return RestartNo
+	}
+	return c.Restart
+}
+
+// RestartLimit returns the maximum number of restarts allowed by the policy.
+func (c CommandItem) RestartLimit() int {
+	if c.MaxRestarts <= 0 {
+		return DefaultMaxRestarts
+	}
+	return c.MaxRestarts
+}
+
+// Policies for background commands that are already running.
+const (
+	IfRunningSkip     = "skip"
+	IfRunningRecreate = "recreate"
+	IfRunningFail     = "fail"
+)
+
+// IfRunningPolicies lists the values accepted by if_running.
+var IfRunningPolicies = []string{IfRunningSkip, IfRunningRecreate, IfRunningFail}
+
+// IfRunningPolicy returns the effective if_running policy, defaulting to
+// IfRunningSkip.
+func (c CommandItem) IfRunningPolicy() string {
+	if c.IfRunning == "" {
+		return IfRunningSkip
+	}
+	return c.IfRunning
+}
+
+// Stop defaults for background commands.
+const (
+	DefaultStopSignal  = "SIGTERM"
+	DefaultStopTimeout = 10 * time.Second
+)
+
+// StopSignals lists the signals accepted by stop_signal.
+var StopSignals = []string{"SIGTERM", "SIGINT", "SIGHUP", "SIGQUIT", "SIGKILL", "SIGUSR1", "SIGUSR2"}
+
+// StopSignalName returns the effective stop signal in its canonical form
+// ("int" and "SIGINT" both become "SIGINT").
+func (c CommandItem) StopSignalName() string {
+	if c.StopSignal == "" {
+		return DefaultStopSignal
+	}
+	name := strings.ToUpper(strings.TrimSpace(c.StopSignal))
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	return name
+}
+
+// StopGracePeriod returns how long the processes get to exit after the stop
+// signal before they are killed.
+func (c CommandItem) StopGracePeriod() time.Duration {
+	if c.StopTimeout <= 0 {
+		return DefaultStopTimeout
+	}
+	return time.Duration(c.StopTimeout)
+}
+
+// ExecPolicy controls how foreground commands are run: how long they may
+// take, how often they are retried and whether their project goes on when
+// they fail. It is set on a command, or on a project or at the top level as
+// the default of their commands; a command inherits the fields it does not
+// set from its project, and the project from the top level.
+type ExecPolicy struct {
+	// Timeout stops a command, along with its children, when it runs
+	// longer. Zero means no timeout.
+	Timeout *Duration `yaml:"timeout"`
+	// Retries is how many more times a failed command is run.
+	Retries *int `yaml:"retries"`
+	// RetryDelay is the delay before the first retry. It doubles with every
+	// further retry.
+	RetryDelay *Duration `yaml:"retry_delay"`
+	// ContinueOnError lets the project go on with its next command when the
+	// command still fails after its retries.
+	ContinueOnError *bool `yaml:"continue_on_error"`
+}
+
+// DefaultRetryDelay is used when retries are set without retry_delay.
+const DefaultRetryDelay = time.Second
+
+// TimeoutDuration returns the timeout of a command, or zero when it has none.
+func (e ExecPolicy) TimeoutDuration() time.Duration {
+	if e.Timeout == nil {
+		return 0
+	}
+	return time.Duration(*e.Timeout)
+}
+
+// MaxRetries returns how many times a failed command is retried.
+func (e ExecPolicy) MaxRetries() int {
+	if e.Retries == nil {
+		return 0
+	}
+	return *e.Retries
+}
+
+// MaxRetryDelay caps the growth of the delay between retries. A longer
+// retry_delay is used as is.
+const MaxRetryDelay = time.Minute
+
+// RetryBackoff returns the delay before the given retry (1 for the first):
+// RetryDelay, doubled for every retry before it, up to MaxRetryDelay.
+func (e ExecPolicy) RetryBackoff(retry int) time.Duration {
+	delay := DefaultRetryDelay
+	if e.RetryDelay != nil {
+		delay = time.Duration(*e.RetryDelay)
+	}
+	limit := max(delay, MaxRetryDelay)
+	// Doubling stops at the limit, so that the delay cannot overflow.
+	for i := 1; i < retry && delay > 0 && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// ContinuesOnError reports whether a failure of the command is ignored.
+func (e ExecPolicy) ContinuesOnError() bool {
+	return e.ContinueOnError != nil && *e.ContinueOnError
+}
+
+// inherit returns the policy with the fields it does not set taken from
+// defaults.
+func (e ExecPolicy) inherit(defaults ExecPolicy) ExecPolicy {
+	if e.Timeout == nil {
+		e.Timeout = defaults.Timeout
+	}
+	if e.Retries == nil {
+		e.Retries = defaults.Retries
+	}
+	if e.RetryDelay == nil {
+		e.RetryDelay = defaults.RetryDelay
+	}
+	if e.ContinueOnError == nil {
+		e.ContinueOnError = defaults.ContinueOnError
+	}
+	return e
+}
+
+// isSet reports whether any field of the policy is set.
+func (e ExecPolicy) isSet() bool {
+	return e.setsForeground() || e.ContinueOnError != nil
+}
+
+// setsForeground reports whether fields that only apply to foreground
+// commands are set.
+func (e ExecPolicy) setsForeground() bool {
+	return e.Timeout != nil || e.Retries != nil || e.RetryDelay != nil
+}
+
+func (e ExecPolicy) validate() error {
+	if e.TimeoutDuration() < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if e.MaxRetries() < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if e.RetryDelay != nil && *e.RetryDelay < 0 {
+		return fmt.Errorf("retry_delay must not be negative")
+	}
+	return nil
 }
 
 func (c *CommandItem) UnmarshalYAML(value *yaml.Node) error {
@@ -31,17 +239,194 @@ func (c *CommandItem) UnmarshalYAML(value *yaml.Node) error {
 type Commands struct {
 	Up   []CommandItem `yaml:"up"`
 	Down []CommandItem `yaml:"down"`
+	// Restart replaces down and up for "mdc restart". Its commands run in
+	// the foreground, after which the project's background processes are
+	// restarted.
+	Restart []CommandItem `yaml:"restart"`
+	// Actions holds the named command lists run by "mdc run", e.g. "migrate"
+	// or "seed".
+	Actions map[string][]CommandItem `yaml:"actions"`
+
+	// unknown holds the other keys found under commands, e.g. a misspelled
+	// "restrat" or an action outside of actions, so that validate can
+	// reject them instead of silently ignoring their commands.
+	unknown []string
+}
+
+// commandsKeys are the keys allowed under commands.
+var commandsKeys = []string{"up", "down", "restart", "actions"}
+
+func (c *Commands) UnmarshalYAML(value *yaml.Node) error {
+	type raw Commands
+	var r raw
+	if err := value.Decode(&r); err != nil {
+		return err
+	}
+	*c = Commands(r)
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if key := value.Content[i].Value; !slices.Contains(commandsKeys, key) {
+				c.unknown = append(c.unknown, key)
+			}
+		}
+	}
+	return nil
+}
+
+// Built-in actions. Any other action is looked up in Commands.Actions.
+const (
+	ActionUp   = "up"
+	ActionDown = "down"
+)
+
+// ForAction returns the commands of an action, or nil when the project does
+// not define it.
+func (c Commands) ForAction(action string) []CommandItem {
+	switch action {
+	case ActionUp:
+		return c.Up
+	case ActionDown:
+		return c.Down
+	default:
+		return c.Actions[action]
+	}
+}
+
+// all returns every command list that "mdc up", "mdc down", "mdc restart"
+// or "mdc run" can execute, with named actions in name order.
+func (c Commands) all() [][]CommandItem {
+	lists := [][]CommandItem{c.Up, c.Down, c.Restart}
+	for _, name := range slices.Sorted(maps.Keys(c.Actions)) {
+		lists = append(lists, c.Actions[name])
+	}
+	return lists
 }
 
 type Project struct {
-	Name     string   `yaml:"name"`
-	Path     string   `yaml:"path"`
-	Commands Commands `yaml:"commands"`
+	Name        string       `yaml:"name"`
+	Path        string       `yaml:"path"`
+	Tags        []string     `yaml:"tags"`
+	DependsOn   []string     `yaml:"depends_on"`
+	HealthCheck *HealthCheck `yaml:"healthcheck"`
+	Commands    Commands     `yaml:"commands"`
+	// ExecPolicy holds the defaults of the project's commands.
+	ExecPolicy `yaml:",inline"`
+
+	Env     map[string]string `yaml:"env"`
+	EnvFile []string          `yaml:"env_file"`
 }
 
 type Config struct {
 	ExecutionMode string    `yaml:"execution_mode"`
 	Projects      []Project `yaml:"projects"`
+
+	Env     map[string]string `yaml:"env"`
+	EnvFile []string          `yaml:"env_file"`
+
+	Logs LogConfig `yaml:"logs"`
+	// ExecPolicy holds the defaults of every project's commands.
+	ExecPolicy `yaml:",inline"`
+}
+
+// Duration is a time.Duration that is written as a Go duration string
+// (e.g. "500ms", "30s", "2m") in YAML.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q: %w", value.Line, value.Value, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+const (
+	DefaultHealthCheckInterval = time.Second
+	DefaultHealthCheckTimeout  = 60 * time.Second
+)
+
+// HealthCheck describes a readiness probe. Exactly one of TCP, HTTP,
+// Command or LogMatch must be set.
+type HealthCheck struct {
+	// TCP is a "host:port" address that must accept connections.
+	TCP string `yaml:"tcp"`
+	// HTTP is a URL that must answer a GET request with Status
+	// (or any 2xx/3xx status when Status is 0).
+	HTTP   string `yaml:"http"`
+	Status int    `yaml:"status"`
+	// Command is a shell command, run in the project directory, that must exit 0.
+	Command string `yaml:"command"`
+	// LogMatch is a regular expression that must match the proc log output.
+	LogMatch string `yaml:"log_match"`
+
+	// Interval is the delay between probes.
+	Interval Duration `yaml:"interval"`
+	// Timeout is the total time to wait for the check to pass.
+	Timeout Duration `yaml:"timeout"`
+	// Retries is the maximum number of failed probes before giving up.
+	// Zero means probing continues until Timeout elapses.
+	Retries int `yaml:"retries"`
+}
+
+func (h *HealthCheck) IntervalDuration() time.Duration {
+	if h.Interval <= 0 {
+		return DefaultHealthCheckInterval
+	}
+	return time.Duration(h.Interval)
+}
+
+func (h *HealthCheck) TimeoutDuration() time.Duration {
+	if h.Timeout <= 0 {
+		return DefaultHealthCheckTimeout
+	}
+	return time.Duration(h.Timeout)
+}
+
+// String returns a short human-readable description such as "tcp localhost:3000".
+func (h *HealthCheck) String() string {
+	switch {
+	case h.TCP != "":
+		return "tcp " + h.TCP
+	case h.HTTP != "":
+		if h.Status != 0 {
+			return fmt.Sprintf("http %s (status %d)", h.HTTP, h.Status)
+		}
+		return "http " + h.HTTP
+	case h.Command != "":
+		return "command " + h.Command
+	case h.LogMatch != "":
+		return fmt.Sprintf("log /%s/", h.LogMatch)
+	}
+	return "none"
+}
+
+func (h *HealthCheck) validate() error {
+	set := 0
+	for _, v := range []string{h.TCP, h.HTTP, h.Command, h.LogMatch} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("healthcheck must set exactly one of tcp, http, command or log_match")
+	}
+	if h.LogMatch != "" {
+		if _, err := regexp.Compile(h.LogMatch); err != nil {
+			return fmt.Errorf("healthcheck log_match: %w", err)
+		}
+	}
+	if h.Status != 0 && (h.Status < 100 || h.Status > 599) {
+		return fmt.Errorf("healthcheck status must be a valid HTTP status code, got %d", h.Status)
+	}
+	if h.Retries < 0 {
+		return fmt.Errorf("healthcheck retries must not be negative")
+	}
+	return nil
 }
 
 func ExpandHome(path string) (string, error) {
@@ -146,12 +531,29 @@ func LoadFromDir(configDir, name string) (*Config, error) {
 		return nil, fmt.Errorf("invalid config %q: %w", name, err)
 	}
 
+	if cfg.EnvFile, err = resolveEnvFiles(cfg.EnvFile, configDir); err != nil {
+		return nil, err
+	}
+
 	for i := range cfg.Projects {
-		expanded, err := ExpandHome(cfg.Projects[i].Path)
+		p := &cfg.Projects[i]
+		expanded, err := ExpandHome(p.Path)
 		if err != nil {
-			return nil, fmt.Errorf("project %q: %w", cfg.Projects[i].Name, err)
+			return nil, fmt.Errorf("project %q: %w", p.Name, err)
+		}
+		p.Path = expanded
+
+		if p.EnvFile, err = resolveEnvFiles(p.EnvFile, p.Path); err != nil {
+			return nil, fmt.Errorf("project %q: %w", p.Name, err)
 		}
-		cfg.Projects[i].Path = expanded
+		for _, items := range p.Commands.all() {
+			for j := range items {
+				if items[j].EnvFile, err = resolveEnvFiles(items[j].EnvFile, p.Path); err != nil {
+					return nil, fmt.Errorf("project %q: %w", p.Name, err)
+				}
+			}
+		}
+		p.applyExecPolicy(cfg.ExecPolicy)
 	}
 
 	return &cfg, nil
@@ -161,16 +563,42 @@ const configTemplate = `# mdc 設定ファイル
 # コメントを外して、プロジェクトの情報を記入してください。
 #
 # execution_mode: プロジェクト間の実行モード
-#   "parallel"    - 全プロジェクトを同時に実行
-#   "sequential"  - プロジェクトを定義順に1つずつ処理
+#   "parallel"    - 依存関係が完了したプロジェクトを同時に実行
+#   "sequential"  - プロジェクトを依存関係 (depends_on) の順に1つずつ処理 (依存関係のないプロジェクト同士は定義順)
 #
 # projects[].name: プロジェクト名 (ログ出力のプレフィックスに使用)
 # projects[].path: プロジェクトのディレクトリパス (~展開対応)
+# projects[].tags: --tag で絞り込むためのタグのリスト
+# projects[].depends_on: 先に起動を完了させる必要があるプロジェクト名のリスト
 # projects[].commands.up: 起動時に実行するコマンドのリスト
 # projects[].commands.down: 停止時に実行するコマンドのリスト
+# projects[].commands.restart: mdc restart で down / up の代わりに実行するコマンドのリスト (任意、実行後にバックグラウンドプロセスを再起動)
+# projects[].commands.actions.<action>: mdc run <config> <action> で実行する任意の名前のコマンドリスト (migrate / seed など)
 # commands[][].command: 実行するコマンド文字列
 # commands[][].background: true でバックグラウンド実行 (デフォルト: false)
-
+# commands[][].healthcheck: 起動完了を判定するヘルスチェック (tcp / http / command / log_match)
+# commands[][].restart: mdc supervise 実行中の再起動ポリシー ("no" / "on-failure" / "always")
+# commands[][].max_restarts: 再起動回数の上限 (デフォルト: 5)
+# commands[][].name: バックグラウンドコマンドの名前 (mdc proc stop <config>/<project>/<name> のように PID の代わりに指定可能)
+# commands[][].stop_signal: 停止時にプロセスツリーへ送るシグナル ("SIGTERM" / "SIGINT" など、デフォルト: "SIGTERM")
+# commands[][].stop_timeout: 停止シグナル後に強制終了するまでの猶予 (デフォルト: "10s")
+# commands[][].if_running: 同じバックグラウンドコマンドが実行中の場合の動作 ("skip" / "recreate" / "fail"、デフォルト: "skip")
+# commands[][].timeout: フォアグラウンドコマンドのタイムアウト (超えるとプロセスグループごと停止、デフォルト: なし)
+# commands[][].retries: 失敗したフォアグラウンドコマンドを再試行する回数 (デフォルト: 0)
+# commands[][].retry_delay: 最初の再試行までの待ち時間 (再試行のたびに倍増、デフォルト: "1s")
+# commands[][].continue_on_error: true にすると失敗してもプロジェクトの次のコマンドに進む
+#   timeout / retries / retry_delay / continue_on_error はトップレベル・プロジェクトでもデフォルトとして指定可能
+
+# env / env_file: 環境変数とdotenvファイル (トップレベル・プロジェクト・コマンドで指定可能)
+#   優先順位: トップレベル < プロジェクト < コマンド (各レベルで env_file < env)
+#   値の中の ${VAR} / ${VAR:-default} は展開されます
+#
+# logs: バックグラウンドプロセスのログのローテーションと保持期間
+#   max_size: ローテーションするサイズ (デフォルト: "10MB")
+#   max_files: 保持するローテーション済みファイル数 (デフォルト: 3)
+#   max_age: ローテーション済み・停止済みプロセスのログの保持期間 (デフォルト: "168h")
+#   keep_stopped: true にすると mdc down で停止したプロセスのログを max_age まで残す
+#
 # execution_mode: "parallel"
 # projects:
 #   - name: "Frontend"
@@ -180,6 +608,9 @@ const configTemplate = `# mdc 設定ファイル
 #         - command: "docker compose up -d"
 #         - command: "npm run dev"
 #           background: true
+#           healthcheck:
+#             tcp: "localhost:3000"
+#             timeout: "60s"
 #       down:
 #         - command: "docker compose down"
 #
@@ -268,6 +699,13 @@ func (c *Config) validate() error {
 		return fmt.Errorf("at least one project must be defined")
 	}
 
+	if err := c.Logs.validate(); err != nil {
+		return err
+	}
+	if err := c.ExecPolicy.validate(); err != nil {
+		return err
+	}
+
 	for i, p := range c.Projects {
 		if p.Name == "" {
 			return fmt.Errorf("project[%d]: name is required", i)
@@ -275,7 +713,221 @@ func (c *Config) validate() error {
 		if p.Path == "" {
 			return fmt.Errorf("project %q: path is required", p.Name)
 		}
+		if p.HealthCheck != nil {
+			if err := p.HealthCheck.validate(); err != nil {
+				return fmt.Errorf("project %q: %w", p.Name, err)
+			}
+			if p.HealthCheck.LogMatch != "" && !hasBackground(p.Commands.Up) {
+				return fmt.Errorf("project %q: healthcheck log_match requires a background command in up", p.Name)
+			}
+		}
+		if err := p.ExecPolicy.validate(); err != nil {
+			return fmt.Errorf("project %q: %w", p.Name, err)
+		}
+		if len(p.Commands.unknown) > 0 {
+			return fmt.Errorf("project %q: unknown key %q under commands: want up, down, restart or actions (named actions go under commands.actions)", p.Name, p.Commands.unknown[0])
+		}
+		for name := range p.Commands.Actions {
+			if slices.Contains(commandsKeys, name) {
+				return fmt.Errorf("project %q: action name %q is reserved", p.Name, name)
+			}
+		}
+		for _, item := range p.Commands.Restart {
+			if item.Background {
+				return fmt.Errorf("project %q: restart command %q: background is not supported; background processes are restarted after the restart commands", p.Name, item.Command)
+			}
+		}
+		names := make(map[string]bool)
+		for _, items := range p.Commands.all() {
+			for _, item := range items {
+				if err := item.validateName(); err != nil {
+					return fmt.Errorf("project %q: command %q: %w", p.Name, item.Command, err)
+				}
+				if item.Name != "" {
+					if names[item.Name] {
+						return fmt.Errorf("project %q: duplicate command name %q", p.Name, item.Name)
+					}
+					names[item.Name] = true
+				}
+				switch item.RestartPolicy() {
+				case RestartNo, RestartOnFailure, RestartAlways:
+				default:
+					return fmt.Errorf("project %q: command %q: restart must be \"no\", \"on-failure\" or \"always\", got %q", p.Name, item.Command, item.Restart)
+				}
+				if item.RestartPolicy() != RestartNo && !item.Background {
+					return fmt.Errorf("project %q: command %q: restart requires background: true", p.Name, item.Command)
+				}
+				if err := item.validateStop(); err != nil {
+					return fmt.Errorf("project %q: command %q: %w", p.Name, item.Command, err)
+				}
+				if err := item.validateIfRunning(); err != nil {
+					return fmt.Errorf("project %q: command %q: %w", p.Name, item.Command, err)
+				}
+				if err := item.validateExecPolicy(); err != nil {
+					return fmt.Errorf("project %q: command %q: %w", p.Name, item.Command, err)
+				}
+				if item.HealthCheck == nil {
+					continue
+				}
+				if err := item.HealthCheck.validate(); err != nil {
+					return fmt.Errorf("project %q: command %q: %w", p.Name, item.Command, err)
+				}
+				if item.HealthCheck.LogMatch != "" && !item.Background {
+					return fmt.Errorf("project %q: command %q: healthcheck log_match requires background: true", p.Name, item.Command)
+				}
+			}
+		}
+	}
+
+	return c.validateDependencies()
+}
+
+func hasBackground(items []CommandItem) bool {
+	for _, item := range items {
+		if item.Background {
+			return true
+		}
+	}
+	return false
+}
+
+func (c CommandItem) validateName() error {
+	if c.Name == "" {
+		return nil
+	}
+	if !c.Background {
+		return fmt.Errorf("name requires background: true")
+	}
+	if strings.ContainsAny(c.Name, "/ \t") {
+		return fmt.Errorf("name must not contain \"/\" or whitespace, got %q", c.Name)
+	}
+	return nil
+}
+
+func (c CommandItem) validateStop() error {
+	if c.StopSignal == "" && c.StopTimeout == 0 {
+		return nil
+	}
+	if !c.Background {
+		return fmt.Errorf("stop_signal and stop_timeout require background: true")
+	}
+	if !slices.Contains(StopSignals, c.StopSignalName()) {
+		return fmt.Errorf("stop_signal must be one of %s, got %q", strings.Join(StopSignals, ", "), c.StopSignal)
+	}
+	if c.StopTimeout < 0 {
+		return fmt.Errorf("stop_timeout must not be negative")
+	}
+	return nil
+}
+
+func (c CommandItem) validateIfRunning() error {
+	if c.IfRunning == "" {
+		return nil
+	}
+	if !c.Background {
+		return fmt.Errorf("if_running requires background: true")
+	}
+	if !slices.Contains(IfRunningPolicies, c.IfRunning) {
+		return fmt.Errorf("if_running must be one of %s, got %q", strings.Join(IfRunningPolicies, ", "), c.IfRunning)
+	}
+	return nil
+}
+
+func (c CommandItem) validateExecPolicy() error {
+	if c.Background && c.ExecPolicy.setsForeground() {
+		return fmt.Errorf("timeout, retries and retry_delay require a foreground command")
+	}
+	return c.ExecPolicy.validate()
+}
+
+// applyExecPolicy resolves the policy of every command of the project from
+// its own fields, the project's and then the config's.
+func (p *Project) applyExecPolicy(defaults ExecPolicy) {
+	defaults = p.ExecPolicy.inherit(defaults)
+	if !defaults.isSet() {
+		return
+	}
+	for _, items := range p.Commands.all() {
+		for j := range items {
+			items[j].ExecPolicy = items[j].ExecPolicy.inherit(defaults)
+		}
+	}
+}
+
+// SetIfRunning overrides the if_running policy of every background command,
+// e.g. from a command-line flag.
+func (c *Config) SetIfRunning(policy string) {
+	for i := range c.Projects {
+		for _, items := range c.Projects[i].Commands.all() {
+			for j := range items {
+				if items[j].Background {
+					items[j].IfRunning = policy
+				}
+			}
+		}
 	}
+}
 
+func (c *Config) validateDependencies() error {
+	index := make(map[string]int, len(c.Projects))
+	for i, p := range c.Projects {
+		if _, dup := index[p.Name]; dup {
+			return fmt.Errorf("project %q: duplicate project name", p.Name)
+		}
+		index[p.Name] = i
+	}
+
+	for _, p := range c.Projects {
+		for _, dep := range p.DependsOn {
+			if dep == p.Name {
+				return fmt.Errorf("project %q: cannot depend on itself", p.Name)
+			}
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("project %q: depends_on references unknown project %q", p.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(c.Projects))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			start := 0
+			for j, name := range path {
+				if name == c.Projects[i].Name {
+					start = j
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), c.Projects[i].Name)
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		path = append(path, c.Projects[i].Name)
+		for _, dep := range c.Projects[i].DependsOn {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range c.Projects {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
 	return nil
 }
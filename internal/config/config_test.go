@@ -1,10 +1,12 @@
 package config
 
 import (
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestExpandHome(t *testing.T) {
@@ -173,6 +175,319 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: "path is required",
 		},
+		{
+			name: "valid depends_on",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{
+					{Name: "db", Path: "/tmp"},
+					{Name: "api", Path: "/tmp", DependsOn: []string{"db"}},
+					{Name: "web", Path: "/tmp", DependsOn: []string{"api", "db"}},
+				},
+			},
+		},
+		{
+			name: "duplicate project name",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects:      []Project{{Name: "svc", Path: "/tmp"}, {Name: "svc", Path: "/tmp"}},
+			},
+			wantErr: "duplicate project name",
+		},
+		{
+			name: "depends_on unknown project",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects:      []Project{{Name: "svc", Path: "/tmp", DependsOn: []string{"db"}}},
+			},
+			wantErr: "unknown project \"db\"",
+		},
+		{
+			name: "depends_on itself",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects:      []Project{{Name: "svc", Path: "/tmp", DependsOn: []string{"svc"}}},
+			},
+			wantErr: "cannot depend on itself",
+		},
+		{
+			name: "dependency cycle",
+			cfg: Config{
+				ExecutionMode: "sequential",
+				Projects: []Project{
+					{Name: "a", Path: "/tmp", DependsOn: []string{"c"}},
+					{Name: "b", Path: "/tmp", DependsOn: []string{"a"}},
+					{Name: "c", Path: "/tmp", DependsOn: []string{"b"}},
+				},
+			},
+			wantErr: "dependency cycle detected: a -> c -> b -> a",
+		},
+		{
+			name: "valid command healthcheck",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "npm run dev", Background: true, HealthCheck: &HealthCheck{LogMatch: "ready in \\d+ms"}}},
+				}}},
+			},
+		},
+		{
+			name: "healthcheck without probe",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects:      []Project{{Name: "svc", Path: "/tmp", HealthCheck: &HealthCheck{}}},
+			},
+			wantErr: "exactly one of tcp, http, command or log_match",
+		},
+		{
+			name: "healthcheck with two probes",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects:      []Project{{Name: "svc", Path: "/tmp", HealthCheck: &HealthCheck{TCP: "localhost:80", HTTP: "http://localhost"}}},
+			},
+			wantErr: "exactly one of tcp, http, command or log_match",
+		},
+		{
+			name: "healthcheck invalid regex",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "npm run dev", Background: true, HealthCheck: &HealthCheck{LogMatch: "("}}},
+				}}},
+			},
+			wantErr: "healthcheck log_match",
+		},
+		{
+			name: "healthcheck log_match on foreground command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "make build", HealthCheck: &HealthCheck{LogMatch: "done"}}},
+				}}},
+			},
+			wantErr: "log_match requires background: true",
+		},
+		{
+			name: "project log_match without background command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", HealthCheck: &HealthCheck{LogMatch: "ready"}, Commands: Commands{
+					Up: []CommandItem{{Command: "make build"}},
+				}}},
+			},
+			wantErr: "healthcheck log_match requires a background command in up",
+		},
+		{
+			name: "project log_match with background command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", HealthCheck: &HealthCheck{LogMatch: "ready"}, Commands: Commands{
+					Up: []CommandItem{{Command: "make build"}, {Command: "npm run dev", Background: true}},
+				}}},
+			},
+		},
+		{
+			name: "healthcheck invalid status",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects:      []Project{{Name: "svc", Path: "/tmp", HealthCheck: &HealthCheck{HTTP: "http://localhost", Status: 42}}},
+			},
+			wantErr: "valid HTTP status code",
+		},
+		{
+			name: "valid restart policy",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "npm run dev", Background: true, Restart: RestartOnFailure, MaxRestarts: 3}},
+				}}},
+			},
+		},
+		{
+			name: "invalid restart policy",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "npm run dev", Background: true, Restart: "sometimes"}},
+				}}},
+			},
+			wantErr: "restart must be",
+		},
+		{
+			name: "restart policy on foreground command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "make build", Restart: RestartAlways}},
+				}}},
+			},
+			wantErr: "restart requires background: true",
+		},
+		{
+			name: "valid stop signal",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "npm run dev", Background: true, StopSignal: "int", StopTimeout: Duration(5 * time.Second)}},
+				}}},
+			},
+		},
+		{
+			name: "invalid stop signal",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "npm run dev", Background: true, StopSignal: "SIGSTOP"}},
+				}}},
+			},
+			wantErr: "stop_signal must be one of",
+		},
+		{
+			name: "stop signal on foreground command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "make build", StopTimeout: Duration(time.Second)}},
+				}}},
+			},
+			wantErr: "require background: true",
+		},
+		{
+			name: "valid command names",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{
+					{Name: "svc", Path: "/tmp", Commands: Commands{
+						Up: []CommandItem{{Name: "web", Command: "npm run dev", Background: true}, {Name: "worker", Command: "npm run worker", Background: true}},
+					}},
+					{Name: "api", Path: "/tmp", Commands: Commands{
+						Up: []CommandItem{{Name: "web", Command: "go run .", Background: true}},
+					}},
+				},
+			},
+		},
+		{
+			name: "duplicate command name",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Name: "web", Command: "npm run dev", Background: true}, {Name: "web", Command: "npm start", Background: true}},
+				}}},
+			},
+			wantErr: "duplicate command name \"web\"",
+		},
+		{
+			name: "command name with a slash",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Name: "web/dev", Command: "npm run dev", Background: true}},
+				}}},
+			},
+			wantErr: "must not contain",
+		},
+		{
+			name: "name on foreground command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Name: "build", Command: "make build"}},
+				}}},
+			},
+			wantErr: "name requires background: true",
+		},
+		{
+			name: "invalid if_running",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "npm run dev", Background: true, IfRunning: "ignore"}},
+				}}},
+			},
+			wantErr: "if_running must be one of skip, recreate, fail",
+		},
+		{
+			name: "if_running on foreground command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "make build", IfRunning: IfRunningFail}},
+				}}},
+			},
+			wantErr: "if_running requires background: true",
+		},
+		{
+			name: "valid restart commands",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up:      []CommandItem{{Command: "docker compose up -d"}},
+					Restart: []CommandItem{{Command: "docker compose restart"}},
+				}}},
+			},
+		},
+		{
+			name: "background restart command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Restart: []CommandItem{{Command: "npm run dev", Background: true}},
+				}}},
+			},
+			wantErr: "background is not supported",
+		},
+		{
+			name: "invalid named action",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Actions: map[string][]CommandItem{"migrate": {{
+						Command:     "npm run migrate",
+						Background:  true,
+						HealthCheck: &HealthCheck{LogMatch: "("},
+					}}},
+				}}},
+			},
+			wantErr: `command "npm run migrate": healthcheck log_match`,
+		},
+		{
+			name: "duplicate name in named action",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up:      []CommandItem{{Command: "npm run dev", Background: true, Name: "web"}},
+					Actions: map[string][]CommandItem{"preview": {{Command: "npm run preview", Background: true, Name: "web"}}},
+				}}},
+			},
+			wantErr: `duplicate command name "web"`,
+		},
+		{
+			name: "negative retries",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects:      []Project{{Name: "svc", Path: "/tmp", ExecPolicy: ExecPolicy{Retries: ptr(-1)}}},
+			},
+			wantErr: "retries must not be negative",
+		},
+		{
+			name: "timeout on background command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "npm run dev", Background: true, ExecPolicy: ExecPolicy{Timeout: ptr(Duration(time.Minute))}}},
+				}}},
+			},
+			wantErr: "require a foreground command",
+		},
+		{
+			name: "continue_on_error on background command",
+			cfg: Config{
+				ExecutionMode: "parallel",
+				Projects: []Project{{Name: "svc", Path: "/tmp", Commands: Commands{
+					Up: []CommandItem{{Command: "npm run dev", Background: true, ExecPolicy: ExecPolicy{ContinueOnError: ptr(true)}}},
+				}}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -533,6 +848,342 @@ projects:
 	})
 }
 
+func TestHealthCheckUnmarshalYAML(t *testing.T) {
+	t.Run("durations and defaults", func(t *testing.T) {
+		dir := t.TempDir()
+		yaml := `execution_mode: sequential
+projects:
+  - name: app
+    path: /tmp
+    healthcheck:
+      http: "http://localhost:8080/health"
+      status: 204
+    commands:
+      up:
+        - command: "npm run dev"
+          background: true
+          healthcheck:
+            tcp: "localhost:3000"
+            interval: "250ms"
+            timeout: "30s"
+            retries: 5
+`
+		if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadFromDir(dir, "test")
+		if err != nil {
+			t.Fatalf("LoadFromDir() error: %v", err)
+		}
+
+		hc := cfg.Projects[0].Commands.Up[0].HealthCheck
+		if hc == nil {
+			t.Fatal("command healthcheck should be parsed")
+		}
+		if hc.TCP != "localhost:3000" || hc.Retries != 5 {
+			t.Errorf("HealthCheck = %+v", hc)
+		}
+		if hc.IntervalDuration() != 250*time.Millisecond {
+			t.Errorf("IntervalDuration() = %s, want 250ms", hc.IntervalDuration())
+		}
+		if hc.TimeoutDuration() != 30*time.Second {
+			t.Errorf("TimeoutDuration() = %s, want 30s", hc.TimeoutDuration())
+		}
+
+		projectHC := cfg.Projects[0].HealthCheck
+		if projectHC == nil || projectHC.Status != 204 {
+			t.Fatalf("project healthcheck = %+v", projectHC)
+		}
+		if projectHC.IntervalDuration() != DefaultHealthCheckInterval {
+			t.Errorf("IntervalDuration() = %s, want default", projectHC.IntervalDuration())
+		}
+		if projectHC.TimeoutDuration() != DefaultHealthCheckTimeout {
+			t.Errorf("TimeoutDuration() = %s, want default", projectHC.TimeoutDuration())
+		}
+		if got := projectHC.String(); got != "http http://localhost:8080/health (status 204)" {
+			t.Errorf("String() = %q", got)
+		}
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		dir := t.TempDir()
+		yaml := `execution_mode: sequential
+projects:
+  - name: app
+    path: /tmp
+    healthcheck:
+      tcp: "localhost:3000"
+      timeout: "soon"
+`
+		if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := LoadFromDir(dir, "test")
+		if err == nil {
+			t.Fatal("LoadFromDir() expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "invalid duration") {
+			t.Errorf("error = %q, want containing 'invalid duration'", err.Error())
+		}
+	})
+
+	t.Run("unknown key under commands", func(t *testing.T) {
+		dir := t.TempDir()
+		yaml := `execution_mode: sequential
+projects:
+  - name: app
+    path: /tmp
+    commands:
+      up: ["echo up"]
+      restrat: ["echo restart"]
+`
+		if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := LoadFromDir(dir, "test")
+		if err == nil || !strings.Contains(err.Error(), `unknown key "restrat" under commands`) {
+			t.Errorf("LoadFromDir() error = %v, want unknown key \"restrat\"", err)
+		}
+	})
+
+	t.Run("reserved action name", func(t *testing.T) {
+		dir := t.TempDir()
+		yaml := `execution_mode: sequential
+projects:
+  - name: app
+    path: /tmp
+    commands:
+      up: ["echo up"]
+      actions:
+        restart: ["echo restart"]
+`
+		if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := LoadFromDir(dir, "test")
+		if err == nil || !strings.Contains(err.Error(), `action name "restart" is reserved`) {
+			t.Errorf("LoadFromDir() error = %v, want reserved action name", err)
+		}
+	})
+}
+
+func TestStopSettings(t *testing.T) {
+	tests := []struct {
+		item        CommandItem
+		wantSignal  string
+		wantTimeout time.Duration
+	}{
+		{item: CommandItem{}, wantSignal: "SIGTERM", wantTimeout: DefaultStopTimeout},
+		{item: CommandItem{StopSignal: "int"}, wantSignal: "SIGINT", wantTimeout: DefaultStopTimeout},
+		{item: CommandItem{StopSignal: " SIGhup ", StopTimeout: Duration(3 * time.Second)}, wantSignal: "SIGHUP", wantTimeout: 3 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.item.StopSignalName(); got != tt.wantSignal {
+			t.Errorf("StopSignalName(%q) = %q, want %q", tt.item.StopSignal, got, tt.wantSignal)
+		}
+		if got := tt.item.StopGracePeriod(); got != tt.wantTimeout {
+			t.Errorf("StopGracePeriod(%s) = %s, want %s", tt.item.StopTimeout, got, tt.wantTimeout)
+		}
+	}
+}
+
+func TestRestartPolicyUnmarshalYAML(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `execution_mode: sequential
+projects:
+  - name: app
+    path: /tmp
+    commands:
+      up:
+        - command: "npm run dev"
+          background: true
+          restart: always
+          max_restarts: 10
+        - command: "npm run worker"
+          background: true
+          restart: no
+        - command: "make build"
+`
+	if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromDir(dir, "test")
+	if err != nil {
+		t.Fatalf("LoadFromDir() error: %v", err)
+	}
+	up := cfg.Projects[0].Commands.Up
+	if up[0].RestartPolicy() != RestartAlways || up[0].RestartLimit() != 10 {
+		t.Errorf("Up[0] policy = %q/%d, want always/10", up[0].RestartPolicy(), up[0].RestartLimit())
+	}
+	if up[1].RestartPolicy() != RestartNo {
+		t.Errorf("Up[1] policy = %q, want %q", up[1].RestartPolicy(), RestartNo)
+	}
+	if up[2].RestartPolicy() != RestartNo || up[2].RestartLimit() != DefaultMaxRestarts {
+		t.Errorf("Up[2] policy = %q/%d, want no/%d", up[2].RestartPolicy(), up[2].RestartLimit(), DefaultMaxRestarts)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestExecPolicyUnmarshalYAML(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `execution_mode: sequential
+timeout: 5m
+retries: 2
+projects:
+  - name: app
+    path: /tmp
+    retry_delay: 500ms
+    continue_on_error: true
+    commands:
+      up:
+        - "npm ci"
+        - command: "make build"
+          timeout: 0s
+          retries: 0
+          continue_on_error: false
+      restart:
+        - command: "docker compose restart"
+          timeout: 30s
+  - name: db
+    path: /tmp
+    commands:
+      up: ["docker compose up -d"]
+`
+	if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromDir(dir, "test")
+	if err != nil {
+		t.Fatalf("LoadFromDir() error: %v", err)
+	}
+	tests := []struct {
+		name         string
+		item         CommandItem
+		wantTimeout  time.Duration
+		wantRetries  int
+		wantDelay    time.Duration
+		wantContinue bool
+	}{
+		{"inherited", cfg.Projects[0].Commands.Up[0], 5 * time.Minute, 2, 500 * time.Millisecond, true},
+		{"overridden", cfg.Projects[0].Commands.Up[1], 0, 0, 500 * time.Millisecond, false},
+		{"restart command", cfg.Projects[0].Commands.Restart[0], 30 * time.Second, 2, 500 * time.Millisecond, true},
+		{"config defaults", cfg.Projects[1].Commands.Up[0], 5 * time.Minute, 2, DefaultRetryDelay, false},
+	}
+	for _, tt := range tests {
+		if got := tt.item.TimeoutDuration(); got != tt.wantTimeout {
+			t.Errorf("%s: TimeoutDuration() = %s, want %s", tt.name, got, tt.wantTimeout)
+		}
+		if got := tt.item.MaxRetries(); got != tt.wantRetries {
+			t.Errorf("%s: MaxRetries() = %d, want %d", tt.name, got, tt.wantRetries)
+		}
+		if got := tt.item.RetryBackoff(1); got != tt.wantDelay {
+			t.Errorf("%s: RetryBackoff(1) = %s, want %s", tt.name, got, tt.wantDelay)
+		}
+		if got := tt.item.ContinuesOnError(); got != tt.wantContinue {
+			t.Errorf("%s: ContinuesOnError() = %v, want %v", tt.name, got, tt.wantContinue)
+		}
+	}
+	if got := cfg.Projects[0].Commands.Up[0].RetryBackoff(3); got != 2*time.Second {
+		t.Errorf("RetryBackoff(3) = %s, want 2s", got)
+	}
+}
+
+func TestRetryBackoffIsCapped(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay *Duration
+		retry int
+		want  time.Duration
+	}{
+		{"default", nil, 4, 8 * time.Second},
+		{"capped", nil, 10, MaxRetryDelay},
+		{"large retry count", ptr(Duration(time.Second)), 1000, MaxRetryDelay},
+		{"huge retry count", ptr(Duration(time.Second)), math.MaxInt, MaxRetryDelay},
+		{"longer retry_delay", ptr(Duration(5 * time.Minute)), 100, 5 * time.Minute},
+		{"zero retry_delay", ptr(Duration(0)), 1000, 0},
+	}
+	for _, tt := range tests {
+		e := ExecPolicy{RetryDelay: tt.delay}
+		if got := e.RetryBackoff(tt.retry); got != tt.want {
+			t.Errorf("%s: RetryBackoff(%d) = %s, want %s", tt.name, tt.retry, got, tt.want)
+		}
+	}
+}
+
+func TestContinueOnErrorInheritedAlone(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `execution_mode: sequential
+continue_on_error: true
+projects:
+  - name: app
+    path: /tmp
+    commands:
+      up: ["npm ci"]
+      restart: ["docker compose restart"]
+  - name: db
+    path: /tmp
+    continue_on_error: false
+    commands:
+      up: ["docker compose up -d"]
+  - name: api
+    path: /tmp
+    commands:
+      up: ["make build"]
+      actions:
+        migrate: ["make migrate"]
+`
+	if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFromDir(dir, "test")
+	if err != nil {
+		t.Fatalf("LoadFromDir() error: %v", err)
+	}
+	tests := []struct {
+		name string
+		item CommandItem
+		want bool
+	}{
+		{"from config", cfg.Projects[0].Commands.Up[0], true},
+		{"restart command", cfg.Projects[0].Commands.Restart[0], true},
+		{"overridden by project", cfg.Projects[1].Commands.Up[0], false},
+		{"named action", cfg.Projects[2].Commands.ForAction("migrate")[0], true},
+	}
+	for _, tt := range tests {
+		if got := tt.item.ContinuesOnError(); got != tt.want {
+			t.Errorf("%s: ContinuesOnError() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Set alone on a project.
+	yaml = `execution_mode: sequential
+projects:
+  - name: app
+    path: /tmp
+    continue_on_error: true
+    commands:
+      up: ["npm ci", {command: "npm run dev", background: true}]
+`
+	if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = LoadFromDir(dir, "test"); err != nil {
+		t.Fatalf("LoadFromDir() error: %v", err)
+	}
+	for i, item := range cfg.Projects[0].Commands.Up {
+		if !item.ContinuesOnError() {
+			t.Errorf("Up[%d]: continue_on_error set on the project should be inherited", i)
+		}
+	}
+}
+
 func TestCreateConfig(t *testing.T) {
 	t.Run("creates template without extension", func(t *testing.T) {
 		dir := t.TempDir()
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ProjectEnv resolves the environment variables defined at config and
+// project level. Layers are applied in increasing precedence:
+//
+//	config env_file < config env < project env_file < project env
+//
+// Only variables defined by mdc are returned; the process environment is
+// consulted for ${VAR} interpolation but not included in the result.
+func (c *Config) ProjectEnv(p Project) (map[string]string, error) {
+	env := map[string]string{}
+	if err := applyEnv(env, c.EnvFile, c.Env); err != nil {
+		return nil, err
+	}
+	if err := applyEnv(env, p.EnvFile, p.Env); err != nil {
+		return nil, fmt.Errorf("project %q: %w", p.Name, err)
+	}
+	return env, nil
+}
+
+// CommandEnv layers the command's env_file and env on top of base, which is
+// usually the result of ProjectEnv. base is not modified.
+func CommandEnv(base map[string]string, item CommandItem) (map[string]string, error) {
+	env := make(map[string]string, len(base)+len(item.Env))
+	for k, v := range base {
+		env[k] = v
+	}
+	if err := applyEnv(env, item.EnvFile, item.Env); err != nil {
+		return nil, fmt.Errorf("command %q: %w", item.Command, err)
+	}
+	return env, nil
+}
+
+// applyEnv loads files in order and then vars into env. Values may reference
+// variables from earlier layers, earlier lines of the same file or the
+// process environment with ${VAR} or ${VAR:-default}.
+func applyEnv(env map[string]string, files []string, vars map[string]string) error {
+	for _, path := range files {
+		if err := loadEnvFile(env, path); err != nil {
+			return err
+		}
+	}
+
+	// Variables of the same env block cannot reference each other, since
+	// map order is undefined; resolve them all against the previous layers.
+	resolved := make(map[string]string, len(vars))
+	for k, v := range vars {
+		resolved[k] = Interpolate(v, env)
+	}
+	for k, v := range resolved {
+		env[k] = v
+	}
+	return nil
+}
+
+var interpolation = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Interpolate replaces ${VAR} and ${VAR:-default} in s, looking variables up
+// in env first and then in the process environment. Unknown variables
+// without a default expand to an empty string.
+func Interpolate(s string, env map[string]string) string {
+	return interpolation.ReplaceAllStringFunc(s, func(m string) string {
+		sub := interpolation.FindStringSubmatch(m)
+		name, hasDefault, def := sub[1], sub[2] != "", sub[3]
+		if v, ok := env[name]; ok && v != "" {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			return v
+		}
+		if hasDefault {
+			return def
+		}
+		return ""
+	})
+}
+
+// loadEnvFile parses a dotenv file into env. Supported syntax: KEY=VALUE,
+// an optional "export " prefix, # comments, and single or double quoted
+// values. Single-quoted values are taken literally.
+func loadEnvFile(env map[string]string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("env_file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("env_file %s:%d: expected KEY=VALUE", path, lineNo)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			value = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value[1 : len(value)-1])
+			value = Interpolate(value, env)
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+			value = Interpolate(value, env)
+		}
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("env_file %s: %w", path, err)
+	}
+	return nil
+}
+
+// resolveEnvFiles makes env_file paths absolute relative to baseDir,
+// expanding a leading ~.
+func resolveEnvFiles(files []string, baseDir string) ([]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	resolved := make([]string, len(files))
+	for i, f := range files {
+		expanded, err := ExpandHome(f)
+		if err != nil {
+			return nil, err
+		}
+		if !filepath.IsAbs(expanded) {
+			expanded = filepath.Join(baseDir, expanded)
+		}
+		resolved[i] = expanded
+	}
+	return resolved, nil
+}
+
+// Environ returns the process environment with env appended in sorted key
+// order, suitable for exec.Cmd.Env. It returns nil when env is empty so that
+// the command simply inherits the environment.
+func Environ(env map[string]string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := os.Environ()
+	for _, k := range keys {
+		result = append(result, k+"="+env[k])
+	}
+	return result
+}
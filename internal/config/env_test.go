@@ -0,0 +1,201 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("MDC_TEST_HOST", "db.local")
+
+	env := map[string]string{"PORT": "5432", "EMPTY": ""}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "from env map", in: "port=${PORT}", want: "port=5432"},
+		{name: "from process env", in: "${MDC_TEST_HOST}:${PORT}", want: "db.local:5432"},
+		{name: "unknown variable", in: "x${MDC_TEST_UNDEFINED}x", want: "xx"},
+		{name: "default for unknown", in: "${MDC_TEST_UNDEFINED:-fallback}", want: "fallback"},
+		{name: "default for empty", in: "${EMPTY:-fallback}", want: "fallback"},
+		{name: "plain dollar untouched", in: "pa$$word $PORT", want: "pa$$word $PORT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Interpolate(tt.in, env); got != tt.want {
+				t.Errorf("Interpolate(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	content := `# comment
+NODE_ENV=development
+export DB_HOST=localhost
+DB_PORT = 5432   # trailing comment
+DATABASE_URL="postgres://${DB_HOST}:${DB_PORT}/app"
+LITERAL='${DB_HOST}'
+MULTI="a\nb"
+
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{}
+	if err := loadEnvFile(env, path); err != nil {
+		t.Fatalf("loadEnvFile() error: %v", err)
+	}
+
+	want := map[string]string{
+		"NODE_ENV":     "development",
+		"DB_HOST":      "localhost",
+		"DB_PORT":      "5432",
+		"DATABASE_URL": "postgres://localhost:5432/app",
+		"LITERAL":      "${DB_HOST}",
+		"MULTI":        "a\nb",
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("env[%q] = %q, want %q", k, env[k], v)
+		}
+	}
+	if len(env) != len(want) {
+		t.Errorf("len(env) = %d, want %d: %v", len(env), len(want), env)
+	}
+
+	t.Run("invalid line", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad.env")
+		if err := os.WriteFile(bad, []byte("NOT_A_PAIR\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := loadEnvFile(map[string]string{}, bad)
+		if err == nil || !strings.Contains(err.Error(), "bad.env:1") {
+			t.Errorf("loadEnvFile() error = %v, want line reference", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		err := loadEnvFile(map[string]string{}, filepath.Join(dir, "missing.env"))
+		if err == nil || !strings.Contains(err.Error(), "env_file") {
+			t.Errorf("loadEnvFile() error = %v, want env_file error", err)
+		}
+	})
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeEnv := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg := &Config{
+		EnvFile: []string{writeEnv("global.env", "A=global-file\nB=global-file\nC=global-file\nD=global-file\nE=global-file\nF=global-file\n")},
+		Env:     map[string]string{"B": "global", "C": "global", "D": "global", "E": "global", "F": "global"},
+	}
+	project := Project{
+		Name:    "api",
+		EnvFile: []string{writeEnv("project.env", "C=project-file\nD=project-file\nE=project-file\nF=project-file\n")},
+		Env:     map[string]string{"D": "project", "E": "project", "F": "project"},
+	}
+	item := CommandItem{
+		Command: "npm run dev",
+		EnvFile: []string{writeEnv("command.env", "E=command-file\nF=command-file\n")},
+		Env:     map[string]string{"F": "command-${D}"},
+	}
+
+	base, err := cfg.ProjectEnv(project)
+	if err != nil {
+		t.Fatalf("ProjectEnv() error: %v", err)
+	}
+	env, err := CommandEnv(base, item)
+	if err != nil {
+		t.Fatalf("CommandEnv() error: %v", err)
+	}
+
+	want := map[string]string{
+		"A": "global-file",
+		"B": "global",
+		"C": "project-file",
+		"D": "project",
+		"E": "command-file",
+		"F": "command-project",
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("env[%q] = %q, want %q", k, env[k], v)
+		}
+	}
+	if base["F"] != "project" {
+		t.Errorf("CommandEnv() must not modify base, base[F] = %q", base["F"])
+	}
+}
+
+func TestLoadFromDirResolvesEnvFiles(t *testing.T) {
+	configDir := t.TempDir()
+	projectDir := t.TempDir()
+	yaml := `execution_mode: sequential
+env_file: [".env.global"]
+projects:
+  - name: app
+    path: ` + projectDir + `
+    env_file: [".env"]
+    commands:
+      up:
+        - command: "npm run dev"
+          env_file: ["/abs/.env.cmd"]
+          env:
+            NODE_ENV: development
+      restart:
+        - command: "docker compose restart"
+          env_file: [".env.restart"]
+`
+	if err := os.WriteFile(filepath.Join(configDir, "test.yml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromDir(configDir, "test")
+	if err != nil {
+		t.Fatalf("LoadFromDir() error: %v", err)
+	}
+	if got, want := cfg.EnvFile[0], filepath.Join(configDir, ".env.global"); got != want {
+		t.Errorf("EnvFile[0] = %q, want %q", got, want)
+	}
+	if got, want := cfg.Projects[0].EnvFile[0], filepath.Join(projectDir, ".env"); got != want {
+		t.Errorf("Projects[0].EnvFile[0] = %q, want %q", got, want)
+	}
+	up := cfg.Projects[0].Commands.Up[0]
+	if up.EnvFile[0] != "/abs/.env.cmd" {
+		t.Errorf("command EnvFile[0] = %q, want absolute path unchanged", up.EnvFile[0])
+	}
+	if up.Env["NODE_ENV"] != "development" {
+		t.Errorf("command Env = %v", up.Env)
+	}
+	restart := cfg.Projects[0].Commands.Restart[0]
+	if got, want := restart.EnvFile[0], filepath.Join(projectDir, ".env.restart"); got != want {
+		t.Errorf("restart command EnvFile[0] = %q, want %q", got, want)
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	if Environ(nil) != nil {
+		t.Error("Environ(nil) should return nil to inherit the environment")
+	}
+	got := Environ(map[string]string{"B": "2", "A": "1"})
+	if len(got) < 2 {
+		t.Fatalf("Environ() = %v", got)
+	}
+	if got[len(got)-2] != "A=1" || got[len(got)-1] != "B=2" {
+		t.Errorf("Environ() should append variables in sorted order, got %v", got[len(got)-2:])
+	}
+}
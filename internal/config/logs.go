@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults for proc log rotation and retention.
+const (
+	DefaultLogMaxSize  = 10 * 1024 * 1024
+	DefaultLogMaxFiles = 3
+	DefaultLogMaxAge   = 7 * 24 * time.Hour
+)
+
+// LogConfig controls rotation and retention of the logs written by
+// background commands.
+type LogConfig struct {
+	// MaxSize is the size at which a log is rotated.
+	MaxSize ByteSize `yaml:"max_size"`
+	// MaxFiles is the number of rotated segments kept per log.
+	MaxFiles int `yaml:"max_files"`
+	// MaxAge is how long rotated segments and logs of processes that are
+	// no longer tracked are kept.
+	MaxAge Duration `yaml:"max_age"`
+	// KeepStopped keeps the logs of processes stopped by "mdc down" until
+	// they are older than MaxAge, instead of removing them right away.
+	KeepStopped bool `yaml:"keep_stopped"`
+}
+
+// MaxSizeBytes returns MaxSize, or DefaultLogMaxSize when unset.
+func (l LogConfig) MaxSizeBytes() int64 {
+	if l.MaxSize <= 0 {
+		return DefaultLogMaxSize
+	}
+	return int64(l.MaxSize)
+}
+
+// MaxFileCount returns MaxFiles, or DefaultLogMaxFiles when unset.
+func (l LogConfig) MaxFileCount() int {
+	if l.MaxFiles <= 0 {
+		return DefaultLogMaxFiles
+	}
+	return l.MaxFiles
+}
+
+// MaxAgeDuration returns MaxAge, or DefaultLogMaxAge when unset.
+func (l LogConfig) MaxAgeDuration() time.Duration {
+	if l.MaxAge <= 0 {
+		return DefaultLogMaxAge
+	}
+	return time.Duration(l.MaxAge)
+}
+
+func (l LogConfig) validate() error {
+	if l.MaxSize < 0 {
+		return fmt.Errorf("logs: max_size must not be negative")
+	}
+	if l.MaxFiles < 0 {
+		return fmt.Errorf("logs: max_files must not be negative")
+	}
+	if l.MaxAge < 0 {
+		return fmt.Errorf("logs: max_age must not be negative")
+	}
+	return nil
+}
+
+// ByteSize is a size in bytes that is written in YAML either as a number
+// or with a unit (e.g. "512KB", "10MB", "1GiB").
+type ByteSize int64
+
+var byteUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1 << 10,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1 << 20,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1 << 30,
+	"GIB": 1 << 30,
+}
+
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	size, err := ParseByteSize(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	*b = size
+	return nil
+}
+
+// ParseByteSize parses sizes such as "1024", "512KB" or "10MB". Units are
+// binary (1KB = 1024 bytes) and case-insensitive.
+func ParseByteSize(s string) (ByteSize, error) {
+	trimmed := strings.ToUpper(strings.TrimSpace(s))
+	i := strings.IndexFunc(trimmed, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	num, unit := trimmed, ""
+	if i >= 0 {
+		num, unit = trimmed[:i], strings.TrimSpace(trimmed[i:])
+	}
+	mult, ok := byteUnits[unit]
+	if !ok || num == "" {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return ByteSize(n * float64(mult)), nil
+}
+
+func (b ByteSize) String() string {
+	switch {
+	case b >= 1<<30 && b%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", b>>30)
+	case b >= 1<<20 && b%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", b>>20)
+	case b >= 1<<10 && b%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", b>>10)
+	}
+	return fmt.Sprintf("%dB", int64(b))
+}
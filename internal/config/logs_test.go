@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ByteSize
+		wantErr bool
+	}{
+		{in: "1024", want: 1024},
+		{in: "512KB", want: 512 << 10},
+		{in: "10mb", want: 10 << 20},
+		{in: "1.5M", want: 3 << 19},
+		{in: "2GiB", want: 2 << 30},
+		{in: " 100 B ", want: 100},
+		{in: "10TB", wantErr: true},
+		{in: "MB", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseByteSize(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseByteSize(%q) = %d, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseByteSize(%q) error: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseByteSize(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	tests := map[ByteSize]string{
+		10 << 20:  "10MB",
+		512 << 10: "512KB",
+		1 << 30:   "1GB",
+		1500:      "1500B",
+	}
+	for in, want := range tests {
+		if got := in.String(); got != want {
+			t.Errorf("ByteSize(%d).String() = %q, want %q", int64(in), got, want)
+		}
+	}
+}
+
+func TestLogConfigDefaults(t *testing.T) {
+	var lc LogConfig
+	if lc.MaxSizeBytes() != DefaultLogMaxSize {
+		t.Errorf("MaxSizeBytes() = %d, want %d", lc.MaxSizeBytes(), DefaultLogMaxSize)
+	}
+	if lc.MaxFileCount() != DefaultLogMaxFiles {
+		t.Errorf("MaxFileCount() = %d, want %d", lc.MaxFileCount(), DefaultLogMaxFiles)
+	}
+	if lc.MaxAgeDuration() != DefaultLogMaxAge {
+		t.Errorf("MaxAgeDuration() = %s, want %s", lc.MaxAgeDuration(), DefaultLogMaxAge)
+	}
+}
+
+func TestLogConfigUnmarshalYAML(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `execution_mode: sequential
+logs:
+  max_size: "5MB"
+  max_files: 2
+  max_age: "48h"
+projects:
+  - name: app
+    path: /tmp
+`
+	if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFromDir(dir, "test")
+	if err != nil {
+		t.Fatalf("LoadFromDir() error: %v", err)
+	}
+	if cfg.Logs.MaxSizeBytes() != 5<<20 {
+		t.Errorf("MaxSizeBytes() = %d, want %d", cfg.Logs.MaxSizeBytes(), 5<<20)
+	}
+	if cfg.Logs.MaxFileCount() != 2 {
+		t.Errorf("MaxFileCount() = %d, want 2", cfg.Logs.MaxFileCount())
+	}
+	if cfg.Logs.MaxAgeDuration() != 48*time.Hour {
+		t.Errorf("MaxAgeDuration() = %s, want 48h", cfg.Logs.MaxAgeDuration())
+	}
+
+	bad := strings.Replace(yaml, `"5MB"`, `"lots"`, 1)
+	if err := os.WriteFile(filepath.Join(dir, "bad.yml"), []byte(bad), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromDir(dir, "bad"); err == nil || !strings.Contains(err.Error(), `invalid size "lots"`) {
+		t.Errorf("LoadFromDir() error = %v, want invalid size", err)
+	}
+
+	negative := strings.Replace(yaml, "max_files: 2", "max_files: -1", 1)
+	if err := os.WriteFile(filepath.Join(dir, "neg.yml"), []byte(negative), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromDir(dir, "neg"); err == nil || !strings.Contains(err.Error(), "max_files must not be negative") {
+		t.Errorf("LoadFromDir() error = %v, want negative max_files error", err)
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Selector narrows a config down to a subset of its projects.
+type Selector struct {
+	// Only keeps the named projects. Empty means all projects.
+	Only []string
+	// Except removes the named projects.
+	Except []string
+	// Tags keeps projects that have at least one of the tags.
+	Tags []string
+}
+
+// IsEmpty reports whether the selector keeps every project.
+func (s Selector) IsEmpty() bool {
+	return len(s.Only) == 0 && len(s.Except) == 0 && len(s.Tags) == 0
+}
+
+// Select returns a copy of the config containing only the projects matched
+// by sel. Dependencies on projects that are filtered out are dropped, so the
+// selected projects do not wait for them. Unknown project names and an empty
+// result are errors.
+func (c *Config) Select(sel Selector) (*Config, error) {
+	if sel.IsEmpty() {
+		return c, nil
+	}
+
+	known := make(map[string]bool, len(c.Projects))
+	for _, p := range c.Projects {
+		known[p.Name] = true
+	}
+	for _, names := range [][]string{sel.Only, sel.Except} {
+		for _, name := range names {
+			if !known[name] {
+				return nil, fmt.Errorf("unknown project %q", name)
+			}
+		}
+	}
+
+	only := toSet(sel.Only)
+	except := toSet(sel.Except)
+	tags := toSet(sel.Tags)
+
+	selected := make(map[string]bool)
+	for _, p := range c.Projects {
+		if len(only) > 0 && !only[p.Name] {
+			continue
+		}
+		if except[p.Name] {
+			continue
+		}
+		if len(tags) > 0 && !p.HasAnyTag(tags) {
+			continue
+		}
+		selected[p.Name] = true
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no projects match the selection (%s)", sel)
+	}
+
+	filtered := *c
+	filtered.Projects = make([]Project, 0, len(selected))
+	for _, p := range c.Projects {
+		if !selected[p.Name] {
+			continue
+		}
+		var deps []string
+		for _, dep := range p.DependsOn {
+			if selected[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		p.DependsOn = deps
+		filtered.Projects = append(filtered.Projects, p)
+	}
+	return &filtered, nil
+}
+
+// ProjectNames returns the names of all projects in definition order.
+func (c *Config) ProjectNames() []string {
+	names := make([]string, len(c.Projects))
+	for i, p := range c.Projects {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// HasAnyTag reports whether the project has at least one of tags.
+func (p Project) HasAnyTag(tags map[string]bool) bool {
+	for _, t := range p.Tags {
+		if tags[t] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Selector) String() string {
+	var parts []string
+	if len(s.Only) > 0 {
+		parts = append(parts, "only: "+strings.Join(s.Only, ","))
+	}
+	if len(s.Except) > 0 {
+		parts = append(parts, "except: "+strings.Join(s.Except, ","))
+	}
+	if len(s.Tags) > 0 {
+		parts = append(parts, "tags: "+strings.Join(s.Tags, ","))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// ActionNames returns the actions defined by at least one project: up and
+// down first, then named actions in name order.
+func (c *Config) ActionNames() []string {
+	var names []string
+	for _, action := range []string{ActionUp, ActionDown} {
+		if len(c.ProjectsWithAction(action)) > 0 {
+			names = append(names, action)
+		}
+	}
+	named := make(map[string]bool)
+	for _, p := range c.Projects {
+		for name, items := range p.Commands.Actions {
+			if len(items) > 0 {
+				named[name] = true
+			}
+		}
+	}
+	return append(names, slices.Sorted(maps.Keys(named))...)
+}
+
+// ProjectsWithAction returns the names of the projects that define commands
+// for the action, in definition order.
+func (c *Config) ProjectsWithAction(action string) []string {
+	var names []string
+	for _, p := range c.Projects {
+		if len(p.Commands.ForAction(action)) > 0 {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
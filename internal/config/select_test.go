@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func selectTestConfig() *Config {
+	return &Config{
+		ExecutionMode: "parallel",
+		Projects: []Project{
+			{Name: "db", Tags: []string{"backend"}},
+			{Name: "api", Tags: []string{"backend"}, DependsOn: []string{"db"}},
+			{Name: "web", Tags: []string{"frontend"}, DependsOn: []string{"api"}},
+			{Name: "docs"},
+		},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name string
+		sel  Selector
+		want []string
+	}{
+		{name: "empty selector", sel: Selector{}, want: []string{"db", "api", "web", "docs"}},
+		{name: "only", sel: Selector{Only: []string{"web", "db"}}, want: []string{"db", "web"}},
+		{name: "except", sel: Selector{Except: []string{"docs"}}, want: []string{"db", "api", "web"}},
+		{name: "tag", sel: Selector{Tags: []string{"backend"}}, want: []string{"db", "api"}},
+		{name: "multiple tags", sel: Selector{Tags: []string{"backend", "frontend"}}, want: []string{"db", "api", "web"}},
+		{name: "tag and except", sel: Selector{Tags: []string{"backend"}, Except: []string{"db"}}, want: []string{"api"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectTestConfig().Select(tt.sel)
+			if err != nil {
+				t.Fatalf("Select() error: %v", err)
+			}
+			if names := got.ProjectNames(); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Select() projects = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		sel     Selector
+		wantErr string
+	}{
+		{name: "unknown only", sel: Selector{Only: []string{"nope"}}, wantErr: `unknown project "nope"`},
+		{name: "unknown except", sel: Selector{Except: []string{"nope"}}, wantErr: `unknown project "nope"`},
+		{name: "no match", sel: Selector{Tags: []string{"mobile"}}, wantErr: "no projects match the selection (tags: mobile)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := selectTestConfig().Select(tt.sel)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Select() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelectPrunesDependencies(t *testing.T) {
+	cfg := selectTestConfig()
+	got, err := cfg.Select(Selector{Only: []string{"api", "web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps := got.Projects[0].DependsOn; len(deps) != 0 {
+		t.Errorf("api.DependsOn = %v, want none", deps)
+	}
+	if deps := got.Projects[1].DependsOn; !reflect.DeepEqual(deps, []string{"api"}) {
+		t.Errorf("web.DependsOn = %v, want [api]", deps)
+	}
+	if deps := cfg.Projects[1].DependsOn; !reflect.DeepEqual(deps, []string{"db"}) {
+		t.Errorf("original config modified: api.DependsOn = %v", deps)
+	}
+}
+
+func TestActions(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `execution_mode: sequential
+projects:
+  - name: api
+    path: /tmp
+    commands:
+      up:
+        - "docker compose up -d"
+      down:
+        - "docker compose down"
+      actions:
+        migrate:
+          - "make migrate"
+        seed:
+          - command: "make seed"
+  - name: web
+    path: /tmp
+    commands:
+      up:
+        - "npm run dev"
+      actions:
+        build:
+          - "npm run build"
+        seed: []
+`
+	if err := os.WriteFile(filepath.Join(dir, "test.yml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFromDir(dir, "test")
+	if err != nil {
+		t.Fatalf("LoadFromDir() error: %v", err)
+	}
+
+	if got, want := cfg.ActionNames(), []string{"up", "down", "build", "migrate", "seed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ActionNames() = %v, want %v", got, want)
+	}
+	for action, want := range map[string][]string{
+		"up":      {"api", "web"},
+		"down":    {"api"},
+		"seed":    {"api"},
+		"build":   {"web"},
+		"missing": nil,
+	} {
+		if got := cfg.ProjectsWithAction(action); !reflect.DeepEqual(got, want) {
+			t.Errorf("ProjectsWithAction(%q) = %v, want %v", action, got, want)
+		}
+	}
+	if got := cfg.Projects[0].Commands.ForAction("migrate"); len(got) != 1 || got[0].Command != "make migrate" {
+		t.Errorf("ForAction(migrate) = %+v, want make migrate", got)
+	}
+}
@@ -0,0 +1,368 @@
+// Package dashboard implements the full-screen "mdc dashboard" view.
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+	"mdc/internal/runner"
+
+	"golang.org/x/term"
+)
+
+const (
+	logTailLines = 200
+	// keyPollInterval is how often the key reader checks whether it has
+	// been stopped while no key is pressed.
+	keyPollInterval = 50 * time.Millisecond
+
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+// Options configures a dashboard session.
+type Options struct {
+	ConfigName string
+	Config     *config.Config
+	// Interval between refreshes of the process list, the log pane and
+	// (when the previous call has finished) the container list.
+	Interval time.Duration
+
+	// Attach, Up and Down run with the terminal restored to normal mode;
+	// their output is shown as is.
+	Attach func(project string, entry pidfile.Entry) error
+	Up     func(project string) error
+	Down   func(project string) error
+	// Lock takes the config's run lock for an action that stops or starts
+	// processes, and returns the function releasing it. It fails when
+	// another mdc command holds the lock. Nil means no locking.
+	Lock func() (release func(), err error)
+}
+
+type psResult struct {
+	results []runner.ProjectContainers
+	at      time.Time
+}
+
+type actionResult struct {
+	message string
+	err     error
+}
+
+// dashboard is the running session: the model plus the terminal state.
+type dashboard struct {
+	opts Options
+	m    *model
+	in   *os.File
+	fd   int
+	raw  *term.State
+	// keys receives the input read by the key reader; nil when stdin is
+	// closed.
+	keys     chan []byte
+	stopKeys func()
+	ps       chan psResult
+	done     chan actionResult
+	frame    strings.Builder
+}
+
+// Run takes over the terminal until the user quits.
+func Run(opts Options) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return errors.New("dashboard requires an interactive terminal")
+	}
+
+	d := &dashboard{
+		opts: opts,
+		m:    newModel(opts.ConfigName, opts.Config.ProjectNames()),
+		in:   os.Stdin,
+		fd:   fd,
+		keys: make(chan []byte),
+		ps:   make(chan psResult, 1),
+		done: make(chan actionResult, 1),
+	}
+
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	// Background actions log through the logger; keep that off the screen.
+	logger.SetOutput(io.Discard)
+	defer logger.SetOutput(os.Stdout)
+
+	d.startKeys()
+	defer func() { d.stopKeys() }()
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	d.refresh()
+	for {
+		d.draw()
+		select {
+		case b := <-d.keys:
+			if b == nil {
+				return nil
+			}
+			for _, k := range parseKeys(b) {
+				if quit := d.dispatch(d.m.handleKey(k)); quit {
+					d.finish()
+					return nil
+				}
+			}
+		case r := <-d.ps:
+			d.m.setContainers(r.results, r.at)
+		case r := <-d.done:
+			d.m.busy = ""
+			d.m.status = r.message
+			if r.err != nil {
+				d.m.status = "Error: " + r.err.Error()
+			}
+			d.refresh()
+		case <-ticker.C:
+			d.refresh()
+		}
+	}
+}
+
+func (d *dashboard) enter() error {
+	raw, err := term.MakeRaw(d.fd)
+	if err != nil {
+		return fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	d.raw = raw
+	_, _ = os.Stdout.WriteString(enterScreen)
+	return nil
+}
+
+func (d *dashboard) leave() {
+	_, _ = os.Stdout.WriteString(leaveScreen)
+	if d.raw != nil {
+		_ = term.Restore(d.fd, d.raw)
+		d.raw = nil
+	}
+}
+
+// startKeys starts the key reader, which sends the input to d.keys until
+// d.stopKeys is called. It only reads once input is available, so that it
+// can be stopped without taking a key away from whoever reads stdin next.
+func (d *dashboard) startKeys() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		d.readKeys(stop)
+	}()
+	d.stopKeys = func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+func (d *dashboard) readKeys(stop <-chan struct{}) {
+	buf := make([]byte, 256)
+	for {
+		ready, err := waitForInput(d.in, keyPollInterval)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if err == nil && !ready {
+			continue
+		}
+		var b []byte
+		if err == nil {
+			var n int
+			if n, err = d.in.Read(buf); err == nil {
+				b = make([]byte, n)
+				copy(b, buf[:n])
+			}
+		}
+		select {
+		case d.keys <- b:
+		case <-stop:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// refresh reloads the tracked processes and the log pane, and starts a
+// container refresh unless one is still running.
+func (d *dashboard) refresh() {
+	processes, err := pidfile.LoadAll(d.opts.ConfigName)
+	if err != nil {
+		d.m.status = "Error: " + err.Error()
+	}
+	d.m.setProcesses(processes, pidfile.Entry.Status)
+	d.loadLog()
+
+	if d.m.psLoading {
+		return
+	}
+	d.m.psLoading = true
+	go func() {
+		results := runner.CollectPS(d.opts.Config)
+		d.ps <- psResult{results: results, at: time.Now()}
+	}()
+}
+
+func (d *dashboard) loadLog() {
+	d.m.logLines = nil
+	r, ok := d.m.selected()
+	if !ok || r.kind != rowProcess {
+		return
+	}
+	path, err := pidfile.ProcLogFilePath(d.opts.ConfigName, r.project, r.entry.PID)
+	if err != nil {
+		return
+	}
+	d.m.logLines = sanitizeLog(runner.TailFile(path, logTailLines))
+}
+
+// dispatch carries out an action and reports whether to quit.
+func (d *dashboard) dispatch(a action) bool {
+	r := a.row
+	switch a.kind {
+	case actionQuit:
+		return true
+	case actionNone:
+		// Selection or filter changes: the log pane follows the cursor.
+		d.loadLog()
+	case actionRefresh:
+		d.refresh()
+	case actionStop:
+		d.background(fmt.Sprintf("Stopping PID %d", r.entry.PID), func() (string, error) {
+			release, err := d.lock()
+			if err != nil {
+				return "", err
+			}
+			defer release()
+			runner.StopProcess(d.opts.ConfigName, r.project, r.entry)
+			return fmt.Sprintf("Stopped %q (PID %d)", r.entry.Command, r.entry.PID), nil
+		})
+	case actionRestart:
+		d.background(fmt.Sprintf("Restarting PID %d", r.entry.PID), func() (string, error) {
+			release, err := d.lock()
+			if err != nil {
+				return "", err
+			}
+			defer release()
+			next, err := runner.RestartProcess(d.opts.ConfigName, r.project, r.entry)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Restarted %q (PID %d -> %d)", r.entry.Command, r.entry.PID, next.PID), nil
+		})
+	case actionAttach:
+		d.suspend(func() error { return d.opts.Attach(r.project, r.entry) })
+	case actionUp:
+		d.suspend(func() error { return d.opts.Up(r.project) })
+	case actionDown:
+		d.suspend(func() error { return d.opts.Down(r.project) })
+	}
+	return false
+}
+
+// lock takes the run lock through Options.Lock, if set.
+func (d *dashboard) lock() (func(), error) {
+	if d.opts.Lock == nil {
+		return func() {}, nil
+	}
+	return d.opts.Lock()
+}
+
+// finish waits for a running background action so that quitting never
+// leaves a half-restarted process behind.
+func (d *dashboard) finish() {
+	if d.m.busy == "" {
+		return
+	}
+	d.m.status = ""
+	d.draw()
+	<-d.done
+}
+
+// background runs fn without blocking the event loop. Only one background
+// action runs at a time.
+func (d *dashboard) background(busy string, fn func() (string, error)) {
+	d.m.busy = busy
+	go func() {
+		msg, err := fn()
+		d.done <- actionResult{message: msg, err: err}
+	}()
+}
+
+// suspend hands the terminal back to fn and resumes the dashboard after
+// the user presses Enter. The key reader is stopped meanwhile, so that fn
+// gets all of the input. Ctrl-C is delivered to fn's child processes but
+// does not terminate mdc.
+func (d *dashboard) suspend(fn func() error) {
+	d.stopKeys()
+	d.leave()
+	logger.SetOutput(os.Stdout)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	err := fn()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	fmt.Print("\nPress Enter to return to the dashboard...")
+	d.waitForEnter()
+	signal.Stop(sigCh)
+
+	logger.SetOutput(io.Discard)
+	d.m.status = ""
+	if err := d.enter(); err != nil {
+		d.m.status = "Error: " + err.Error()
+	}
+	d.startKeys()
+	d.refresh()
+}
+
+// waitForEnter consumes input until a newline arrives or stdin is closed.
+// The key reader is stopped, so stdin is read directly.
+func (d *dashboard) waitForEnter() {
+	buf := make([]byte, 256)
+	for {
+		n, err := d.in.Read(buf)
+		if strings.ContainsAny(string(buf[:n]), "\r\n") || err != nil {
+			return
+		}
+	}
+}
+
+// draw repaints the whole screen in a single write to avoid flicker.
+func (d *dashboard) draw() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	d.m.clampCursor()
+
+	d.frame.Reset()
+	d.frame.WriteString("\x1b[H")
+	lines := d.m.render(width, height)
+	for i, line := range lines {
+		d.frame.WriteString(line)
+		d.frame.WriteString("\x1b[0m\x1b[K")
+		if i < len(lines)-1 {
+			d.frame.WriteString("\r\n")
+		}
+	}
+	d.frame.WriteString("\x1b[J")
+	_, _ = os.Stdout.WriteString(d.frame.String())
+}
@@ -0,0 +1,62 @@
+package dashboard
+
+import "unicode/utf8"
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyCtrlC
+)
+
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+// parseKeys decodes the bytes of a single read from a raw-mode terminal.
+// Unknown escape sequences are dropped.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch {
+		case b[0] == 0x1b && len(b) >= 3 && (b[1] == '[' || b[1] == 'O'):
+			switch b[2] {
+			case 'A':
+				keys = append(keys, key{kind: keyUp})
+			case 'B':
+				keys = append(keys, key{kind: keyDown})
+			}
+			// Skip the rest of longer sequences such as "\x1b[5~".
+			n := 3
+			for n < len(b) && (b[n-1] >= '0' && b[n-1] <= '9' || b[n-1] == ';') {
+				n++
+			}
+			b = b[n:]
+		case b[0] == 0x1b:
+			keys = append(keys, key{kind: keyEscape})
+			b = b[1:]
+		case b[0] == '\r' || b[0] == '\n':
+			keys = append(keys, key{kind: keyEnter})
+			b = b[1:]
+		case b[0] == 0x7f || b[0] == 0x08:
+			keys = append(keys, key{kind: keyBackspace})
+			b = b[1:]
+		case b[0] == 0x03:
+			keys = append(keys, key{kind: keyCtrlC})
+			b = b[1:]
+		case b[0] < 0x20:
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, key{kind: keyRune, r: r})
+			b = b[size:]
+		}
+	}
+	return keys
+}
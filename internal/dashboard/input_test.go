@@ -0,0 +1,76 @@
+package dashboard
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []key
+	}{
+		{name: "runes", in: "jkä", want: []key{{kind: keyRune, r: 'j'}, {kind: keyRune, r: 'k'}, {kind: keyRune, r: 'ä'}}},
+		{name: "arrows", in: "\x1b[A\x1b[B\x1bOA", want: []key{{kind: keyUp}, {kind: keyDown}, {kind: keyUp}}},
+		{name: "escape", in: "\x1b", want: []key{{kind: keyEscape}}},
+		{name: "enter and backspace", in: "\r\x7f", want: []key{{kind: keyEnter}, {kind: keyBackspace}}},
+		{name: "ctrl-c", in: "\x03", want: []key{{kind: keyCtrlC}}},
+		{name: "unknown sequences dropped", in: "\x1b[5~\x1b[1;5Cq", want: []key{{kind: keyRune, r: 'q'}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseKeys([]byte(tt.in))
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseKeys(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseKeys(%q)[%d] = %v, want %v", tt.in, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestKeyReaderStopsWithoutConsumingInput(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	d := &dashboard{in: r, keys: make(chan []byte)}
+
+	receive := func() []byte {
+		t.Helper()
+		select {
+		case b := <-d.keys:
+			return b
+		case <-time.After(2 * time.Second):
+			t.Fatal("no input from the key reader")
+			return nil
+		}
+	}
+
+	d.startKeys()
+	_, _ = w.WriteString("a")
+	if got := receive(); string(got) != "a" {
+		t.Errorf("key reader sent %q, want %q", got, "a")
+	}
+
+	// While stopped, e.g. during an attach, input is left for others.
+	d.stopKeys()
+	_, _ = w.WriteString("b")
+	buf := make([]byte, 8)
+	if n, err := r.Read(buf); err != nil || string(buf[:n]) != "b" {
+		t.Errorf("Read() after stopping = %q, %v; want %q", buf[:n], err, "b")
+	}
+
+	d.startKeys()
+	defer d.stopKeys()
+	_ = w.Close()
+	if got := receive(); got != nil {
+		t.Errorf("key reader sent %q at end of input, want nil", got)
+	}
+}
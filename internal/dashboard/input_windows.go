@@ -0,0 +1,21 @@
+//go:build windows
+
+package dashboard
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// waitForInput reports whether f has input to read within timeout. A
+// console handle is signaled by any input event, so a read may still wait
+// for a key.
+func waitForInput(f *os.File, timeout time.Duration) (bool, error) {
+	event, err := windows.WaitForSingleObject(windows.Handle(f.Fd()), uint32(timeout.Milliseconds()))
+	if err != nil {
+		return false, err
+	}
+	return event == windows.WAIT_OBJECT_0, nil
+}
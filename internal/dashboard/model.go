@@ -0,0 +1,296 @@
+package dashboard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mdc/internal/pidfile"
+	"mdc/internal/runner"
+)
+
+type rowKind int
+
+const (
+	rowProject rowKind = iota
+	rowContainer
+	rowProcess
+)
+
+// row is a single selectable line of the dashboard. Every project gets a
+// rowProject line so that "up" can be run even when nothing is running.
+type row struct {
+	kind      rowKind
+	project   string
+	container runner.ContainerInfo
+	entry     pidfile.Entry
+	status    pidfile.Status
+	running   bool
+}
+
+// model holds everything the dashboard shows. It is only touched by the
+// event loop, so it needs no locking.
+type model struct {
+	configName string
+	projects   []string
+
+	containers map[string][]runner.ContainerInfo
+	psErrs     map[string]error
+	psLoading  bool
+	psUpdated  time.Time
+
+	processes map[string][]pidfile.Entry
+	statuses  map[int]pidfile.Status
+	// exits holds the recorded exit status of processes that are not
+	// running.
+	exits map[int]pidfile.ExitStatus
+
+	cursor    int
+	filter    string
+	filtering bool
+	status    string
+	busy      string
+	logLines  []string
+}
+
+func newModel(configName string, projects []string) *model {
+	return &model{
+		configName: configName,
+		projects:   projects,
+		containers: map[string][]runner.ContainerInfo{},
+		psErrs:     map[string]error{},
+		processes:  map[string][]pidfile.Entry{},
+		statuses:   map[int]pidfile.Status{},
+		exits:      map[int]pidfile.ExitStatus{},
+	}
+}
+
+// setContainers stores the result of a "docker compose ps" refresh.
+func (m *model) setContainers(results []runner.ProjectContainers, at time.Time) {
+	m.containers = map[string][]runner.ContainerInfo{}
+	m.psErrs = map[string]error{}
+	for _, r := range results {
+		if r.Err != nil {
+			m.psErrs[r.ProjectName] = r.Err
+			continue
+		}
+		m.containers[r.ProjectName] = r.Containers
+	}
+	m.psLoading = false
+	m.psUpdated = at
+}
+
+// setProcesses stores the tracked background processes and their status.
+func (m *model) setProcesses(processes map[string][]pidfile.Entry, status func(pidfile.Entry) pidfile.Status) {
+	m.processes = processes
+	m.statuses = map[int]pidfile.Status{}
+	m.exits = map[int]pidfile.ExitStatus{}
+	for project, entries := range processes {
+		for _, e := range entries {
+			s := status(e)
+			m.statuses[e.PID] = s
+			if s != pidfile.StatusRunning {
+				if exit, ok := e.LastExit(m.configName, project); ok {
+					m.exits[e.PID] = exit
+				}
+			}
+		}
+	}
+	m.clampCursor()
+}
+
+// projectOrder returns the config's projects followed by projects that only
+// exist in the PID files (e.g. removed from the config since "up").
+func (m *model) projectOrder() []string {
+	seen := make(map[string]bool, len(m.projects))
+	order := append([]string(nil), m.projects...)
+	for _, p := range m.projects {
+		seen[p] = true
+	}
+	var extra []string
+	for p := range m.processes {
+		if !seen[p] {
+			extra = append(extra, p)
+		}
+	}
+	sort.Strings(extra)
+	return append(order, extra...)
+}
+
+// rows returns the visible rows, grouped by project, after filtering.
+func (m *model) rows() []row {
+	var rows []row
+	for _, project := range m.projectOrder() {
+		group := []row{{kind: rowProject, project: project}}
+		for _, c := range m.containers[project] {
+			group = append(group, row{kind: rowContainer, project: project, container: c, running: c.State == "running"})
+		}
+		for _, e := range m.processes[project] {
+			s := m.statuses[e.PID]
+			group = append(group, row{kind: rowProcess, project: project, entry: e, status: s, running: s == pidfile.StatusRunning})
+		}
+		if m.filter == "" {
+			rows = append(rows, group...)
+			continue
+		}
+		if m.matches(group[0]) {
+			rows = append(rows, group...)
+			continue
+		}
+		var matched []row
+		for _, r := range group[1:] {
+			if m.matches(r) {
+				matched = append(matched, r)
+			}
+		}
+		if len(matched) > 0 {
+			rows = append(rows, group[0])
+			rows = append(rows, matched...)
+		}
+	}
+	return rows
+}
+
+func (m *model) matches(r row) bool {
+	needle := strings.ToLower(m.filter)
+	var haystack string
+	switch r.kind {
+	case rowProject:
+		haystack = r.project
+	case rowContainer:
+		haystack = r.container.Name
+	case rowProcess:
+		haystack = fmt.Sprintf("%s %d", r.entry.Command, r.entry.PID)
+	}
+	return strings.Contains(strings.ToLower(haystack), needle)
+}
+
+func (m *model) selected() (row, bool) {
+	rows := m.rows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return row{}, false
+	}
+	return rows[m.cursor], true
+}
+
+func (m *model) move(delta int) {
+	m.cursor += delta
+	m.clampCursor()
+}
+
+func (m *model) clampCursor() {
+	n := len(m.rows())
+	if m.cursor >= n {
+		m.cursor = n - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+type actionKind int
+
+const (
+	actionNone actionKind = iota
+	actionQuit
+	actionStop
+	actionRestart
+	actionAttach
+	actionUp
+	actionDown
+	actionRefresh
+)
+
+// action is what the event loop should do in response to a key.
+type action struct {
+	kind actionKind
+	row  row
+}
+
+// handleKey updates the model for a key press and returns the action to
+// carry out, if any.
+func (m *model) handleKey(k key) action {
+	if m.filtering {
+		switch k.kind {
+		case keyEnter:
+			m.filtering = false
+		case keyEscape:
+			m.filtering = false
+			m.filter = ""
+		case keyBackspace:
+			if r := []rune(m.filter); len(r) > 0 {
+				m.filter = string(r[:len(r)-1])
+			}
+		case keyRune:
+			m.filter += string(k.r)
+		case keyCtrlC:
+			return action{kind: actionQuit}
+		}
+		m.clampCursor()
+		return action{}
+	}
+
+	switch k.kind {
+	case keyCtrlC:
+		return action{kind: actionQuit}
+	case keyUp:
+		m.move(-1)
+	case keyDown:
+		m.move(1)
+	case keyEnter:
+		return m.processAction(actionAttach)
+	case keyEscape:
+		m.filter = ""
+		m.clampCursor()
+	case keyRune:
+		switch k.r {
+		case 'q':
+			return action{kind: actionQuit}
+		case 'k':
+			m.move(-1)
+		case 'j':
+			m.move(1)
+		case '/':
+			m.filtering = true
+		case 's':
+			return m.processAction(actionStop)
+		case 'r':
+			return m.processAction(actionRestart)
+		case 'a':
+			return m.processAction(actionAttach)
+		case 'u':
+			return m.projectAction(actionUp)
+		case 'd':
+			return m.projectAction(actionDown)
+		case 'R':
+			return action{kind: actionRefresh}
+		}
+	}
+	return action{}
+}
+
+func (m *model) processAction(kind actionKind) action {
+	r, ok := m.selected()
+	if !ok || r.kind != rowProcess {
+		m.status = "Select a background process first"
+		return action{}
+	}
+	if m.busy != "" {
+		m.status = "Busy: " + m.busy
+		return action{}
+	}
+	return action{kind: kind, row: r}
+}
+
+func (m *model) projectAction(kind actionKind) action {
+	r, ok := m.selected()
+	if !ok {
+		return action{}
+	}
+	if m.busy != "" {
+		m.status = "Busy: " + m.busy
+		return action{}
+	}
+	return action{kind: kind, row: r}
+}
@@ -0,0 +1,156 @@
+package dashboard
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"mdc/internal/pidfile"
+	"mdc/internal/runner"
+)
+
+func testModel() *model {
+	m := newModel("demo", []string{"web", "api", "db"})
+	m.setContainers([]runner.ProjectContainers{
+		{ProjectName: "api", Containers: []runner.ContainerInfo{{Name: "api-app-1", State: "running", Status: "Up 2 minutes"}}},
+		{ProjectName: "db", Err: errors.New("docker compose ps failed")},
+	}, time.Now())
+	m.setProcesses(map[string][]pidfile.Entry{
+		"web":    {{PID: 100, Command: "npm run dev"}, {PID: 101, Command: "npm run watch"}},
+		"legacy": {{PID: 200, Command: "old worker"}},
+	}, func(e pidfile.Entry) pidfile.Status {
+		if e.PID == 100 {
+			return pidfile.StatusRunning
+		}
+		return pidfile.StatusDead
+	})
+	return m
+}
+
+func rowSummary(rows []row) []string {
+	var out []string
+	for _, r := range rows {
+		switch r.kind {
+		case rowProject:
+			out = append(out, r.project)
+		case rowContainer:
+			out = append(out, r.project+"/"+r.container.Name)
+		case rowProcess:
+			out = append(out, r.project+"/"+r.entry.Command)
+		}
+	}
+	return out
+}
+
+func TestModelRows(t *testing.T) {
+	m := testModel()
+	got := rowSummary(m.rows())
+	want := []string{
+		"web", "web/npm run dev", "web/npm run watch",
+		"api", "api/api-app-1",
+		"db",
+		"legacy", "legacy/old worker",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("rows[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	rows := m.rows()
+	if !rows[1].running || rows[2].running {
+		t.Errorf("running status = %v/%v, want true/false", rows[1].running, rows[2].running)
+	}
+}
+
+func TestModelFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{filter: "watch", want: []string{"web", "web/npm run watch"}},
+		{filter: "API", want: []string{"api", "api/api-app-1"}},
+		{filter: "101", want: []string{"web", "web/npm run watch"}},
+		{filter: "nothing", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			m := testModel()
+			m.filter = tt.filter
+			got := rowSummary(m.rows())
+			if len(got) != len(tt.want) {
+				t.Fatalf("rows = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("rows[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestModelHandleKey(t *testing.T) {
+	m := testModel()
+
+	if a := m.handleKey(key{kind: keyRune, r: 's'}); a.kind != actionNone {
+		t.Errorf("stop on a project row = %v, want no action", a.kind)
+	}
+	if m.status == "" {
+		t.Error("stop on a project row should set a status message")
+	}
+
+	m.handleKey(key{kind: keyDown})
+	a := m.handleKey(key{kind: keyRune, r: 's'})
+	if a.kind != actionStop || a.row.entry.PID != 100 {
+		t.Errorf("stop = %+v, want stop of PID 100", a)
+	}
+	if a := m.handleKey(key{kind: keyRune, r: 'u'}); a.kind != actionUp || a.row.project != "web" {
+		t.Errorf("up = %+v, want up of web", a)
+	}
+
+	m.busy = "Stopping PID 100"
+	if a := m.handleKey(key{kind: keyRune, r: 'r'}); a.kind != actionNone {
+		t.Errorf("restart while busy = %v, want no action", a.kind)
+	}
+	m.busy = ""
+
+	for i := 0; i < 20; i++ {
+		m.handleKey(key{kind: keyRune, r: 'j'})
+	}
+	if r, _ := m.selected(); r.entry.PID != 200 {
+		t.Errorf("cursor should stop at the last row, got %+v", r)
+	}
+
+	for _, r := range "/api" {
+		m.handleKey(key{kind: keyRune, r: r})
+	}
+	if !m.filtering || m.filter != "api" {
+		t.Errorf("filter = %q (filtering %v), want %q", m.filter, m.filtering, "api")
+	}
+	if a := m.handleKey(key{kind: keyRune, r: 'q'}); a.kind != actionNone || m.filter != "apiq" {
+		t.Errorf("q while filtering should be typed, got action %v filter %q", a.kind, m.filter)
+	}
+	m.handleKey(key{kind: keyBackspace})
+	m.handleKey(key{kind: keyEnter})
+	if m.filtering || m.filter != "api" {
+		t.Errorf("after Enter: filter = %q (filtering %v)", m.filter, m.filtering)
+	}
+	if r, _ := m.selected(); r.project != "api" {
+		t.Errorf("cursor should be clamped into filtered rows, got %+v", r)
+	}
+	m.handleKey(key{kind: keyEscape})
+	if m.filter != "" {
+		t.Errorf("Esc should clear the filter, got %q", m.filter)
+	}
+
+	if a := m.handleKey(key{kind: keyRune, r: 'q'}); a.kind != actionQuit {
+		t.Errorf("q = %v, want quit", a.kind)
+	}
+	if a := m.handleKey(key{kind: keyCtrlC}); a.kind != actionQuit {
+		t.Errorf("Ctrl-C = %v, want quit", a.kind)
+	}
+}
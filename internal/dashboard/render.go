@@ -0,0 +1,205 @@
+package dashboard
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mdc/internal/pidfile"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+const helpLine = "↑↓ move  s stop  r restart  a attach  u up  d down  / filter  R refresh  q quit"
+
+var (
+	controlSeq = regexp.MustCompile(`\x1b(\[[0-9;?]*[a-zA-Z]|\][^\x07]*\x07|[()][0-9A-Za-z])`)
+	colorOK    = text.Colors{text.FgGreen}
+	colorBad   = text.Colors{text.FgRed}
+	colorWarn  = text.Colors{text.FgYellow}
+	colorCmd   = text.Colors{text.FgCyan}
+	colorDim   = text.Colors{text.Faint}
+	colorTitle = text.Colors{text.Bold}
+	colorCur   = text.Colors{text.ReverseVideo}
+)
+
+// render draws the whole screen as width x height lines.
+func (m *model) render(width, height int) []string {
+	if width < 20 || height < 8 {
+		return []string{fit("terminal too small", width)}
+	}
+
+	var lines []string
+	title := fmt.Sprintf("mdc dashboard — %s", m.configName)
+	switch {
+	case m.psLoading && m.psUpdated.IsZero():
+		title += "  (loading containers...)"
+	case !m.psUpdated.IsZero():
+		title += fmt.Sprintf("  (containers: %s)", m.psUpdated.Format("15:04:05"))
+	}
+	lines = append(lines, colorTitle.Sprint(fit(title, width)))
+
+	header := fmt.Sprintf("  %s %s %s %s", pad("PROJECT", projectWidth), pad("KIND", kindWidth), pad("NAME / COMMAND", nameWidth(width)), "PID / STATUS")
+	lines = append(lines, colorDim.Sprint(fit(header, width)))
+
+	// Split the remaining space between the table and the log pane.
+	footer := 2
+	logHeight := (height - 2 - footer) / 3
+	if logHeight < 3 {
+		logHeight = 3
+	}
+	tableHeight := height - 2 - footer - logHeight
+
+	rows := m.rows()
+	start := 0
+	if m.cursor >= tableHeight {
+		start = m.cursor - tableHeight + 1
+	}
+	for i := start; i < len(rows) && i < start+tableHeight; i++ {
+		line := fit(m.formatRow(rows[i], width), width)
+		if i == m.cursor {
+			line = colorCur.Sprint(text.StripEscape(line))
+		}
+		lines = append(lines, line)
+	}
+	if len(rows) == 0 {
+		lines = append(lines, colorDim.Sprint(fit("  no projects match the filter", width)))
+	}
+	for len(lines) < 2+tableHeight {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, colorDim.Sprint(strings.Repeat("─", width)))
+	logLines := m.logLines
+	if len(logLines) > logHeight-1 {
+		logLines = logLines[len(logLines)-(logHeight-1):]
+	}
+	lines = append(lines, colorTitle.Sprint(fit(m.logTitle(), width)))
+	for _, l := range logLines {
+		lines = append(lines, fit(l, width))
+	}
+	for len(lines) < height-footer {
+		lines = append(lines, "")
+	}
+
+	status := m.status
+	if m.busy != "" {
+		status = m.busy + "..."
+	}
+	if m.filtering || m.filter != "" {
+		status = fmt.Sprintf("filter: %s", m.filter)
+		if m.filtering {
+			status += "█"
+		}
+	}
+	lines = append(lines, fit(status, width))
+	lines = append(lines, colorDim.Sprint(fit(helpLine, width)))
+	return lines
+}
+
+const (
+	projectWidth = 16
+	kindWidth    = 9
+	statusWidth  = 24
+)
+
+// nameWidth gives the container name / command column whatever the other
+// columns leave over.
+func nameWidth(width int) int {
+	w := width - 2 - projectWidth - kindWidth - statusWidth - 3
+	if w < 12 {
+		return 12
+	}
+	return w
+}
+
+func (m *model) formatRow(r row, width int) string {
+	nw := nameWidth(width)
+	switch r.kind {
+	case rowContainer:
+		status := colorBad.Sprint(r.container.Status)
+		if r.running {
+			status = colorOK.Sprint(r.container.Status)
+		}
+		return fmt.Sprintf("  %s %s %s %s", pad("", projectWidth), pad("container", kindWidth), pad(r.container.Name, nw), status)
+	case rowProcess:
+		var status string
+		switch r.status {
+		case pidfile.StatusRunning:
+			status = colorOK.Sprint(r.status)
+		case pidfile.StatusStale:
+			status = colorWarn.Sprint(r.status)
+		default:
+			if exit, ok := m.exits[r.entry.PID]; ok {
+				status = colorBad.Sprint(exit)
+			} else {
+				status = colorBad.Sprint(pidfile.StatusDead)
+			}
+		}
+		if r.entry.Restarts > 0 {
+			status += fmt.Sprintf(" (restarts: %d)", r.entry.Restarts)
+		}
+		return fmt.Sprintf("  %s %s %s %d %s", pad("", projectWidth), pad("process", kindWidth), colorCmd.Sprint(pad(r.entry.Command, nw)), r.entry.PID, status)
+	default:
+		summary := ""
+		if err, ok := m.psErrs[r.project]; ok {
+			summary = colorBad.Sprint(firstLine(err.Error()))
+		} else if len(m.containers[r.project]) == 0 && len(m.processes[r.project]) == 0 {
+			summary = colorDim.Sprint("not running")
+		}
+		return fmt.Sprintf("▸ %s %s", pad(r.project, projectWidth), summary)
+	}
+}
+
+func (m *model) logTitle() string {
+	r, ok := m.selected()
+	if !ok || r.kind != rowProcess {
+		return "LOG (select a background process)"
+	}
+	return fmt.Sprintf("LOG [%s] %s (PID %d)", r.project, r.entry.Command, r.entry.PID)
+}
+
+// sanitizeLog turns raw proc log output into printable lines: escape
+// sequences are removed and carriage-return redraws keep the final text.
+func sanitizeLog(s string) []string {
+	s = controlSeq.ReplaceAllString(s, "")
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if i := strings.LastIndex(strings.TrimRight(line, "\r"), "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		line = strings.Map(func(r rune) rune {
+			switch {
+			case r == '\t':
+				return ' '
+			case r < 0x20 || r == 0x7f:
+				return -1
+			}
+			return r
+		}, line)
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// fit truncates s to the given display width, ignoring escape sequences.
+func fit(s string, width int) string {
+	if text.StringWidthWithoutEscSequences(s) <= width {
+		return s
+	}
+	return text.Snip(s, width, "…")
+}
+
+func pad(s string, width int) string {
+	return text.Pad(fit(s, width), width, ' ')
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package dashboard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+func TestRender(t *testing.T) {
+	m := testModel()
+	m.handleKey(key{kind: keyDown})
+	m.logLines = []string{"listening on :3000"}
+
+	lines := m.render(80, 20)
+	if len(lines) != 20 {
+		t.Fatalf("render() returned %d lines, want 20", len(lines))
+	}
+	for i, l := range lines {
+		if w := text.StringWidthWithoutEscSequences(l); w > 80 {
+			t.Errorf("line %d is %d columns wide, want <= 80: %q", i, w, text.StripEscape(l))
+		}
+	}
+
+	screen := text.StripEscape(strings.Join(lines, "\n"))
+	for _, want := range []string{
+		"mdc dashboard — demo",
+		"npm run dev",
+		"Running",
+		"Dead",
+		"api-app-1",
+		"docker compose ps failed",
+		"LOG [web] npm run dev (PID 100)",
+		"listening on :3000",
+		"q quit",
+	} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen missing %q:\n%s", want, screen)
+		}
+	}
+}
+
+func TestRenderTooSmall(t *testing.T) {
+	lines := testModel().render(30, 5)
+	if len(lines) != 1 || !strings.Contains(lines[0], "too small") {
+		t.Errorf("render() = %q, want a single 'too small' line", lines)
+	}
+}
+
+func TestSanitizeLog(t *testing.T) {
+	in := "\x1b[32mready\x1b[0m\r\n50%\r100%\n\ttabbed\x07\n\n"
+	got := sanitizeLog(in)
+	want := []string{"ready", "100%", " tabbed"}
+	if len(got) != len(want) {
+		t.Fatalf("sanitizeLog() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sanitizeLog()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,378 @@
+// Package doctor cross-checks the state mdc keeps in ~/.config/mdc (configs,
+// PID files and proc logs) against the running processes and the host tools,
+// and cleans up what has drifted.
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/pidfile"
+)
+
+// Check identifies the kind of a finding.
+type Check string
+
+const (
+	CheckDeadEntry     Check = "dead-entry"
+	CheckOrphanLog     Check = "orphan-log"
+	CheckRemovedConfig Check = "removed-config"
+	CheckInvalidConfig Check = "invalid-config"
+	CheckMissingPath   Check = "missing-path"
+	CheckMissingBinary Check = "missing-binary"
+	CheckComposeV1     Check = "compose-v1"
+)
+
+type Severity string
+
+const (
+	// SeverityWarning is leftover state: mdc keeps working, but the state is
+	// misleading or wastes space.
+	SeverityWarning Severity = "warning"
+	// SeverityError breaks commands of mdc.
+	SeverityError Severity = "error"
+)
+
+// Finding is a single problem. Findings with a fix can be cleaned up by Fix.
+type Finding struct {
+	Check    Check
+	Severity Severity
+	Config   string
+	Project  string
+	PID      int
+	Path     string
+	Message  string
+	// Fixed is set by Fix once the problem was cleaned up; FixErr when
+	// cleaning up failed.
+	Fixed  bool
+	FixErr error
+
+	fix func() error
+}
+
+// Fixable reports whether Fix can clean up the finding.
+func (f *Finding) Fixable() bool {
+	return f.fix != nil
+}
+
+// PendingLogGrace is how old a "_pending.log" must be before it counts as
+// an orphan: younger ones may belong to a process that is starting.
+const PendingLogGrace = time.Minute
+
+var (
+	// lookPath and composeV2 are overridden in tests.
+	lookPath  = exec.LookPath
+	composeV2 = func() bool {
+		return exec.Command("docker", "compose", "version").Run() == nil
+	}
+)
+
+// Diagnose runs all checks and returns the findings, ordered by check.
+func Diagnose() ([]*Finding, error) {
+	var findings []*Finding
+	configs, err := configNames()
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := checkState(configs)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, state...)
+	findings = append(findings, checkProjectPaths(configs)...)
+	findings = append(findings, checkBinaries()...)
+
+	order := map[Check]int{}
+	for i, c := range []Check{CheckMissingBinary, CheckComposeV1, CheckInvalidConfig, CheckMissingPath, CheckRemovedConfig, CheckDeadEntry, CheckOrphanLog} {
+		order[c] = i
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return order[findings[i].Check] < order[findings[j].Check]
+	})
+	return findings, nil
+}
+
+// Fix cleans up every fixable finding and records the outcome in it.
+func Fix(findings []*Finding) {
+	for _, f := range findings {
+		if f.fix == nil || f.Fixed {
+			continue
+		}
+		if err := f.fix(); err != nil {
+			f.FixErr = err
+			continue
+		}
+		f.Fixed = true
+	}
+}
+
+// configNames returns the names of the existing config files, without
+// extension.
+func configNames() (map[string]bool, error) {
+	files, err := config.ListConfigs()
+	if err != nil {
+		dir, dirErr := config.DefaultConfigDir()
+		if dirErr == nil {
+			if _, statErr := os.Stat(dir); os.IsNotExist(statErr) {
+				return map[string]bool{}, nil
+			}
+		}
+		return nil, err
+	}
+	names := make(map[string]bool, len(files))
+	for _, f := range files {
+		names[f[:len(f)-len(filepath.Ext(f))]] = true
+	}
+	return names, nil
+}
+
+// checkState reports PID files and proc logs that no longer match a config
+// or a running process.
+func checkState(configs map[string]bool) ([]*Finding, error) {
+	var findings []*Finding
+
+	pidConfigs, err := pidfile.ConfigNames()
+	if err != nil {
+		return nil, err
+	}
+	hasPIDs := make(map[string]bool, len(pidConfigs))
+	for _, configName := range pidConfigs {
+		hasPIDs[configName] = true
+		projects, err := pidfile.LoadAll(configName)
+		if err != nil {
+			return nil, err
+		}
+		if !configs[configName] {
+			findings = append(findings, removedConfig(configName, projects))
+			continue
+		}
+		findings = append(findings, deadEntries(configName, projects)...)
+	}
+
+	logConfigs, err := pidfile.LogConfigNames()
+	if err != nil {
+		return nil, err
+	}
+	for _, configName := range logConfigs {
+		logDir, err := pidfile.ProcLogDir(configName)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !configs[configName] && !hasPIDs[configName]:
+			findings = append(findings, &Finding{
+				Check:    CheckOrphanLog,
+				Severity: SeverityWarning,
+				Config:   configName,
+				Path:     logDir,
+				Message:  "proc logs of a config that no longer exists",
+				fix:      func() error { return os.RemoveAll(logDir) },
+			})
+		case configs[configName]:
+			findings = append(findings, orphanLogs(configName, logDir)...)
+		}
+	}
+	return findings, nil
+}
+
+// removedConfig reports the PID directory of a deleted config. Fixing it
+// stops the processes that are still running and removes the PID and log
+// directories.
+func removedConfig(configName string, projects map[string][]pidfile.Entry) *Finding {
+	running := 0
+	for _, entries := range projects {
+		for _, e := range entries {
+			if e.Status() == pidfile.StatusRunning {
+				running++
+			}
+		}
+	}
+	dir, _ := pidfile.Dir(configName)
+	msg := "PID files of a config that no longer exists"
+	if running > 0 {
+		msg = fmt.Sprintf("%s; %d process(es) still running", msg, running)
+	}
+	return &Finding{
+		Check:    CheckRemovedConfig,
+		Severity: SeverityWarning,
+		Config:   configName,
+		Path:     dir,
+		Message:  msg,
+		fix: func() error {
+			if err := pidfile.KillAll(configName); err != nil {
+				return err
+			}
+			// KillAll leaves the directory when it was already empty.
+			return os.RemoveAll(dir)
+		},
+	}
+}
+
+// deadEntries reports tracked processes that have exited, or whose PID now
+// belongs to another process.
+func deadEntries(configName string, projects map[string][]pidfile.Entry) []*Finding {
+	var findings []*Finding
+	for _, projectName := range sortedKeys(projects) {
+		for _, e := range projects[projectName] {
+			status := e.Status()
+			if status == pidfile.StatusRunning {
+				continue
+			}
+			msg := fmt.Sprintf("%q is no longer running", e.Command)
+			if status == pidfile.StatusStale {
+				msg = fmt.Sprintf("%q is no longer running; its PID was reused by another process", e.Command)
+			}
+			pid := e.PID
+			findings = append(findings, &Finding{
+				Check:    CheckDeadEntry,
+				Severity: SeverityWarning,
+				Config:   configName,
+				Project:  projectName,
+				PID:      pid,
+				Message:  msg,
+				fix: func() error {
+					return pidfile.RemoveEntry(configName, projectName, pid)
+				},
+			})
+		}
+	}
+	return findings
+}
+
+// orphanLogs reports pending logs left by failed starts and the log
+// directories of projects that are neither in the config nor tracked.
+func orphanLogs(configName, logDir string) []*Finding {
+	cfg, err := config.Load(configName)
+	if err != nil {
+		// Reported by checkProjectPaths.
+		return nil
+	}
+	projects := make(map[string]bool, len(cfg.Projects))
+	for _, name := range cfg.ProjectNames() {
+		projects[name] = true
+	}
+	tracked, _ := pidfile.LoadAll(configName)
+
+	dirEntries, err := os.ReadDir(logDir)
+	if err != nil {
+		return nil
+	}
+	var findings []*Finding
+	for _, de := range dirEntries {
+		if !de.IsDir() {
+			continue
+		}
+		projectName := de.Name()
+		projectDir := filepath.Join(logDir, projectName)
+		if !projects[projectName] && len(tracked[projectName]) == 0 {
+			findings = append(findings, &Finding{
+				Check:    CheckOrphanLog,
+				Severity: SeverityWarning,
+				Config:   configName,
+				Project:  projectName,
+				Path:     projectDir,
+				Message:  "proc logs of a project that is not in the config",
+				fix:      func() error { return os.RemoveAll(projectDir) },
+			})
+			continue
+		}
+		pending, err := pidfile.ProcLogTmpPath(configName, projectName)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(pending)
+		if err != nil || time.Since(info.ModTime()) < PendingLogGrace {
+			continue
+		}
+		findings = append(findings, &Finding{
+			Check:    CheckOrphanLog,
+			Severity: SeverityWarning,
+			Config:   configName,
+			Project:  projectName,
+			Path:     pending,
+			Message:  "pending log left by a background command that failed to start",
+			fix:      func() error { return os.Remove(pending) },
+		})
+	}
+	return findings
+}
+
+// checkProjectPaths reports configs that cannot be loaded and project paths
+// that do not exist.
+func checkProjectPaths(configs map[string]bool) []*Finding {
+	var findings []*Finding
+	for _, configName := range sortedKeys(configs) {
+		cfg, err := config.Load(configName)
+		if err != nil {
+			findings = append(findings, &Finding{
+				Check:    CheckInvalidConfig,
+				Severity: SeverityError,
+				Config:   configName,
+				Message:  err.Error(),
+			})
+			continue
+		}
+		for _, p := range cfg.Projects {
+			if info, err := os.Stat(p.Path); err == nil && info.IsDir() {
+				continue
+			}
+			findings = append(findings, &Finding{
+				Check:    CheckMissingPath,
+				Severity: SeverityError,
+				Config:   configName,
+				Project:  p.Name,
+				Path:     p.Path,
+				Message:  "project path does not exist or is not a directory",
+			})
+		}
+	}
+	return findings
+}
+
+// checkBinaries reports missing tools: docker for compose projects, and
+// Compose V1, whose docker-compose does not support the JSON output "mdc ps"
+// relies on.
+func checkBinaries() []*Finding {
+	var findings []*Finding
+	missing := func(msg string) {
+		findings = append(findings, &Finding{
+			Check:    CheckMissingBinary,
+			Severity: SeverityError,
+			Message:  msg,
+		})
+	}
+
+	_, dockerErr := lookPath("docker")
+	if dockerErr != nil {
+		missing("docker not found in PATH: docker compose commands and mdc ps will fail")
+	}
+
+	if dockerErr == nil && composeV2() {
+		return findings
+	}
+	if _, err := lookPath("docker-compose"); err == nil {
+		findings = append(findings, &Finding{
+			Check:    CheckComposeV1,
+			Severity: SeverityWarning,
+			Message:  "only docker-compose (Compose V1) is available: mdc ps needs Docker Compose V2",
+		})
+	} else if dockerErr == nil {
+		missing("docker compose is not available: install the Docker Compose V2 plugin")
+	}
+	return findings
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
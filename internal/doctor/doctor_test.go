@@ -0,0 +1,187 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"mdc/internal/pidfile"
+)
+
+// withHome points ~/.config/mdc to a temporary directory and stubs the host
+// tools as all present.
+func withHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	oldLookPath, oldComposeV2 := lookPath, composeV2
+	lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	composeV2 = func() bool { return true }
+	t.Cleanup(func() { lookPath, composeV2 = oldLookPath, oldComposeV2 })
+	return filepath.Join(home, ".config", "mdc")
+}
+
+// deadPID returns the PID of a process that has exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot run true: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checks(findings []*Finding) []string {
+	var result []string
+	for _, f := range findings {
+		s := string(f.Check)
+		if f.Config != "" {
+			s += " " + f.Config
+		}
+		if f.Project != "" {
+			s += "/" + f.Project
+		}
+		result = append(result, s)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestDiagnoseAndFix(t *testing.T) {
+	base := withHome(t)
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(base, "app.yml"), `
+execution_mode: parallel
+projects:
+  - name: web
+    path: `+projectDir+`
+    commands:
+      up:
+        - command: "npm run dev"
+          background: true
+  - name: api
+    path: `+filepath.Join(projectDir, "missing")+`
+    commands:
+      up:
+        - command: "echo up"
+`)
+
+	if err := pidfile.Save("app", "web", []pidfile.Entry{{PID: deadPID(t), Command: "npm run dev"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pidfile.Save("gone", "svc", []pidfile.Entry{{PID: deadPID(t), Command: "make run"}}); err != nil {
+		t.Fatal(err)
+	}
+	pending := filepath.Join(base, "proc", "app", "web", "_pending.log")
+	writeFile(t, pending, "started\n")
+	old := time.Now().Add(-2 * PendingLogGrace)
+	if err := os.Chtimes(pending, old, old); err != nil {
+		t.Fatal(err)
+	}
+	// A pending log being written right now is left alone.
+	writeFile(t, filepath.Join(base, "proc", "app", "api", "_pending.log"), "starting\n")
+	writeFile(t, filepath.Join(base, "proc", "app", "legacy", "1.log"), "old\n")
+	writeFile(t, filepath.Join(base, "proc", "other", "web", "2.log"), "old\n")
+
+	findings, err := Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose() error: %v", err)
+	}
+	want := []string{
+		"dead-entry app/web",
+		"missing-path app/api",
+		"orphan-log app/legacy",
+		"orphan-log app/web",
+		"orphan-log other",
+		"removed-config gone",
+	}
+	if got := checks(findings); strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+
+	Fix(findings)
+	for _, f := range findings {
+		if f.Fixable() != f.Fixed || f.FixErr != nil {
+			t.Errorf("%s: fixable=%v fixed=%v err=%v", f.Check, f.Fixable(), f.Fixed, f.FixErr)
+		}
+	}
+
+	findings, err = Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose() after Fix error: %v", err)
+	}
+	if got := checks(findings); len(got) != 1 || got[0] != "missing-path app/api" {
+		t.Errorf("findings after Fix = %v, want only the missing path", got)
+	}
+	for _, path := range []string{
+		filepath.Join(base, "pids", "gone"),
+		filepath.Join(base, "proc", "other"),
+		filepath.Join(base, "proc", "app", "legacy"),
+		pending,
+	} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should have been removed", path)
+		}
+	}
+}
+
+func TestDiagnoseInvalidConfig(t *testing.T) {
+	base := withHome(t)
+	writeFile(t, filepath.Join(base, "broken.yml"), "execution_mode: sometimes\nprojects: []\n")
+
+	findings, err := Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose() error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Check != CheckInvalidConfig || findings[0].Severity != SeverityError {
+		t.Errorf("findings = %v, want one invalid-config error", checks(findings))
+	}
+}
+
+func TestCheckBinaries(t *testing.T) {
+	withHome(t)
+	tests := []struct {
+		name      string
+		missing   []string
+		composeV2 bool
+		want      []string
+	}{
+		{name: "all present", composeV2: true},
+		{name: "compose v1 only", want: []string{"compose-v1"}},
+		{name: "no compose", missing: []string{"docker-compose"}, want: []string{"missing-binary"}},
+		{name: "no docker", missing: []string{"docker", "docker-compose"}, want: []string{"missing-binary"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookPath = func(name string) (string, error) {
+				for _, m := range tt.missing {
+					if m == name {
+						return "", errors.New("not found")
+					}
+				}
+				return "/usr/bin/" + name, nil
+			}
+			composeV2 = func() bool { return tt.composeV2 }
+
+			got := checks(checkBinaries())
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("checkBinaries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
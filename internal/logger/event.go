@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"io"
+	"time"
+)
+
+// EventType identifies what happened in an Event.
+type EventType string
+
+// Events emitted by the logger functions. The values are part of the
+// "--output json" stream and must stay stable.
+const (
+	EventBorder          EventType = "border"
+	EventStart           EventType = "start"
+	EventSuccess         EventType = "success"
+	EventFailure         EventType = "failure"
+	EventCanceled        EventType = "canceled"
+	EventRetry           EventType = "retry"
+	EventBackground      EventType = "background"
+	EventAlreadyRunning  EventType = "already_running"
+	EventStop            EventType = "stop"
+	EventStopped         EventType = "stopped"
+	EventProjectDone     EventType = "project_done"
+	EventProjectFailed   EventType = "project_failed"
+	EventProjectSkipped  EventType = "project_skipped"
+	EventRollback        EventType = "rollback"
+	EventInterrupted     EventType = "interrupted"
+	EventForceKill       EventType = "force_kill"
+	EventWaiting         EventType = "healthcheck_waiting"
+	EventHealthy         EventType = "healthy"
+	EventUnhealthy       EventType = "unhealthy"
+	EventSummary         EventType = "summary"
+	EventAttach          EventType = "attach"
+	EventDetach          EventType = "detach"
+	EventProcessExited   EventType = "process_exited"
+	EventRestarting      EventType = "restarting"
+	EventSupervisorStart EventType = "supervisor_start"
+	EventSupervisorExit  EventType = "supervisor_exit"
+	EventWarning         EventType = "warning"
+	EventPlan            EventType = "plan"
+	EventPlanProject     EventType = "plan_project"
+	EventPlanStopHeader  EventType = "plan_stop_header"
+	EventPlanStop        EventType = "plan_stop"
+	EventOutput          EventType = "output"
+	EventLog             EventType = "log"
+)
+
+// Event is a single thing worth reporting to the user. Only the fields
+// relevant to the event type are set.
+type Event struct {
+	Type        EventType        `json:"event"`
+	Time        time.Time        `json:"time"`
+	Config      string           `json:"config,omitempty"`
+	Action      string           `json:"action,omitempty"`
+	Mode        string           `json:"mode,omitempty"`
+	Project     string           `json:"project,omitempty"`
+	Path        string           `json:"path,omitempty"`
+	Command     string           `json:"command,omitempty"`
+	Commands    []PlannedCommand `json:"commands,omitempty"`
+	PID         int              `json:"pid,omitempty"`
+	ExitCode    *int             `json:"exit_code,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	Reason      string           `json:"reason,omitempty"`
+	Message     string           `json:"message,omitempty"`
+	Check       string           `json:"check,omitempty"`
+	Elapsed     time.Duration    `json:"-"`
+	Attempt     int              `json:"attempt,omitempty"`
+	MaxAttempts int              `json:"max_attempts,omitempty"`
+	Delay       time.Duration    `json:"-"`
+	Output      string           `json:"output,omitempty"`
+	Results     []ProjectResult  `json:"results,omitempty"`
+}
+
+// PlannedCommand describes a command listed by a dry run.
+type PlannedCommand struct {
+	Command     string `json:"command"`
+	Background  bool   `json:"background,omitempty"`
+	HealthCheck string `json:"healthcheck,omitempty"`
+}
+
+// Renderer writes events to the output. Render is called with the logger
+// lock held, so implementations need no synchronization of their own.
+type Renderer interface {
+	Render(w io.Writer, e Event)
+	// Structured reports whether the output is meant for machines rather
+	// than a terminal.
+	Structured() bool
+}
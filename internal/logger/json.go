@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONRenderer writes one JSON object per event (NDJSON). Purely visual
+// events such as borders are dropped.
+type JSONRenderer struct{}
+
+func (JSONRenderer) Structured() bool { return true }
+
+func (JSONRenderer) Render(w io.Writer, e Event) {
+	switch e.Type {
+	case EventBorder, EventPlanStopHeader:
+		return
+	}
+	line := struct {
+		Event
+		ElapsedMS int64 `json:"elapsed_ms,omitempty"`
+		DelayMS   int64 `json:"delay_ms,omitempty"`
+	}{
+		Event:     e,
+		ElapsedMS: e.Elapsed.Milliseconds(),
+		DelayMS:   e.Delay.Milliseconds(),
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write(append(data, '\n'))
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func captureJSON(t *testing.T, fn func()) []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetRenderer(JSONRenderer{})
+	t.Cleanup(func() {
+		SetOutput(&buf)
+		SetRenderer(TextRenderer{})
+	})
+	fn()
+
+	var events []map[string]any
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestJSONRendererEvents(t *testing.T) {
+	events := captureJSON(t, func() {
+		Border()
+		Start("api", "make build")
+		Success("api", "make build")
+		Background("api", "npm run dev", 4242)
+		Healthy("api", "tcp localhost:3000", 1500*time.Millisecond)
+		ProjectDone("api")
+		Summary("up", []ProjectResult{{Name: "api", State: StateCompleted}})
+	})
+
+	wantTypes := []string{"start", "success", "background", "healthy", "project_done", "summary"}
+	if len(events) != len(wantTypes) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(wantTypes), events)
+	}
+	for i, want := range wantTypes {
+		if events[i]["event"] != want {
+			t.Errorf("events[%d].event = %v, want %q", i, events[i]["event"], want)
+		}
+		if _, ok := events[i]["time"]; !ok {
+			t.Errorf("events[%d] missing time", i)
+		}
+	}
+	if events[0]["project"] != "api" || events[0]["command"] != "make build" {
+		t.Errorf("start event = %v", events[0])
+	}
+	if events[2]["pid"] != float64(4242) {
+		t.Errorf("background pid = %v, want 4242", events[2]["pid"])
+	}
+	if events[3]["elapsed_ms"] != float64(1500) {
+		t.Errorf("healthy elapsed_ms = %v, want 1500", events[3]["elapsed_ms"])
+	}
+	results, ok := events[5]["results"].([]any)
+	if !ok || len(results) != 1 {
+		t.Fatalf("summary results = %v", events[5]["results"])
+	}
+	if r := results[0].(map[string]any); r["name"] != "api" || r["state"] != "completed" {
+		t.Errorf("summary result = %v", r)
+	}
+}
+
+func TestJSONRendererFailureExitCode(t *testing.T) {
+	runErr := exec.Command("sh", "-c", "exit 3").Run()
+	events := captureJSON(t, func() {
+		Error("api", "exit 3", runErr)
+		Error("api", "missing", errors.New("not found"))
+	})
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0]["event"] != "failure" || events[0]["exit_code"] != float64(3) {
+		t.Errorf("failure event = %v, want exit_code 3", events[0])
+	}
+	if _, ok := events[1]["exit_code"]; ok {
+		t.Errorf("failure without exit status should omit exit_code: %v", events[1])
+	}
+	if !strings.Contains(events[1]["error"].(string), "not found") {
+		t.Errorf("failure error = %v", events[1]["error"])
+	}
+}
+
+func TestStructured(t *testing.T) {
+	defer SetRenderer(TextRenderer{})
+	SetRenderer(TextRenderer{})
+	if Structured() {
+		t.Error("Structured() = true for TextRenderer")
+	}
+	SetRenderer(JSONRenderer{})
+	if !Structured() {
+		t.Error("Structured() = false for JSONRenderer")
+	}
+}
@@ -1,12 +1,13 @@
 package logger
 
 import (
-	"fmt"
+	"errors"
 	"io"
 	"math/rand/v2"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"mdc/internal/config"
 
@@ -15,8 +16,10 @@ import (
 )
 
 var (
-	mu  sync.Mutex
-	out io.Writer = os.Stdout
+	mu       sync.Mutex
+	out      io.Writer = os.Stdout
+	renderer Renderer  = TextRenderer{}
+	now                = time.Now
 
 	projectColors = map[string]text.Colors{}
 	colorPalette = []text.Color{
@@ -56,6 +59,22 @@ func SetOutput(w io.Writer) {
 	out = w
 }
 
+// SetRenderer selects how events are written, e.g. TextRenderer for humans
+// or JSONRenderer for an NDJSON event stream.
+func SetRenderer(r Renderer) {
+	mu.Lock()
+	defer mu.Unlock()
+	renderer = r
+}
+
+// Structured reports whether the current renderer emits machine-readable
+// output. Callers must then avoid writing raw command output to stdout.
+func Structured() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return renderer.Structured()
+}
+
 func ResetColors() {
 	mu.Lock()
 	defer mu.Unlock()
@@ -96,105 +115,198 @@ func outputBorder() string {
 	return strings.Repeat("=", terminalWidth())
 }
 
-func writef(format string, args ...any) {
+func emit(e Event) {
 	mu.Lock()
 	defer mu.Unlock()
-	_, _ = fmt.Fprintf(out, format, args...)
+	e.Time = now()
+	renderer.Render(out, e)
 }
 
 func Border() {
-	writef("%s\n", outputBorder())
+	emit(Event{Type: EventBorder})
 }
 
 func Start(projectName, cmd string) {
-	writef("🚀 [%s] Executing: %s\n", prefix(projectName), colorCmd(cmd))
+	emit(Event{Type: EventStart, Project: projectName, Command: cmd})
+}
+
+// StartAttempt is Start for a command that is retried when it fails.
+func StartAttempt(projectName, cmd string, attempt, max int) {
+	emit(Event{Type: EventStart, Project: projectName, Command: cmd, Attempt: attempt, MaxAttempts: max})
+}
+
+// Retrying reports that a failed command is run again after delay.
+func Retrying(projectName, cmd string, attempt, max int, delay time.Duration) {
+	emit(Event{Type: EventRetry, Project: projectName, Command: cmd, Attempt: attempt, MaxAttempts: max, Delay: delay})
 }
 
 func Success(projectName, cmd string) {
-	writef("✅ [%s] Completed: %s\n", prefix(projectName), colorCmd(cmd))
+	emit(Event{Type: EventSuccess, Project: projectName, Command: cmd})
 }
 
 func Error(projectName, cmd string, err error) {
-	writef("❌ [%s] Failed: %s — %s\n", prefix(projectName), colorCmd(cmd), err)
+	emit(Event{Type: EventFailure, Project: projectName, Command: cmd, Error: err.Error(), ExitCode: exitCode(err)})
+}
+
+// Canceled reports a command that was stopped before it finished, e.g.
+// because another project failed with --fail-fast.
+func Canceled(projectName, cmd string, reason error) {
+	emit(Event{Type: EventCanceled, Project: projectName, Command: cmd, Reason: reason.Error()})
 }
 
 func Background(projectName, cmd string, pid int) {
-	writef("🔄 [%s] Background: %s (PID: %s)\n", prefix(projectName), colorCmd(cmd), colorPID(pid))
+	emit(Event{Type: EventBackground, Project: projectName, Command: cmd, PID: pid})
+}
+
+// AlreadyRunning reports a background command that was not started because
+// it is already running.
+func AlreadyRunning(projectName, cmd string, pid int) {
+	emit(Event{Type: EventAlreadyRunning, Project: projectName, Command: cmd, PID: pid})
 }
 
 func Stop(projectName, cmd string, pid int) {
-	writef("🛑 [%s] Stopping: %s (PID: %s)\n", prefix(projectName), colorCmd(cmd), colorPID(pid))
+	emit(Event{Type: EventStop, Project: projectName, Command: cmd, PID: pid})
 }
 
 func Stopped(projectName string) {
-	writef("✅ [%s] Stopped successfully\n", prefix(projectName))
+	emit(Event{Type: EventStopped, Project: projectName})
 }
 
 func ProjectDone(projectName string) {
-	writef("✅ [%s] All commands completed\n", prefix(projectName))
+	emit(Event{Type: EventProjectDone, Project: projectName})
 }
 
 func ProjectFailed(projectName string, err error) {
-	writef("❌ [%s] Aborted — %s\n", prefix(projectName), err)
+	emit(Event{Type: EventProjectFailed, Project: projectName, Error: err.Error()})
+}
+
+func ProjectSkipped(projectName, reason string) {
+	emit(Event{Type: EventProjectSkipped, Project: projectName, Reason: reason})
+}
+
+// Interrupted reports that Ctrl-C was pressed and the running commands are
+// being waited for.
+func Interrupted() {
+	emit(Event{Type: EventInterrupted})
+}
+
+// ForceKill reports that the running commands are being killed after a
+// second Ctrl-C.
+func ForceKill() {
+	emit(Event{Type: EventForceKill})
+}
+
+// Rollback reports that the projects are being rolled back after a failure.
+func Rollback(projects []string) {
+	emit(Event{Type: EventRollback, Message: strings.Join(projects, ", ")})
+}
+
+func Waiting(projectName, check string) {
+	emit(Event{Type: EventWaiting, Project: projectName, Check: check})
+}
+
+func Healthy(projectName, check string, elapsed time.Duration) {
+	emit(Event{Type: EventHealthy, Project: projectName, Check: check, Elapsed: elapsed})
+}
+
+func Unhealthy(projectName, check string, err error) {
+	emit(Event{Type: EventUnhealthy, Project: projectName, Check: check, Error: err.Error()})
+}
+
+// Project states reported by Summary.
+const (
+	StateCompleted   = "completed"
+	StateFailed      = "failed"
+	StateSkipped     = "skipped"
+	StateNotStarted  = "not started"
+	StateCanceled    = "canceled"
+	StateInterrupted = "interrupted"
+)
+
+// ProjectResult is the final state of a single project after a run.
+type ProjectResult struct {
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func Summary(action string, results []ProjectResult) {
+	emit(Event{Type: EventSummary, Action: action, Results: results})
 }
 
 func Attach(projectName, cmd string, pid int) {
-	writef("📎 [%s] Attached: %s (PID: %s)\n", prefix(projectName), colorCmd(cmd), colorPID(pid))
+	emit(Event{Type: EventAttach, Project: projectName, Command: cmd, PID: pid})
 }
 
 func Detach(projectName string) {
-	writef("📎 [%s] Detached\n", prefix(projectName))
+	emit(Event{Type: EventDetach, Project: projectName})
 }
 
 func ProcessExited(projectName string, pid int) {
-	writef("💀 [%s] Process exited (PID: %s)\n", prefix(projectName), colorPID(pid))
+	emit(Event{Type: EventProcessExited, Project: projectName, PID: pid})
+}
+
+func Restarting(projectName, cmd string, attempt, max int, delay time.Duration) {
+	emit(Event{Type: EventRestarting, Project: projectName, Command: cmd, Attempt: attempt, MaxAttempts: max, Delay: delay})
+}
+
+func SupervisorStart(configName string) {
+	emit(Event{Type: EventSupervisorStart, Config: configName})
+}
+
+func SupervisorExit(configName, reason string) {
+	emit(Event{Type: EventSupervisorExit, Config: configName, Reason: reason})
 }
 
 func Warn(projectName, msg string) {
-	writef("⚠️  [%s] %s\n", prefix(projectName), msg)
+	emit(Event{Type: EventWarning, Project: projectName, Message: msg})
 }
 
 func DryRunHeader(action, mode string) {
-	writef("📋 Dry-run: %s (mode: %s)\n", action, mode)
-	writef("%s\n\n", strings.Repeat("━", terminalWidth()))
+	emit(Event{Type: EventPlan, Action: action, Mode: mode})
 }
 
 func DryRunProject(projectName, path string, cmds []config.CommandItem, pathWarning string) {
-	writef("[%s]\n", prefix(projectName))
-	if pathWarning != "" {
-		writef("  📂 %s [%s]\n", path, pathWarning)
-	} else {
-		writef("  📂 %s\n", path)
-	}
+	planned := make([]PlannedCommand, len(cmds))
 	for i, item := range cmds {
-		label := item.Command
-		if item.Background {
-			label += " [background]"
+		planned[i] = PlannedCommand{Command: item.Command, Background: item.Background}
+		if item.HealthCheck != nil {
+			planned[i].HealthCheck = item.HealthCheck.String()
 		}
-		writef("    %d. %s\n", i+1, colorCmd(label))
 	}
-	writef("\n")
+	emit(Event{Type: EventPlanProject, Project: projectName, Path: path, Commands: planned, Message: pathWarning})
 }
 
 func DryRunStopEntry(projectName, command string, pid int) {
-	writef("  [%s] %s (PID: %s)\n", prefix(projectName), colorCmd(command), colorPID(pid))
+	emit(Event{Type: EventPlanStop, Project: projectName, Command: command, PID: pid})
 }
 
 func DryRunStopHeader() {
-	writef("🛑 Stopping background processes:\n")
+	emit(Event{Type: EventPlanStopHeader})
 }
 
 func Output(projectName, output string) {
-	mu.Lock()
-	defer mu.Unlock()
 	trimmed := strings.TrimRight(output, "\n")
 	if trimmed == "" {
 		return
 	}
-	border := outputBorder()
-	_, _ = fmt.Fprintf(out, "   [%s] %s\n", prefix(projectName), border)
-	for _, line := range strings.Split(trimmed, "\n") {
-		_, _ = fmt.Fprintf(out, "   [%s] %s\n", prefix(projectName), line)
+	emit(Event{Type: EventOutput, Project: projectName, Output: trimmed})
+}
+
+// LogLine writes a single line of a process or container log, prefixed
+// with the project name.
+func LogLine(projectName string, pid int, line string) {
+	emit(Event{Type: EventLog, Project: projectName, PID: pid, Output: line})
+}
+
+// exitCode extracts the exit status from errors such as *exec.ExitError.
+func exitCode(err error) *int {
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		code := coder.ExitCode()
+		if code >= 0 {
+			return &code
+		}
 	}
-	_, _ = fmt.Fprintf(out, "   [%s] %s\n", prefix(projectName), border)
+	return nil
 }
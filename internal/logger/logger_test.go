@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"mdc/internal/config"
 
@@ -164,6 +165,103 @@ func TestProjectFailed(t *testing.T) {
 	}
 }
 
+func TestLogLine(t *testing.T) {
+	out := captureOutput(t, func() {
+		LogLine("web", 1234, "listening on :3000")
+	})
+	if got, want := stripANSI(out), "[web] listening on :3000\n"; got != want {
+		t.Errorf("LogLine() = %q, want %q", got, want)
+	}
+}
+
+func TestProjectSkipped(t *testing.T) {
+	out := captureOutput(t, func() {
+		ProjectSkipped("web", `dependency "api" failed`)
+	})
+	plain := stripANSI(out)
+	if !strings.Contains(plain, "[web]") {
+		t.Errorf("output missing project prefix: %q", plain)
+	}
+	if !strings.Contains(plain, `Skipped — dependency "api" failed`) {
+		t.Errorf("output missing reason: %q", plain)
+	}
+}
+
+func TestCanceledAndRollback(t *testing.T) {
+	out := captureOutput(t, func() {
+		Canceled("web", "npm run build", errors.New(`project "api" failed`))
+		Rollback([]string{"db", "api"})
+	})
+	plain := stripANSI(out)
+	for _, want := range []string{
+		`🛑 [web] Canceled: npm run build — project "api" failed`,
+		"Rolling back: db, api",
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("output missing %q: %q", want, plain)
+		}
+	}
+}
+
+func TestHealthCheckMessages(t *testing.T) {
+	out := captureOutput(t, func() {
+		Waiting("api", "tcp localhost:3000")
+		Healthy("api", "tcp localhost:3000", 1500*time.Millisecond)
+		Unhealthy("api", "tcp localhost:3000", errors.New("timed out"))
+	})
+	plain := stripANSI(out)
+	for _, want := range []string{
+		"⏳ [api] Waiting for health check: tcp localhost:3000",
+		"💚 [api] Healthy: tcp localhost:3000 (1.5s)",
+		"💔 [api] Unhealthy: tcp localhost:3000 — timed out",
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("output missing %q: %q", want, plain)
+		}
+	}
+}
+
+func TestSupervisorMessages(t *testing.T) {
+	out := captureOutput(t, func() {
+		SupervisorStart("dev")
+		Restarting("worker", "npm run worker", 2, 5, 2*time.Second)
+		SupervisorExit("dev", "no tracked processes left")
+	})
+	plain := stripANSI(out)
+	for _, want := range []string{
+		`Supervising background processes of "dev"`,
+		"🔁 [worker] Restarting: npm run worker in 2s (2/5)",
+		`Supervisor for "dev" exiting: no tracked processes left`,
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("output missing %q: %q", want, plain)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	out := captureOutput(t, func() {
+		Summary("up", []ProjectResult{
+			{Name: "db", State: StateCompleted},
+			{Name: "api", State: StateFailed, Detail: "health check timed out"},
+			{Name: "web", State: StateSkipped, Detail: `dependency "api" failed`},
+			{Name: "docs", State: StateCanceled, Detail: `project "api" failed`},
+		})
+	})
+	plain := stripANSI(out)
+	for _, want := range []string{
+		"Summary (up):",
+		"✅ [db] completed",
+		"❌ [api] failed — health check timed out",
+		`[web] skipped — dependency "api" failed`,
+		`🛑 [docs] canceled — project "api" failed`,
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("output missing %q: %q", want, plain)
+		}
+	}
+}
+
 func TestOutput(t *testing.T) {
 	t.Run("single line with borders", func(t *testing.T) {
 		out := captureOutput(t, func() {
@@ -358,3 +456,35 @@ func TestProjectColorVariety(t *testing.T) {
 		t.Errorf("different projects should get different colors, both got %q", ansiA)
 	}
 }
+
+func TestInterrupted(t *testing.T) {
+	out := captureOutput(t, func() {
+		Interrupted()
+		ForceKill()
+	})
+	plain := stripANSI(out)
+	for _, want := range []string{
+		"Interrupted — waiting for running commands to stop (press Ctrl-C again to kill them)",
+		"Killing running commands",
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("output missing %q: %q", want, plain)
+		}
+	}
+}
+
+func TestRetrying(t *testing.T) {
+	out := captureOutput(t, func() {
+		StartAttempt("api", "npm ci", 1, 3)
+		Retrying("api", "npm ci", 2, 3, 2*time.Second)
+	})
+	plain := stripANSI(out)
+	for _, want := range []string{
+		"🚀 [api] Executing: npm ci (attempt 1/3)",
+		"🔁 [api] Retrying: npm ci in 2s (attempt 2/3)",
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("output missing %q: %q", want, plain)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TextRenderer is the default human-readable renderer with emojis and
+// per-project colors.
+type TextRenderer struct{}
+
+func (TextRenderer) Structured() bool { return false }
+
+func (TextRenderer) Render(w io.Writer, e Event) {
+	p := func(format string, args ...any) {
+		_, _ = fmt.Fprintf(w, format, args...)
+	}
+
+	switch e.Type {
+	case EventBorder:
+		p("%s\n", outputBorder())
+	case EventStart:
+		var attempt string
+		if e.MaxAttempts > 1 {
+			attempt = fmt.Sprintf(" (attempt %d/%d)", e.Attempt, e.MaxAttempts)
+		}
+		p("🚀 [%s] Executing: %s%s\n", prefix(e.Project), colorCmd(e.Command), attempt)
+	case EventSuccess:
+		p("✅ [%s] Completed: %s\n", prefix(e.Project), colorCmd(e.Command))
+	case EventFailure:
+		p("❌ [%s] Failed: %s — %s\n", prefix(e.Project), colorCmd(e.Command), e.Error)
+	case EventCanceled:
+		p("🛑 [%s] Canceled: %s — %s\n", prefix(e.Project), colorCmd(e.Command), e.Reason)
+	case EventRetry:
+		p("🔁 [%s] Retrying: %s in %s (attempt %d/%d)\n", prefix(e.Project), colorCmd(e.Command), e.Delay, e.Attempt, e.MaxAttempts)
+	case EventBackground:
+		p("🔄 [%s] Background: %s (PID: %s)\n", prefix(e.Project), colorCmd(e.Command), colorPID(e.PID))
+	case EventAlreadyRunning:
+		p("⏭️  [%s] Already running: %s (PID: %s)\n", prefix(e.Project), colorCmd(e.Command), colorPID(e.PID))
+	case EventStop:
+		p("🛑 [%s] Stopping: %s (PID: %s)\n", prefix(e.Project), colorCmd(e.Command), colorPID(e.PID))
+	case EventStopped:
+		p("✅ [%s] Stopped successfully\n", prefix(e.Project))
+	case EventProjectDone:
+		p("✅ [%s] All commands completed\n", prefix(e.Project))
+	case EventProjectFailed:
+		p("❌ [%s] Aborted — %s\n", prefix(e.Project), e.Error)
+	case EventProjectSkipped:
+		p("⏭️  [%s] Skipped — %s\n", prefix(e.Project), e.Reason)
+	case EventInterrupted:
+		p("\n✋ Interrupted — waiting for running commands to stop (press Ctrl-C again to kill them)\n")
+	case EventForceKill:
+		p("💥 Killing running commands\n")
+	case EventRollback:
+		p("↩️  Rolling back: %s\n", e.Message)
+	case EventWaiting:
+		p("⏳ [%s] Waiting for health check: %s\n", prefix(e.Project), e.Check)
+	case EventHealthy:
+		p("💚 [%s] Healthy: %s (%s)\n", prefix(e.Project), e.Check, e.Elapsed.Round(10*time.Millisecond))
+	case EventUnhealthy:
+		p("💔 [%s] Unhealthy: %s — %s\n", prefix(e.Project), e.Check, e.Error)
+	case EventSummary:
+		p("📊 Summary (%s):\n", e.Action)
+		for _, r := range e.Results {
+			var icon string
+			switch r.State {
+			case StateCompleted:
+				icon = "✅"
+			case StateFailed:
+				icon = "❌"
+			case StateCanceled, StateInterrupted:
+				icon = "🛑"
+			default:
+				icon = "⏭️ "
+			}
+			if r.Detail != "" {
+				p("  %s [%s] %s — %s\n", icon, prefix(r.Name), r.State, r.Detail)
+			} else {
+				p("  %s [%s] %s\n", icon, prefix(r.Name), r.State)
+			}
+		}
+	case EventAttach:
+		p("📎 [%s] Attached: %s (PID: %s)\n", prefix(e.Project), colorCmd(e.Command), colorPID(e.PID))
+	case EventDetach:
+		p("📎 [%s] Detached\n", prefix(e.Project))
+	case EventProcessExited:
+		p("💀 [%s] Process exited (PID: %s)\n", prefix(e.Project), colorPID(e.PID))
+	case EventRestarting:
+		p("🔁 [%s] Restarting: %s in %s (%d/%d)\n", prefix(e.Project), colorCmd(e.Command), e.Delay, e.Attempt, e.MaxAttempts)
+	case EventSupervisorStart:
+		p("👀 Supervising background processes of %q (Ctrl-C to stop)\n", e.Config)
+	case EventSupervisorExit:
+		p("👋 Supervisor for %q exiting: %s\n", e.Config, e.Reason)
+	case EventWarning:
+		p("⚠️  [%s] %s\n", prefix(e.Project), e.Message)
+	case EventPlan:
+		p("📋 Dry-run: %s (mode: %s)\n", e.Action, e.Mode)
+		p("%s\n\n", strings.Repeat("━", terminalWidth()))
+	case EventPlanProject:
+		p("[%s]\n", prefix(e.Project))
+		if e.Message != "" {
+			p("  📂 %s [%s]\n", e.Path, e.Message)
+		} else {
+			p("  📂 %s\n", e.Path)
+		}
+		for i, c := range e.Commands {
+			label := c.Command
+			if c.Background {
+				label += " [background]"
+			}
+			if c.HealthCheck != "" {
+				label += fmt.Sprintf(" [healthcheck: %s]", c.HealthCheck)
+			}
+			p("    %d. %s\n", i+1, colorCmd(label))
+		}
+		p("\n")
+	case EventPlanStopHeader:
+		p("🛑 Stopping background processes:\n")
+	case EventPlanStop:
+		p("  [%s] %s (PID: %s)\n", prefix(e.Project), colorCmd(e.Command), colorPID(e.PID))
+	case EventOutput:
+		border := outputBorder()
+		p("   [%s] %s\n", prefix(e.Project), border)
+		for _, line := range strings.Split(e.Output, "\n") {
+			p("   [%s] %s\n", prefix(e.Project), line)
+		}
+		p("   [%s] %s\n", prefix(e.Project), border)
+	case EventLog:
+		p("[%s] %s\n", prefix(e.Project), e.Output)
+	}
+}
@@ -0,0 +1,176 @@
+package pidfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ExitStatus is recorded by the exec helper next to the proc log when a
+// background command exits: ~/.config/mdc/proc/<config>/<project>/<pid>.exit.
+// It is copied into the PID entry once mdc notices the exit (see
+// RecordExits).
+type ExitStatus struct {
+	// Code is the exit code, or -1 when the command was killed by a signal
+	// or when it is not known how the process ended.
+	Code   int    `json:"code"`
+	Signal string `json:"signal,omitempty"`
+	// ExitedAt is when the process exited or, if that is not known, when
+	// mdc noticed it was gone.
+	ExitedAt time.Time `json:"exited_at"`
+}
+
+// unknownExit is recorded for a process that is gone without leaving an
+// exit status: it was started by an earlier version or on Windows, or its
+// helper was killed with SIGKILL.
+func unknownExit(noticed time.Time) ExitStatus {
+	return ExitStatus{Code: -1, ExitedAt: noticed}
+}
+
+// Known reports whether the exit code or signal is known.
+func (s ExitStatus) Known() bool {
+	return s.Code >= 0 || s.Signal != ""
+}
+
+// Crashed reports whether the process ended with a non-zero exit code, was
+// killed by a signal or died in an unknown way.
+func (s ExitStatus) Crashed() bool {
+	return s.Code != 0 || s.Signal != ""
+}
+
+// String describes the exit status as shown in place of "Dead", e.g.
+// "Exited (code 1)" or "Killed (SIGTERM)". An unknown status is "Dead".
+func (s ExitStatus) String() string {
+	switch {
+	case s.Signal != "":
+		return fmt.Sprintf("Killed (%s)", s.Signal)
+	case !s.Known():
+		return string(StatusDead)
+	}
+	return fmt.Sprintf("Exited (code %d)", s.Code)
+}
+
+// ExitFilePath returns the exit status file of the process with the given
+// PID whose log is at logPath (or is in the same directory).
+func ExitFilePath(logPath string, pid int) string {
+	return filepath.Join(filepath.Dir(logPath), fmt.Sprintf("%d.exit", pid))
+}
+
+// ProcExitFilePath returns the exit status file path for a specific process.
+// Path: ~/.config/mdc/proc/<config-name>/<project-name>/<pid>.exit
+func ProcExitFilePath(configName, projectName string, pid int) (string, error) {
+	logPath, err := ProcLogFilePath(configName, projectName, pid)
+	if err != nil {
+		return "", err
+	}
+	return ExitFilePath(logPath, pid), nil
+}
+
+// WriteExitStatus writes an exit status file atomically.
+func WriteExitStatus(path string, s ExitStatus) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// ReadExitStatus returns the recorded exit status of a process. ok is false
+// when none was recorded: the process is still running, was started by an
+// earlier version, or its helper was killed with SIGKILL.
+func ReadExitStatus(configName, projectName string, pid int) (s ExitStatus, ok bool) {
+	path, err := ProcExitFilePath(configName, projectName, pid)
+	if err != nil {
+		return s, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, false
+	}
+	return s, json.Unmarshal(data, &s) == nil
+}
+
+// LastExit returns how the entry's process ended: the exit recorded in the
+// entry or, until it is recorded, the status left by the exec helper. ok is
+// false when neither is available, e.g. while the process is running.
+func (e Entry) LastExit(configName, projectName string) (ExitStatus, bool) {
+	if e.Exit != nil {
+		return *e.Exit, true
+	}
+	return ReadExitStatus(configName, projectName, e.PID)
+}
+
+// Runtime returns how long the process ran, or has been running so far. It
+// is zero for entries written before the start time was recorded.
+func (e Entry) Runtime(now time.Time) time.Duration {
+	if e.StartedAt.IsZero() {
+		return 0
+	}
+	if e.Exit != nil {
+		now = e.Exit.ExitedAt
+	}
+	if d := now.Sub(e.StartedAt); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Exited is a tracked process whose exit was just recorded.
+type Exited struct {
+	Project string
+	Entry   Entry
+}
+
+// RecordExits copies the exit status of every tracked process of the config
+// that is no longer running into its entry, so that how and when it ended
+// survives log pruning and stays visible in "mdc proc list". Processes that
+// left no status are recorded as an unknown exit noticed now. Each exit is
+// also added to the history. It returns the entries recorded by this call;
+// each exit is returned only once.
+func RecordExits(configName string) ([]Exited, error) {
+	projects, err := LoadAll(configName)
+	if err != nil {
+		return nil, err
+	}
+	var exited []Exited
+	for projectName, entries := range projects {
+		if !hasUnrecordedExit(entries) {
+			continue
+		}
+		err := update(configName, projectName, func(entries []Entry) []Entry {
+			now := time.Now()
+			for i, e := range entries {
+				if e.Exit != nil || e.Status() != StatusDead {
+					continue
+				}
+				exit, ok := ReadExitStatus(configName, projectName, e.PID)
+				if !ok {
+					exit = unknownExit(now)
+				}
+				entries[i].Exit = &exit
+				exited = append(exited, Exited{Project: projectName, Entry: entries[i]})
+				recordExit(configName, projectName, e, exit)
+			}
+			return entries
+		})
+		if err != nil {
+			return exited, err
+		}
+	}
+	sort.SliceStable(exited, func(i, j int) bool {
+		return exited[i].Entry.Exit.ExitedAt.Before(exited[j].Entry.Exit.ExitedAt)
+	})
+	return exited, nil
+}
+
+func hasUnrecordedExit(entries []Entry) bool {
+	for _, e := range entries {
+		if e.Exit == nil && e.Status() == StatusDead {
+			return true
+		}
+	}
+	return false
+}
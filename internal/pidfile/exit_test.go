@@ -0,0 +1,121 @@
+package pidfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExitStatusRoundTrip(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	if _, ok := ReadExitStatus("cfg", "web", 100); ok {
+		t.Fatal("ReadExitStatus() should report no status before one is written")
+	}
+
+	path, err := ProcExitFilePath("cfg", "web", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logPath, _ := ProcLogFilePath("cfg", "web", 100)
+	if got := ExitFilePath(logPath, 100); got != path {
+		t.Errorf("ExitFilePath() = %q, want %q", got, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	want := ExitStatus{Code: 3, ExitedAt: time.Now().Truncate(time.Second)}
+	if err := WriteExitStatus(path, want); err != nil {
+		t.Fatalf("WriteExitStatus() error: %v", err)
+	}
+	got, ok := ReadExitStatus("cfg", "web", 100)
+	if !ok || got.Code != 3 || !got.ExitedAt.Equal(want.ExitedAt) {
+		t.Errorf("ReadExitStatus() = %+v, %v, want %+v", got, ok, want)
+	}
+}
+
+func TestExitStatusString(t *testing.T) {
+	if got := (ExitStatus{Code: 1}).String(); got != "Exited (code 1)" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (ExitStatus{Code: -1, Signal: "SIGTERM"}).String(); got != "Killed (SIGTERM)" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := unknownExit(time.Now()).String(); got != "Dead" {
+		t.Errorf("String() of an unknown exit = %q", got)
+	}
+}
+
+func TestExitStatusCrashed(t *testing.T) {
+	tests := []struct {
+		status ExitStatus
+		want   bool
+	}{
+		{ExitStatus{Code: 0}, false},
+		{ExitStatus{Code: 1}, true},
+		{ExitStatus{Code: -1, Signal: "SIGKILL"}, true},
+		{unknownExit(time.Now()), true},
+	}
+	for _, tt := range tests {
+		if got := tt.status.Crashed(); got != tt.want {
+			t.Errorf("%v.Crashed() = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRecordExits(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	started := time.Now().Add(-time.Minute).Truncate(time.Second)
+	entries := []Entry{
+		{PID: 999999999, Command: "crashed", StartedAt: started},
+		{PID: 999999998, Command: "untraced"},
+		{PID: os.Getpid(), Command: "running"},
+	}
+	if err := Save("cfg", "web", entries); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := ProcExitFilePath("cfg", "web", 999999999)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	exitedAt := started.Add(30 * time.Second)
+	if err := WriteExitStatus(path, ExitStatus{Code: 2, ExitedAt: exitedAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	exited, err := RecordExits("cfg")
+	if err != nil {
+		t.Fatalf("RecordExits() error: %v", err)
+	}
+	if len(exited) != 2 {
+		t.Fatalf("RecordExits() returned %d entries, want 2", len(exited))
+	}
+
+	saved, _ := Load("cfg", "web")
+	byPID := map[int]Entry{}
+	for _, e := range saved {
+		byPID[e.PID] = e
+	}
+	crashed := byPID[999999999]
+	if crashed.Exit == nil || crashed.Exit.Code != 2 || !crashed.Exit.ExitedAt.Equal(exitedAt) {
+		t.Errorf("recorded exit = %+v, want code 2 at %v", crashed.Exit, exitedAt)
+	}
+	if got := crashed.Runtime(time.Now()); got != 30*time.Second {
+		t.Errorf("Runtime() = %v, want 30s", got)
+	}
+	if untraced := byPID[999999998]; untraced.Exit == nil || untraced.Exit.Known() {
+		t.Errorf("exit without a status file = %+v, want an unknown exit", untraced.Exit)
+	}
+	if running := byPID[os.Getpid()]; running.Exit != nil {
+		t.Errorf("running process got an exit recorded: %+v", running.Exit)
+	}
+
+	// Each exit is reported once.
+	if exited, err := RecordExits("cfg"); err != nil || len(exited) != 0 {
+		t.Errorf("second RecordExits() = %d entries, %v, want none", len(exited), err)
+	}
+}
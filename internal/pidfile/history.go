@@ -0,0 +1,203 @@
+package pidfile
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mdc/internal/config"
+)
+
+// HistoryAction is a kind of process lifecycle event.
+type HistoryAction string
+
+const (
+	HistoryStarted   HistoryAction = "started"
+	HistoryStopped   HistoryAction = "stopped"
+	HistoryRestarted HistoryAction = "restarted"
+	HistoryExited    HistoryAction = "exited"
+)
+
+// Trigger names the mdc command whose actions are recorded in the history,
+// e.g. "up", "down" or "proc restart". The CLI sets it once per run.
+var Trigger string
+
+// HistoryEvent is a line of a config's process history. Unlike PID files
+// and proc logs, the history is kept after the processes are stopped: it is
+// only appended to.
+type HistoryEvent struct {
+	Time    time.Time     `json:"time"`
+	Project string        `json:"project"`
+	Action  HistoryAction `json:"action"`
+	PID     int           `json:"pid"`
+	Name    string        `json:"name,omitempty"`
+	Command string        `json:"command"`
+	// PrevPID is the PID of the process a restart replaced.
+	PrevPID int `json:"prev_pid,omitempty"`
+	// Exit is how the process ended, for exited events.
+	Exit *ExitStatus `json:"exit,omitempty"`
+	// By is the mdc command that caused the event. Exits have none.
+	By string `json:"by,omitempty"`
+}
+
+func historyBaseDir() (string, error) {
+	if BaseDir != "" {
+		return filepath.Join(filepath.Dir(BaseDir), "history"), nil
+	}
+	base, err := config.BaseMDCDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "history"), nil
+}
+
+// HistoryFilePath returns the history file of a config.
+// Path: ~/.config/mdc/history/<config-name>.jsonl
+func HistoryFilePath(configName string) (string, error) {
+	base, err := historyBaseDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, configName+".jsonl"), nil
+}
+
+// RecordHistory appends an event to the config's history. The history is
+// informational, so failures are only reported with Warnf.
+func RecordHistory(configName string, ev HistoryEvent) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if ev.By == "" && ev.Action != HistoryExited {
+		ev.By = Trigger
+	}
+	if err := appendHistory(configName, ev); err != nil {
+		Warnf("failed to record %s event of PID %d in the history: %v", ev.Action, ev.PID, err)
+	}
+}
+
+func appendHistory(configName string, ev HistoryEvent) error {
+	path, err := HistoryFilePath(configName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	// A single write per line, so that concurrent mdc processes do not
+	// interleave their events.
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// RecordStart records that a background process was started.
+func RecordStart(configName, projectName string, e Entry) {
+	RecordHistory(configName, HistoryEvent{Project: projectName, Action: HistoryStarted, PID: e.PID, Name: e.Name, Command: e.Command})
+}
+
+// RecordStop records that a tracked process is being stopped. A process
+// that is already gone is recorded as exited instead, unless its exit was
+// recorded before. Call it before the process is signalled.
+func RecordStop(configName, projectName string, e Entry) {
+	if RecordGone(configName, projectName, e) {
+		return
+	}
+	RecordHistory(configName, HistoryEvent{Project: projectName, Action: HistoryStopped, PID: e.PID, Name: e.Name, Command: e.Command})
+}
+
+// RecordRestart records that the process with prevPID was replaced by next.
+func RecordRestart(configName, projectName string, prevPID int, next Entry) {
+	RecordHistory(configName, HistoryEvent{Project: projectName, Action: HistoryRestarted, PID: next.PID, PrevPID: prevPID, Name: next.Name, Command: next.Command})
+}
+
+// RecordGone records the exit of a process that is no longer running,
+// unless it was already recorded (see RecordExits). It reports whether the
+// process is gone. Call it before stopping or replacing a process, so that
+// an earlier crash is not lost with its entry.
+func RecordGone(configName, projectName string, e Entry) bool {
+	if e.Status() != StatusDead {
+		return false
+	}
+	if e.Exit == nil {
+		exit, ok := e.LastExit(configName, projectName)
+		if !ok {
+			exit = unknownExit(time.Now())
+		}
+		recordExit(configName, projectName, e, exit)
+	}
+	return true
+}
+
+func recordExit(configName, projectName string, e Entry, exit ExitStatus) {
+	RecordHistory(configName, HistoryEvent{Time: exit.ExitedAt, Project: projectName, Action: HistoryExited, PID: e.PID, Name: e.Name, Command: e.Command, Exit: &exit})
+}
+
+// LoadHistory reads the history of a config, oldest first. A config without
+// history returns no events. Lines that cannot be parsed, e.g. cut short by
+// a full disk, are skipped.
+func LoadHistory(configName string) ([]HistoryEvent, error) {
+	path, err := HistoryFilePath(configName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var events []HistoryEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev HistoryEvent
+		if json.Unmarshal(scanner.Bytes(), &ev) == nil {
+			events = append(events, ev)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return events, err
+	}
+	// Exits are recorded when they are noticed, with the time they happened.
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
+
+// HistoryConfigNames returns the names of all configs that have a history.
+func HistoryConfigNames() ([]string, error) {
+	base, err := historyBaseDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".jsonl") {
+			names = append(names, strings.TrimSuffix(e.Name(), ".jsonl"))
+		}
+	}
+	return names, nil
+}
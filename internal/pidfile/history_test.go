@@ -0,0 +1,88 @@
+package pidfile
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+	oldTrigger := Trigger
+	Trigger = "up"
+	defer func() { Trigger = oldTrigger }()
+
+	running := Entry{PID: os.Getpid(), Command: "npm run dev"}
+	RecordStart("cfg", "web", running)
+	Trigger = "proc restart"
+	RecordRestart("cfg", "web", 100, running)
+	Trigger = "down"
+	RecordStop("cfg", "web", running)
+	// A process that died before it was stopped is recorded as exited.
+	RecordStop("cfg", "api", Entry{PID: 999999999, Command: "go run ."})
+	// An exit that was already recorded is not recorded again.
+	RecordStop("cfg", "api", Entry{PID: 999999998, Command: "go run .", Exit: &ExitStatus{Code: 1, ExitedAt: time.Now()}})
+
+	path, _ := HistoryFilePath("cfg")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("{\"time\": \"cut short\n")
+	_ = f.Close()
+
+	events, err := LoadHistory("cfg")
+	if err != nil {
+		t.Fatalf("LoadHistory() error: %v", err)
+	}
+	want := []struct {
+		action HistoryAction
+		pid    int
+		by     string
+	}{
+		{HistoryStarted, os.Getpid(), "up"},
+		{HistoryRestarted, os.Getpid(), "proc restart"},
+		{HistoryStopped, os.Getpid(), "down"},
+		{HistoryExited, 999999999, ""},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("LoadHistory() returned %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		ev := events[i]
+		if ev.Action != w.action || ev.PID != w.pid || ev.By != w.by {
+			t.Errorf("event %d = %s PID %d by %q, want %s PID %d by %q", i, ev.Action, ev.PID, ev.By, w.action, w.pid, w.by)
+		}
+	}
+	if events[1].PrevPID != 100 {
+		t.Errorf("restarted PrevPID = %d, want 100", events[1].PrevPID)
+	}
+	if exit := events[3].Exit; exit == nil || exit.Known() {
+		t.Errorf("exit of a process without status = %+v, want unknown", exit)
+	}
+
+	names, err := HistoryConfigNames()
+	if err != nil || len(names) != 1 || names[0] != "cfg" {
+		t.Errorf("HistoryConfigNames() = %v, %v, want [cfg]", names, err)
+	}
+}
+
+func TestHistorySurvivesKillAll(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	if err := Save("cfg", "web", []Entry{{PID: 999999999, Command: "fake"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RecordExits("cfg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := KillAll("cfg"); err != nil {
+		t.Fatal(err)
+	}
+	events, err := LoadHistory("cfg")
+	if err != nil || len(events) != 1 || events[0].Action != HistoryExited {
+		t.Errorf("LoadHistory() after KillAll = %+v, %v, want the exit", events, err)
+	}
+}
@@ -0,0 +1,112 @@
+package pidfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mdc/internal/config"
+)
+
+// Identity describes a process well enough to tell it apart from an
+// unrelated process that later reuses its PID, e.g. after a reboot.
+type Identity struct {
+	// StartTime is the start time of the process in clock ticks since boot,
+	// as reported by /proc/<pid>/stat (Linux only).
+	StartTime uint64 `json:"start_time,omitempty"`
+	// PGID is the process group ID.
+	PGID int `json:"pgid,omitempty"`
+	// Exe is the path of the executable (Linux only).
+	Exe string `json:"exe,omitempty"`
+}
+
+// matches reports whether cur, the identity of the process now using the
+// PID, can be the recorded process. Fields that were not recorded (entries
+// written by older versions) or cannot be read now are not compared.
+func (id Identity) matches(cur Identity) bool {
+	if id.StartTime != 0 && cur.StartTime != 0 && id.StartTime != cur.StartTime {
+		return false
+	}
+	if id.PGID != 0 && cur.PGID != 0 && id.PGID != cur.PGID {
+		return false
+	}
+	if id.Exe != "" && cur.Exe != "" && trimDeleted(id.Exe) != trimDeleted(cur.Exe) {
+		return false
+	}
+	return true
+}
+
+// trimDeleted drops the marker Linux appends to the executable link when
+// the binary has been replaced, e.g. by a package upgrade.
+func trimDeleted(exe string) string {
+	return strings.TrimSuffix(exe, " (deleted)")
+}
+
+// Status is the state of a tracked process.
+type Status string
+
+const (
+	StatusRunning Status = "Running"
+	StatusDead    Status = "Dead"
+	// StatusStale means the PID is alive but belongs to another process.
+	StatusStale Status = "Stale"
+)
+
+// Status checks whether the entry's process is still running and is still
+// the process that was started. An entry with a recorded exit is dead
+// without looking at the PID, which may have been reused since.
+func (e Entry) Status() Status {
+	if e.Exit != nil || !IsRunning(e.PID) {
+		return StatusDead
+	}
+	if !e.Identity.matches(Identify(e.PID)) {
+		return StatusStale
+	}
+	return StatusRunning
+}
+
+// ErrStale is returned when a tracked PID now belongs to another process.
+var ErrStale = errors.New("PID was reused by another process; not signalling it")
+
+// KillEntry stops the entry's process and all of its descendants, after
+// verifying that the PID still belongs to it: the stop signal goes to the
+// whole tree and whatever is still alive after the grace period is killed.
+// Those processes are returned. A stale entry returns ErrStale and nothing
+// is signalled.
+func KillEntry(e Entry) ([]Survivor, error) {
+	switch e.Status() {
+	case StatusDead:
+		return nil, nil
+	case StatusStale:
+		return nil, ErrStale
+	}
+	return killTree(e.PID, e.StopSignalName(), e.StopGracePeriod()), nil
+}
+
+// StopSignalName and StopGracePeriod apply the defaults of
+// config.CommandItem to the recorded stop settings.
+func (e Entry) StopSignalName() string {
+	return config.CommandItem{StopSignal: e.StopSignal}.StopSignalName()
+}
+
+func (e Entry) StopGracePeriod() time.Duration {
+	return config.CommandItem{StopTimeout: config.Duration(e.StopTimeout)}.StopGracePeriod()
+}
+
+// Survivor is a process that was still alive at the end of the grace period
+// and had to be killed.
+type Survivor struct {
+	PID     int
+	Command string
+}
+
+// SurvivorMessage describes the processes of an entry that ignored the stop
+// signal.
+func SurvivorMessage(e Entry, survivors []Survivor) string {
+	names := make([]string, len(survivors))
+	for i, s := range survivors {
+		names[i] = fmt.Sprintf("%d (%s)", s.PID, s.Command)
+	}
+	return fmt.Sprintf("killed after %s without exiting on %s: PID %s", e.StopGracePeriod(), e.StopSignalName(), strings.Join(names, ", "))
+}
@@ -0,0 +1,46 @@
+package pidfile
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Identify reads the identity of a running process from /proc. Fields that
+// cannot be read are left empty.
+func Identify(pid int) Identity {
+	var id Identity
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		id.StartTime, id.PGID = parseStat(string(data))
+	}
+	if exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); err == nil {
+		id.Exe = exe
+	}
+	return id
+}
+
+// parseStat extracts the start time and process group from the content of
+// /proc/<pid>/stat.
+func parseStat(stat string) (startTime uint64, pgid int) {
+	_, fields := splitStat(stat)
+	if len(fields) < 20 {
+		return 0, 0
+	}
+	pgid, _ = strconv.Atoi(fields[2])
+	startTime, _ = strconv.ParseUint(fields[19], 10, 64)
+	return startTime, pgid
+}
+
+// splitStat splits /proc/<pid>/stat into the command name and the fields
+// that follow it; fields[0] is field 3 (state). The command name is in
+// parentheses and may itself contain spaces and parentheses, so fields are
+// counted from the last closing parenthesis.
+func splitStat(stat string) (comm string, fields []string) {
+	open := strings.IndexByte(stat, '(')
+	i := strings.LastIndexByte(stat, ')')
+	if open < 0 || i < open {
+		return "", nil
+	}
+	return stat[open+1 : i], strings.Fields(stat[i+1:])
+}
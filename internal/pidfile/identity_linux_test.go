@@ -0,0 +1,45 @@
+package pidfile
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseStat(t *testing.T) {
+	tests := []struct {
+		name      string
+		stat      string
+		wantStart uint64
+		wantPGID  int
+	}{
+		{
+			name:      "plain",
+			stat:      "1234 (sleep) S 1 1234 1234 0 -1 4194304 94 0 0 0 0 0 0 0 20 0 1 0 987654 2592768 128",
+			wantStart: 987654,
+			wantPGID:  1234,
+		},
+		{
+			name:      "command with spaces and parentheses",
+			stat:      "42 (my (odd) cmd) R 1 40 40 0 -1 0 0 0 0 0 0 0 0 0 20 0 1 0 5555 0 0",
+			wantStart: 5555,
+			wantPGID:  40,
+		},
+		{name: "truncated", stat: "42 (sh) S 1 40"},
+		{name: "garbage", stat: "not a stat line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, pgid := parseStat(tt.stat)
+			if start != tt.wantStart || pgid != tt.wantPGID {
+				t.Errorf("parseStat() = %d, %d; want %d, %d", start, pgid, tt.wantStart, tt.wantPGID)
+			}
+		})
+	}
+}
+
+func TestIdentifyLinux(t *testing.T) {
+	id := Identify(os.Getpid())
+	if id.StartTime == 0 || id.PGID == 0 || id.Exe == "" {
+		t.Errorf("Identify(self) = %+v, want all fields set", id)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !windows
+
+package pidfile
+
+import "syscall"
+
+// Identify returns the process group of a running process. Start time and
+// executable are only available on Linux.
+func Identify(pid int) Identity {
+	pgid, err := syscall.Getpgid(pid)
+	if err != nil {
+		return Identity{}
+	}
+	return Identity{PGID: pgid}
+}
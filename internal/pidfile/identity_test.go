@@ -0,0 +1,74 @@
+package pidfile
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+func TestIdentityMatches(t *testing.T) {
+	recorded := Identity{StartTime: 100, PGID: 10, Exe: "/usr/bin/script"}
+	tests := []struct {
+		name string
+		cur  Identity
+		want bool
+	}{
+		{name: "same", cur: recorded, want: true},
+		{name: "other start time", cur: Identity{StartTime: 200, PGID: 10, Exe: "/usr/bin/script"}, want: false},
+		{name: "other group", cur: Identity{StartTime: 100, PGID: 11, Exe: "/usr/bin/script"}, want: false},
+		{name: "other executable", cur: Identity{StartTime: 100, PGID: 10, Exe: "/usr/bin/bash"}, want: false},
+		{name: "upgraded executable", cur: Identity{StartTime: 100, PGID: 10, Exe: "/usr/bin/script (deleted)"}, want: true},
+		{name: "unreadable fields", cur: Identity{}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recorded.matches(tt.cur); got != tt.want {
+				t.Errorf("matches(%+v) = %v, want %v", tt.cur, got, tt.want)
+			}
+		})
+	}
+
+	if !(Identity{}).matches(Identity{StartTime: 1, PGID: 2, Exe: "x"}) {
+		t.Error("an entry without a recorded identity should match any process")
+	}
+}
+
+func TestEntryStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process identity is not recorded on Windows")
+	}
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pid := cmd.Process.Pid
+	defer func() { _ = cmd.Process.Kill(); _ = cmd.Wait() }()
+
+	entry := Entry{PID: pid, Command: "sleep 30", Identity: Identify(pid)}
+	if got := entry.Status(); got != StatusRunning {
+		t.Errorf("Status() = %s, want Running", got)
+	}
+
+	// Simulate the PID being reused by a process started later.
+	stale := entry
+	stale.PGID++
+	stale.StartTime++
+	if got := stale.Status(); got != StatusStale {
+		t.Errorf("Status() of a reused PID = %s, want Stale", got)
+	}
+	if _, err := KillEntry(stale); !errors.Is(err, ErrStale) {
+		t.Errorf("KillEntry(stale) = %v, want ErrStale", err)
+	}
+	if !IsRunning(pid) {
+		t.Fatal("a stale entry must not signal the process")
+	}
+
+	if _, err := KillEntry(entry); err != nil {
+		t.Fatalf("KillEntry() error: %v", err)
+	}
+	_ = cmd.Wait()
+	if got := entry.Status(); got != StatusDead {
+		t.Errorf("Status() after kill = %s, want Dead", got)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package pidfile
+
+// Identify is not supported on Windows; entries are verified by PID only.
+func Identify(pid int) Identity {
+	return Identity{}
+}
@@ -0,0 +1,129 @@
+package pidfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Warnf reports recoverable problems such as a quarantined PID file. It can
+// be replaced, e.g. in tests.
+var Warnf = func(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "⚠️  Warning: "+format+"\n", args...)
+}
+
+// lockPath returns the lock file guarding a config's PID files. It lives
+// next to the config's PID directory rather than inside it, because that
+// directory is removed when its last entry goes away.
+func lockPath(configName string) (string, error) {
+	base, err := baseDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "."+configName+".lock"), nil
+}
+
+// withLock runs fn while holding an exclusive advisory lock on the config's
+// PID files. The lock serializes read-modify-write cycles across goroutines
+// and mdc processes alike. It is not reentrant: fn must use the unlocked
+// helpers (load, save) rather than the exported functions.
+func withLock(configName string, fn func() error) error {
+	path, err := lockPath(configName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	defer func() { _ = unlockFile(f) }()
+	return fn()
+}
+
+// update applies fn to a project's entries under the config lock. An empty
+// result removes the PID file.
+func update(configName, projectName string, fn func([]Entry) []Entry) error {
+	return withLock(configName, func() error {
+		entries, err := load(configName, projectName)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		entries = fn(entries)
+		if len(entries) == 0 {
+			path, err := filePath(configName, projectName)
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return removeEmptyConfigDir(configName)
+		}
+		return save(configName, projectName, entries)
+	})
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it into place, so that readers never see a partially written file.
+// The file gets the permissions perm.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// readEntries reads a PID file. A file that cannot be parsed is moved aside
+// to "<file>.corrupt-<timestamp>" and treated as empty, so that one broken
+// file does not make every config unreadable. The caller must hold the
+// config lock.
+func readEntries(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		quarantine := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102T150405"))
+		if renameErr := os.Rename(path, quarantine); renameErr != nil {
+			return nil, fmt.Errorf("corrupt PID file %s: %w", path, err)
+		}
+		Warnf("corrupt PID file %s moved to %s (%v); its processes are no longer tracked", path, quarantine, err)
+		return nil, nil
+	}
+	return entries, nil
+}
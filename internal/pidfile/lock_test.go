@@ -0,0 +1,190 @@
+package pidfile
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const helperEnv = "MDC_PIDFILE_HELPER"
+
+func TestConcurrentAppendGoroutines(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	const workers, perWorker = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				if err := Append("cfg", "web", Entry{PID: w*1000 + i, Command: "cmd"}); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Append() error: %v", err)
+	}
+
+	entries, err := Load("cfg", "web")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(entries) != workers*perWorker {
+		t.Errorf("got %d entries, want %d", len(entries), workers*perWorker)
+	}
+}
+
+func TestConcurrentAppendAndRemove(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	for i := 0; i < 50; i++ {
+		if err := Append("cfg", "web", Entry{PID: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(pid int) {
+			defer wg.Done()
+			if err := RemoveEntry("cfg", "web", pid); err != nil {
+				t.Errorf("RemoveEntry() error: %v", err)
+			}
+		}(i)
+		go func(pid int) {
+			defer wg.Done()
+			if err := Append("cfg", "api", Entry{PID: 100 + pid}); err != nil {
+				t.Errorf("Append() error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	all, err := LoadAll("cfg")
+	if err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+	if len(all["web"]) != 0 {
+		t.Errorf("web entries = %v, want none", all["web"])
+	}
+	if len(all["api"]) != 50 {
+		t.Errorf("got %d api entries, want 50", len(all["api"]))
+	}
+}
+
+// TestHelperAppend is run as a subprocess by TestConcurrentAppendProcesses.
+func TestHelperAppend(t *testing.T) {
+	if os.Getenv(helperEnv) == "" {
+		t.Skip("helper process")
+	}
+	BaseDir = os.Getenv(helperEnv)
+	worker, _ := strconv.Atoi(os.Getenv(helperEnv + "_WORKER"))
+	for i := 0; i < 25; i++ {
+		if err := Append("cfg", "web", Entry{PID: worker*1000 + i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConcurrentAppendProcesses(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	const workers = 4
+	cmds := make([]*exec.Cmd, workers)
+	for w := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperAppend$")
+		cmd.Env = append(os.Environ(), helperEnv+"="+BaseDir, fmt.Sprintf("%s_WORKER=%d", helperEnv, w))
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		cmds[w] = cmd
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper process failed: %v", err)
+		}
+	}
+
+	entries, err := Load("cfg", "web")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(entries) != workers*25 {
+		t.Errorf("got %d entries, want %d", len(entries), workers*25)
+	}
+
+	dir, _ := Dir("cfg")
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		if strings.Contains(f.Name(), ".tmp-") {
+			t.Errorf("temporary file left behind: %s", f.Name())
+		}
+	}
+}
+
+func TestCorruptFileIsQuarantined(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	var warnings []string
+	oldWarnf := Warnf
+	Warnf = func(format string, args ...any) { warnings = append(warnings, fmt.Sprintf(format, args...)) }
+	defer func() { Warnf = oldWarnf }()
+
+	if err := Save("cfg", "api", []Entry{{PID: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save("other", "web", []Entry{{PID: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := filePath("cfg", "web")
+	if err := os.WriteFile(path, []byte(`[{"pid": 12`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := LoadAllConfigs()
+	if err != nil {
+		t.Fatalf("LoadAllConfigs() error: %v", err)
+	}
+	if len(all["cfg"]["api"]) != 1 || len(all["other"]["web"]) != 1 {
+		t.Errorf("healthy files should still load, got %v", all)
+	}
+	if _, ok := all["cfg"]["web"]; ok {
+		t.Errorf("corrupt file should be skipped, got %v", all["cfg"]["web"])
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "corrupt PID file") {
+		t.Errorf("warnings = %v, want one corrupt file warning", warnings)
+	}
+
+	matches, _ := filepath.Glob(path + ".corrupt-*")
+	if len(matches) != 1 {
+		t.Fatalf("quarantined files = %v, want 1", matches)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("corrupt file should be moved away, err = %v", err)
+	}
+
+	// The project can be used again right away.
+	if err := Append("cfg", "web", Entry{PID: 3}); err != nil {
+		t.Fatalf("Append() after quarantine error: %v", err)
+	}
+	entries, err := Load("cfg", "web")
+	if err != nil || len(entries) != 1 || entries[0].PID != 3 {
+		t.Errorf("Load() = %v, %v; want the new entry only", entries, err)
+	}
+}
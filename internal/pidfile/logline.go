@@ -0,0 +1,131 @@
+package pidfile
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+)
+
+// Each line of a proc log written by the exec helper starts with the time it
+// was written, in UTC with millisecond precision, followed by a space. Logs
+// written by earlier versions have no timestamps; readers accept both.
+const logTimeLayout = "2006-01-02T15:04:05.000Z"
+
+// logTimePrefixLen is the length of the timestamp including the space.
+const logTimePrefixLen = len(logTimeLayout) + 1
+
+// TimestampWriter prefixes every line written to the underlying writer with
+// the current time.
+type TimestampWriter struct {
+	w       io.Writer
+	now     func() time.Time
+	midLine bool
+}
+
+func NewTimestampWriter(w io.Writer) *TimestampWriter {
+	return &TimestampWriter{w: w, now: time.Now}
+}
+
+func (t *TimestampWriter) Write(p []byte) (int, error) {
+	stamp := t.now().UTC().Format(logTimeLayout) + " "
+	buf := make([]byte, 0, len(p)+logTimePrefixLen)
+	for rest := p; len(rest) > 0; {
+		if !t.midLine {
+			buf = append(buf, stamp...)
+			t.midLine = true
+		}
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			buf = append(buf, rest...)
+			break
+		}
+		buf = append(buf, rest[:i+1]...)
+		rest = rest[i+1:]
+		t.midLine = false
+	}
+	if _, err := t.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// SplitLogLine separates the timestamp from a proc log line. ok is false
+// when the line has no timestamp.
+func SplitLogLine(line string) (t time.Time, rest string, ok bool) {
+	if !hasLogTimestamp(line) {
+		return time.Time{}, line, false
+	}
+	t, _ = time.Parse(logTimeLayout, line[:logTimePrefixLen-1])
+	return t, line[logTimePrefixLen:], true
+}
+
+func hasLogTimestamp(line string) bool {
+	if len(line) < logTimePrefixLen || line[logTimePrefixLen-1] != ' ' {
+		return false
+	}
+	_, err := time.Parse(logTimeLayout, line[:logTimePrefixLen-1])
+	return err == nil
+}
+
+// StripLogTimestamps removes the timestamps from proc log content.
+func StripLogTimestamps(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		_, lines[i], _ = SplitLogLine(line)
+	}
+	return strings.Join(lines, "")
+}
+
+// TimestampStripper removes the timestamps from a stream of proc log content
+// written to it in arbitrary chunks.
+type TimestampStripper struct {
+	w io.Writer
+	// pending holds the start of a line until it is known whether it is a
+	// timestamp.
+	pending []byte
+	midLine bool
+}
+
+func NewTimestampStripper(w io.Writer) *TimestampStripper {
+	return &TimestampStripper{w: w}
+}
+
+func (s *TimestampStripper) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		if s.midLine {
+			out = append(out, b)
+			s.midLine = b != '\n'
+			continue
+		}
+		s.pending = append(s.pending, b)
+		switch {
+		case b == '\n':
+			out = append(out, s.pending...)
+		case len(s.pending) == logTimePrefixLen:
+			if !hasLogTimestamp(string(s.pending)) {
+				out = append(out, s.pending...)
+			}
+			s.midLine = true
+		default:
+			continue
+		}
+		s.pending = s.pending[:0]
+	}
+	if _, err := s.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the start of a line that is too short to be a timestamp.
+func (s *TimestampStripper) Flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	_, err := s.w.Write(s.pending)
+	s.pending = s.pending[:0]
+	s.midLine = true
+	return err
+}
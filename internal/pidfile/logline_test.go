@@ -0,0 +1,62 @@
+package pidfile
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTimestampWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewTimestampWriter(&buf)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 6e6, time.FixedZone("JST", 9*3600))
+	w.now = func() time.Time { return now }
+
+	_, _ = w.Write([]byte("first\r\nsec"))
+	_, _ = w.Write([]byte("ond\n\nthird"))
+	want := "2026-01-01T18:04:05.006Z first\r\n" +
+		"2026-01-01T18:04:05.006Z second\n" +
+		"2026-01-01T18:04:05.006Z \n" +
+		"2026-01-01T18:04:05.006Z third"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestSplitLogLine(t *testing.T) {
+	ts, rest, ok := SplitLogLine("2026-01-01T18:04:05.006Z \x1b[32mready\x1b[0m")
+	if !ok || rest != "\x1b[32mready\x1b[0m" || !ts.Equal(time.Date(2026, 1, 1, 18, 4, 5, 6e6, time.UTC)) {
+		t.Errorf("SplitLogLine() = %v, %q, %v", ts, rest, ok)
+	}
+	for _, line := range []string{"", "ready", "2026-01-01T18:04:05Z ready", "2026-13-01T18:04:05.006Z ready"} {
+		if _, rest, ok := SplitLogLine(line); ok || rest != line {
+			t.Errorf("SplitLogLine(%q) = %q, %v, want the line unchanged", line, rest, ok)
+		}
+	}
+}
+
+func TestStripLogTimestamps(t *testing.T) {
+	in := "2026-01-01T18:04:05.006Z one\nplain two\n2026-01-01T18:04:06.000Z three"
+	if got, want := StripLogTimestamps(in), "one\nplain two\nthree"; got != want {
+		t.Errorf("StripLogTimestamps() = %q, want %q", got, want)
+	}
+}
+
+func TestTimestampStripper(t *testing.T) {
+	in := "2026-01-01T18:04:05.006Z one\r\nplain line without stamp\nshort\n2026-01-01T18:04:06.000Z two\nend"
+	want := "one\r\nplain line without stamp\nshort\ntwo\nend"
+
+	// Byte by byte, so that every timestamp is split across writes.
+	var buf bytes.Buffer
+	s := NewTimestampStripper(&buf)
+	for i := 0; i < len(in); i++ {
+		_, _ = s.Write([]byte{in[i]})
+	}
+	if got := buf.String(); got != "one\r\nplain line without stamp\nshort\ntwo\n" {
+		t.Errorf("before Flush = %q", got)
+	}
+	_ = s.Flush()
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
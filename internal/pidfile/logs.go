@@ -0,0 +1,308 @@
+package pidfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"mdc/internal/config"
+)
+
+// SupervisorLogName is the log of a detached "mdc supervise", kept in the
+// config's proc log directory.
+const SupervisorLogName = "_supervisor.log"
+
+const pendingLogName = "_pending.log"
+
+// logFileRe matches the names of process logs ("<pid>.log").
+var logFileRe = regexp.MustCompile(`^\d+\.log$`)
+
+// exitFileRe matches the names of exit status files ("<pid>.exit").
+var exitFileRe = regexp.MustCompile(`^(\d+)\.exit$`)
+
+// segmentPath returns the path of the n-th rotated segment of a log.
+// Segment 1 is the most recent.
+func segmentPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// LogRotation holds the limits at which a LogWriter rotates its log. It is
+// recorded with each process so that restarts keep rotating the same way.
+type LogRotation struct {
+	MaxSize  int64 `json:"max_size,omitempty"`
+	MaxFiles int   `json:"max_files,omitempty"`
+}
+
+// RotationOf returns the rotation limits of the given log settings.
+func RotationOf(lc config.LogConfig) LogRotation {
+	return LogRotation{MaxSize: lc.MaxSizeBytes(), MaxFiles: lc.MaxFileCount()}
+}
+
+// config returns the limits as log settings, so that unset limits fall back
+// to the defaults.
+func (r LogRotation) config() config.LogConfig {
+	return config.LogConfig{MaxSize: config.ByteSize(r.MaxSize), MaxFiles: r.MaxFiles}
+}
+
+// TrackedLogPath returns the name of the log written to logFile once the
+// process with the given PID is tracked: process logs, including pending
+// ones, are renamed after the PID (see RenameProcLog), other logs such as
+// the supervisor log keep their name.
+func TrackedLogPath(logFile string, pid int) string {
+	name := filepath.Base(logFile)
+	if name == pendingLogName || logFileRe.MatchString(name) {
+		return filepath.Join(filepath.Dir(logFile), fmt.Sprintf("%d.log", pid))
+	}
+	return logFile
+}
+
+// LogWriter appends to a log and rotates it when it exceeds the maximum
+// size: the log is renamed to "<log>.1" (older segments are shifted, the
+// oldest beyond max_files is removed) and a new log is opened in its place.
+// As the writer owns the file, no output is lost across a rotation.
+type LogWriter struct {
+	path     string
+	rotation config.LogConfig
+	f        *os.File
+	size     int64
+}
+
+// OpenLogWriter opens file for appending. The log is rotated under path,
+// which differs from file while a process log is still pending: rotation
+// waits until the spawner has renamed the file to path.
+func OpenLogWriter(file, path string, rotation LogRotation) (*LogWriter, error) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &LogWriter{path: path, rotation: rotation.config(), f: f, size: info.Size()}, nil
+}
+
+// Write appends p to the log and rotates it when it has grown too large.
+// A failed rotation is retried on the next write.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	if err == nil && w.size > w.rotation.MaxSizeBytes() {
+		_ = w.rotate()
+	}
+	return n, err
+}
+
+// Close closes the log.
+func (w *LogWriter) Close() error {
+	return w.f.Close()
+}
+
+func (w *LogWriter) rotate() error {
+	current, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	info, err := w.f.Stat()
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(current, info) {
+		return fmt.Errorf("%s is not the log being written", w.path)
+	}
+
+	maxFiles := w.rotation.MaxFileCount()
+	_ = os.Remove(segmentPath(w.path, maxFiles))
+	for n := maxFiles - 1; n >= 1; n-- {
+		if err := os.Rename(segmentPath(w.path, n), segmentPath(w.path, n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(w.path, segmentPath(w.path, 1)); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		// Keep writing to the renamed segment rather than losing output.
+		return err
+	}
+	_ = w.f.Close()
+	w.f, w.size = f, 0
+	return nil
+}
+
+// LogSegments returns the existing rotated segments of the log at path,
+// oldest first, followed by the log itself when it exists.
+func LogSegments(path string) []string {
+	matches, _ := filepath.Glob(path + ".*")
+	type segment struct {
+		path string
+		n    int
+	}
+	var segments []segment
+	for _, m := range matches {
+		n, err := strconv.Atoi(m[len(path)+1:])
+		if err != nil || n < 1 {
+			continue
+		}
+		segments = append(segments, segment{path: m, n: n})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].n > segments[j].n })
+
+	result := make([]string, 0, len(segments)+1)
+	for _, s := range segments {
+		result = append(result, s.path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		result = append(result, path)
+	}
+	return result
+}
+
+// PruneOptions configures PruneLogs.
+type PruneOptions struct {
+	// Logs holds the max_files setting.
+	Logs config.LogConfig
+	// MaxAge is the retention age: older rotated segments and logs of
+	// untracked processes are removed. Zero removes them all.
+	MaxAge time.Duration
+	// DryRun reports what would be done without changing anything.
+	DryRun bool
+}
+
+// PruneResult lists what PruneLogs did (or would do in a dry run).
+type PruneResult struct {
+	Removed []string
+	// Freed is the total size of the removed files in bytes.
+	Freed int64
+}
+
+// segmentRe matches rotated segments such as "123.log.2".
+var segmentRe = regexp.MustCompile(`^(.+\.log)\.(\d+)$`)
+
+// PruneLogs enforces the log policy for one config:
+//
+//   - rotated segments of the logs of running processes and of the
+//     supervisor log are removed beyond max_files or when older than
+//     MaxAge (the logs themselves are rotated by their writer, see
+//     LogWriter);
+//   - logs and exit status files of processes that are no longer tracked
+//     (e.g. stopped with "mdc proc stop") and stale pending logs are removed
+//     once they are older than MaxAge.
+func PruneLogs(configName string, opts PruneOptions) (PruneResult, error) {
+	var result PruneResult
+
+	logDir, err := ProcLogDir(configName)
+	if err != nil {
+		return result, err
+	}
+	tracked, err := LoadAll(configName)
+	if err != nil {
+		return result, err
+	}
+
+	// live reports whether a log (or its segments) belongs to a process
+	// that is still tracked, keyed by the log's base name.
+	live := map[string]bool{filepath.Join(logDir, SupervisorLogName): true}
+	for projectName, entries := range tracked {
+		for _, e := range entries {
+			live[filepath.Join(logDir, projectName, fmt.Sprintf("%d.log", e.PID))] = true
+		}
+	}
+
+	cutoff := time.Now().Add(-opts.MaxAge)
+	err = filepath.WalkDir(logDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		old := !info.ModTime().After(cutoff)
+
+		var remove bool
+		if m := segmentRe.FindStringSubmatch(path); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			if live[m[1]] {
+				remove = n > opts.Logs.MaxFileCount() || old
+			} else {
+				remove = old
+			}
+		} else if d.Name() == pendingLogName || logFileRe.MatchString(d.Name()) {
+			remove = !live[path] && old
+		} else if m := exitFileRe.FindStringSubmatch(d.Name()); m != nil {
+			remove = !live[filepath.Join(filepath.Dir(path), m[1]+".log")] && old
+		}
+		if !remove {
+			return nil
+		}
+		if !opts.DryRun {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+		result.Removed = append(result.Removed, path)
+		result.Freed += info.Size()
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if !opts.DryRun {
+		removeEmptyDirs(logDir)
+	}
+	sort.Strings(result.Removed)
+	return result, nil
+}
+
+// removeEmptyDirs removes the project directories under logDir, and logDir
+// itself, when they are empty.
+func removeEmptyDirs(logDir string) {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			// Only succeeds when the directory is empty.
+			_ = os.Remove(filepath.Join(logDir, e.Name()))
+		}
+	}
+	_ = os.Remove(logDir)
+}
+
+// LogConfigNames returns the names of all configs that have a proc log
+// directory.
+func LogConfigNames() ([]string, error) {
+	base, err := procBaseDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
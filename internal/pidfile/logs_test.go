@@ -0,0 +1,229 @@
+package pidfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mdc/internal/config"
+)
+
+func writeLog(t *testing.T, path, content string, age time.Duration) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if age > 0 {
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLogWriterRotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "100.log")
+	w, err := OpenLogWriter(path, path, LogRotation{MaxSize: 10, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+
+	if _, err := w.Write([]byte("small")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Fatalf("log below max_size should not be rotated, err = %v", err)
+	}
+
+	for _, content := range []string{"-first-segment", "second-segment", "third-segment"} {
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		if got := readFile(t, path); got != "" {
+			t.Errorf("log after rotation = %q, want empty", got)
+		}
+	}
+	if _, err := w.Write([]byte("after")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, path); got != "after" {
+		t.Errorf("log = %q, want the writes after the last rotation", got)
+	}
+	if got := readFile(t, path+".1"); got != "third-segment" {
+		t.Errorf("segment 1 = %q, want newest content", got)
+	}
+	if got := readFile(t, path+".2"); got != "second-segment" {
+		t.Errorf("segment 2 = %q, want previous content", got)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("segment 3 should not exist with max_files 2, err = %v", err)
+	}
+}
+
+func TestLogWriterWaitsForRename(t *testing.T) {
+	dir := t.TempDir()
+	pending := filepath.Join(dir, "_pending.log")
+	path := TrackedLogPath(pending, 100)
+	if path != filepath.Join(dir, "100.log") {
+		t.Fatalf("TrackedLogPath() = %q, want 100.log", path)
+	}
+	w, err := OpenLogWriter(pending, path, LogRotation{MaxSize: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+
+	if _, err := w.Write([]byte("before rename\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, pending); got != "before rename\n" {
+		t.Fatalf("pending log = %q, want it unrotated", got)
+	}
+
+	if err := os.Rename(pending, path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("after\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path+".1"); got != "before rename\nafter\n" {
+		t.Errorf("segment 1 = %q, want all output so far", got)
+	}
+	if got := readFile(t, path); got != "" {
+		t.Errorf("log after rotation = %q, want empty", got)
+	}
+}
+
+func TestTrackedLogPath(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		logFile string
+		want    string
+	}{
+		{filepath.Join(dir, "_pending.log"), filepath.Join(dir, "200.log")},
+		{filepath.Join(dir, "100.log"), filepath.Join(dir, "200.log")},
+		{filepath.Join(dir, SupervisorLogName), filepath.Join(dir, SupervisorLogName)},
+	}
+	for _, tt := range tests {
+		if got := TrackedLogPath(tt.logFile, 200); got != tt.want {
+			t.Errorf("TrackedLogPath(%q) = %q, want %q", tt.logFile, got, tt.want)
+		}
+	}
+}
+
+func TestLogSegments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "100.log")
+	for _, name := range []string{"100.log", "100.log.1", "100.log.2", "100.log.10", "100.log.tmp"} {
+		writeLog(t, filepath.Join(dir, name), name, 0)
+	}
+
+	got := LogSegments(path)
+	want := []string{path + ".10", path + ".2", path + ".1", path}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("LogSegments() = %v, want %v", got, want)
+	}
+}
+
+func TestPruneLogs(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	if err := Save("cfg", "web", []Entry{{PID: 100, Command: "npm run dev"}}); err != nil {
+		t.Fatal(err)
+	}
+	logDir, err := ProcLogDir("cfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	week := 8 * 24 * time.Hour
+
+	files := map[string]time.Duration{
+		"web/100.log":       0,    // running: kept
+		"web/100.log.1":     0,    // recent segment: kept
+		"web/100.log.2":     week, // old segment: removed
+		"web/90.log":        0,    // stopped recently: kept
+		"web/80.log":        week, // stopped long ago: removed
+		"web/80.log.1":      week, // its segment: removed
+		"web/80.exit":       week, // its exit status: removed
+		"web/100.exit":      week, // status of a tracked PID: kept
+		"web/_pending.log":  week, // stale pending log: removed
+		"api/70.log":        week, // untracked project: removed
+		"_supervisor.log":   0,    // small: kept
+		"_supervisor.log.5": 0,    // beyond max_files: removed
+	}
+	for name, age := range files {
+		writeLog(t, filepath.Join(logDir, name), "x", age)
+	}
+
+	opts := PruneOptions{
+		Logs:   config.LogConfig{MaxFiles: 3},
+		MaxAge: 7 * 24 * time.Hour,
+		DryRun: true,
+	}
+	dry, err := PruneLogs("cfg", opts)
+	if err != nil {
+		t.Fatalf("PruneLogs(dry-run) error: %v", err)
+	}
+	if len(dry.Removed) != 7 {
+		t.Errorf("dry run: removed %v, want 7 files", dry.Removed)
+	}
+	for name := range files {
+		if _, err := os.Stat(filepath.Join(logDir, name)); err != nil {
+			t.Errorf("dry run should not touch %s: %v", name, err)
+		}
+	}
+
+	opts.DryRun = false
+	result, err := PruneLogs("cfg", opts)
+	if err != nil {
+		t.Fatalf("PruneLogs() error: %v", err)
+	}
+
+	wantGone := []string{"web/100.log.2", "web/80.log", "web/80.log.1", "web/80.exit", "web/_pending.log", "api/70.log", "_supervisor.log.5"}
+	for _, name := range wantGone {
+		if _, err := os.Stat(filepath.Join(logDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed, err = %v", name, err)
+		}
+	}
+	wantKept := []string{"web/100.log", "web/100.log.1", "web/100.exit", "web/90.log", "_supervisor.log"}
+	for _, name := range wantKept {
+		if _, err := os.Stat(filepath.Join(logDir, name)); err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+	}
+	if result.Freed != int64(len(wantGone)) {
+		t.Errorf("Freed = %d, want %d", result.Freed, len(wantGone))
+	}
+	if _, err := os.Stat(filepath.Join(logDir, "api")); !os.IsNotExist(err) {
+		t.Errorf("empty project log directory should be removed, err = %v", err)
+	}
+
+	// A zero retention age removes every log of untracked processes.
+	if _, err := PruneLogs("cfg", PruneOptions{MaxAge: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(logDir, "web", "90.log")); !os.IsNotExist(err) {
+		t.Errorf("90.log should be removed with zero max age, err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(logDir, "web", "100.log")); err != nil {
+		t.Errorf("log of a running process must be kept: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package pidfile
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,9 +16,31 @@ import (
 var BaseDir string
 
 type Entry struct {
-	PID     int    `json:"pid"`
+	PID int `json:"pid"`
+	// Name is the command's name from the config, if any.
+	Name    string `json:"name,omitempty"`
 	Command string `json:"command"`
 	Dir     string `json:"dir"`
+	// Restarts counts how many times the supervisor has restarted this command.
+	Restarts int `json:"restarts,omitempty"`
+	// Env holds the environment variables resolved from the config, so that
+	// restarts use the same environment as the original start.
+	Env map[string]string `json:"env,omitempty"`
+	// Rotation holds the limits at which the log is rotated, so that
+	// restarts keep rotating it the same way.
+	Rotation LogRotation `json:"log_rotation,omitzero"`
+	// StopSignal and StopTimeout control how the process tree is stopped;
+	// see config.CommandItem.
+	StopSignal  string        `json:"stop_signal,omitempty"`
+	StopTimeout time.Duration `json:"stop_timeout,omitempty"`
+	// StartedAt is when the command was started.
+	StartedAt time.Time `json:"started_at,omitzero"`
+	// Exit is how the process ended, recorded once mdc notices that it is
+	// no longer running (see RecordExits).
+	Exit *ExitStatus `json:"exit,omitempty"`
+	// Identity guards against signalling an unrelated process that reused
+	// the PID.
+	Identity
 }
 
 func baseDir() (string, error) {
@@ -68,7 +91,7 @@ func ProcLogTmpPath(configName, projectName string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, projectName, "_pending.log"), nil
+	return filepath.Join(dir, projectName, pendingLogName), nil
 }
 
 // RenameProcLog renames the temporary log file to the final PID-based name.
@@ -100,15 +123,19 @@ func filePath(configName, projectName string) (string, error) {
 }
 
 func Append(configName, projectName string, entry Entry) error {
-	entries, err := Load(configName, projectName)
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
-	entries = append(entries, entry)
-	return Save(configName, projectName, entries)
+	return update(configName, projectName, func(entries []Entry) []Entry {
+		return append(entries, entry)
+	})
 }
 
 func Save(configName, projectName string, entries []Entry) error {
+	return withLock(configName, func() error {
+		return save(configName, projectName, entries)
+	})
+}
+
+// save writes a PID file atomically. The caller must hold the config lock.
+func save(configName, projectName string, entries []Entry) error {
 	path, err := filePath(configName, projectName)
 	if err != nil {
 		return err
@@ -120,7 +147,9 @@ func Save(configName, projectName string, entries []Entry) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	// Entries hold the resolved environment of the commands, which may
+	// include secrets from env files: only the user may read them.
+	return writeFileAtomic(path, data, 0600)
 }
 
 func Load(configName, projectName string) ([]Entry, error) {
@@ -128,18 +157,51 @@ func Load(configName, projectName string) ([]Entry, error) {
 	if err != nil {
 		return nil, err
 	}
-	data, err := os.ReadFile(path)
+	return loadFile(configName, path)
+}
+
+// load reads a PID file. The caller must hold the config lock.
+func load(configName, projectName string) ([]Entry, error) {
+	path, err := filePath(configName, projectName)
 	if err != nil {
 		return nil, err
 	}
-	var entries []Entry
-	if err := json.Unmarshal(data, &entries); err != nil {
+	return readEntries(path)
+}
+
+// loadFile reads a PID file without taking the lock: files are replaced
+// atomically, so a parse error means the file is really corrupt. It is then
+// checked again, and quarantined, under the lock.
+func loadFile(configName, path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
 		return nil, err
 	}
-	return entries, nil
+	var entries []Entry
+	if json.Unmarshal(data, &entries) == nil {
+		return entries, nil
+	}
+	err = withLock(configName, func() error {
+		var readErr error
+		entries, readErr = readEntries(path)
+		return readErr
+	})
+	if os.IsNotExist(err) {
+		// Replaced or removed in the meantime.
+		return nil, nil
+	}
+	return entries, err
 }
 
 func LoadAll(configName string) (map[string][]Entry, error) {
+	return loadAll(configName, func(path string) ([]Entry, error) {
+		return loadFile(configName, path)
+	})
+}
+
+// loadAll reads every PID file of a config with the given reader: loadFile
+// without the lock, or readEntries when the caller holds it.
+func loadAll(configName string, read func(path string) ([]Entry, error)) (map[string][]Entry, error) {
 	dir, err := Dir(configName)
 	if err != nil {
 		return nil, err
@@ -157,15 +219,17 @@ func LoadAll(configName string) (map[string][]Entry, error) {
 			continue
 		}
 		name := strings.TrimSuffix(de.Name(), ".json")
-		data, err := os.ReadFile(filepath.Join(dir, de.Name()))
+		items, err := read(filepath.Join(dir, de.Name()))
 		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
 			return nil, err
 		}
-		var items []Entry
-		if err := json.Unmarshal(data, &items); err != nil {
-			return nil, err
+		if items != nil {
+			// nil means the file was quarantined.
+			result[name] = items
 		}
-		result[name] = items
 	}
 	return result, nil
 }
@@ -198,43 +262,130 @@ func LoadAllConfigs() (map[string]map[string][]Entry, error) {
 	return result, nil
 }
 
+// ConfigNames returns the names of all configs that have a PID directory.
+func ConfigNames() ([]string, error) {
+	base, err := baseDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
 // StopFunc is called for each tracked process before it is killed.
 type StopFunc func(projectName, command string, pid int)
 
-const defaultGracefulTimeout = 10 * time.Second
-
 func KillAll(configName string) error {
-	return KillAllWithCallback(configName, nil)
+	return KillAllWithCallback(configName, nil, false)
 }
 
-func KillAllWithCallback(configName string, onStop StopFunc) error {
-	projects, err := LoadAll(configName)
+// KillAllWithCallback stops every tracked process of the config and removes
+// its PID directory and, unless keepLogs is set, its log directory. The PID
+// directory is removed before any process is signalled so that a running
+// supervisor does not restart them. Kept logs are removed by PruneLogs once
+// they are old enough.
+func KillAllWithCallback(configName string, onStop StopFunc, keepLogs bool) error {
+	var projects map[string][]Entry
+	err := withLock(configName, func() error {
+		var err error
+		if projects, err = loadAll(configName, readEntries); err != nil {
+			return err
+		}
+		dir, err := Dir(configName)
+		if err != nil {
+			return err
+		}
+		return os.RemoveAll(dir)
+	})
 	if err != nil {
 		return err
 	}
-	for projectName, entries := range projects {
-		for _, e := range entries {
-			if onStop != nil {
-				onStop(projectName, e.Command, e.PID)
-			}
-			_ = GracefulKill(e.PID, defaultGracefulTimeout)
-		}
+	killEntries(configName, projects, onStop)
+	if keepLogs {
+		return nil
 	}
-	dir, err := Dir(configName)
+	logDir, err := ProcLogDir(configName)
 	if err != nil {
 		return err
 	}
-	if err := os.RemoveAll(dir); err != nil {
+	_ = os.RemoveAll(logDir)
+	return nil
+}
+
+// KillProjectsWithCallback is like KillAllWithCallback but only stops the
+// processes recorded under the given projects. Other projects' entries and
+// logs are left untouched.
+func KillProjectsWithCallback(configName string, projectNames []string, onStop StopFunc, keepLogs bool) error {
+	logDir, err := ProcLogDir(configName)
+	if err != nil {
 		return err
 	}
-	logDir, err := ProcLogDir(configName)
+
+	selected := make(map[string][]Entry)
+	err = withLock(configName, func() error {
+		for _, name := range projectNames {
+			entries, err := load(configName, name)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return err
+			}
+			selected[name] = entries
+			path, err := filePath(configName, name)
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return removeEmptyConfigDir(configName)
+	})
 	if err != nil {
 		return err
 	}
-	_ = os.RemoveAll(logDir)
+
+	killEntries(configName, selected, onStop)
+	if keepLogs {
+		return nil
+	}
+	for name := range selected {
+		_ = os.RemoveAll(filepath.Join(logDir, name))
+	}
 	return nil
 }
 
+func killEntries(configName string, projects map[string][]Entry, onStop StopFunc) {
+	for projectName, entries := range projects {
+		for _, e := range entries {
+			RecordStop(configName, projectName, e)
+			if onStop != nil {
+				onStop(projectName, e.Command, e.PID)
+			}
+			survivors, err := KillEntry(e)
+			if errors.Is(err, ErrStale) {
+				Warnf("[%s] PID %d of %q: %v", projectName, e.PID, e.Command, err)
+			}
+			if len(survivors) > 0 {
+				Warnf("[%s] %s", projectName, SurvivorMessage(e, survivors))
+			}
+		}
+	}
+}
+
 // FindByPID searches all PID files and returns the config name, project name,
 // and entry for the given PID. Returns an error if the PID is not found.
 func FindByPID(pid int) (configName, projectName string, entry Entry, err error) {
@@ -258,31 +409,39 @@ func FindByPID(pid int) (configName, projectName string, entry Entry, err error)
 // If no entries remain, the file is deleted. If no files remain in the
 // config directory, the directory is also removed.
 func RemoveEntry(configName, projectName string, pid int) error {
-	entries, err := Load(configName, projectName)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
+	return update(configName, projectName, func(entries []Entry) []Entry {
+		filtered := make([]Entry, 0, len(entries))
+		for _, e := range entries {
+			if e.PID != pid {
+				filtered = append(filtered, e)
+			}
 		}
-		return err
-	}
+		return filtered
+	})
+}
 
-	filtered := make([]Entry, 0, len(entries))
-	for _, e := range entries {
-		if e.PID != pid {
-			filtered = append(filtered, e)
-		}
-	}
+// ErrNotTracked is returned by ReplaceEntry when the entry to replace is no
+// longer tracked, e.g. because "mdc down" stopped it in the meantime.
+var ErrNotTracked = errors.New("process is no longer tracked")
 
-	if len(filtered) == 0 {
-		path, err := filePath(configName, projectName)
-		if err != nil {
-			return err
+// ReplaceEntry swaps the entry with oldPID for newEntry, keeping its position.
+// If oldPID is not tracked, nothing changes and ErrNotTracked is returned.
+func ReplaceEntry(configName, projectName string, oldPID int, newEntry Entry) error {
+	found := false
+	err := update(configName, projectName, func(entries []Entry) []Entry {
+		for i, e := range entries {
+			if e.PID == oldPID {
+				entries[i] = newEntry
+				found = true
+				break
+			}
 		}
-		_ = os.Remove(path)
-		return removeEmptyConfigDir(configName)
+		return entries
+	})
+	if err == nil && !found {
+		return ErrNotTracked
 	}
-
-	return Save(configName, projectName, filtered)
+	return err
 }
 
 func removeEmptyConfigDir(configName string) error {
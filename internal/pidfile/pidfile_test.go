@@ -1,8 +1,10 @@
 package pidfile
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -42,6 +44,29 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestSaveIsPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	if err := Save("myconfig", "frontend", []Entry{{PID: 1234, Command: "npm run dev", Env: map[string]string{"TOKEN": "secret"}}}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	path, err := filePath("myconfig", "frontend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("PID file mode = %o, want 600", mode)
+	}
+}
+
 func TestAppend(t *testing.T) {
 	cleanup := withTempBaseDir(t)
 	defer cleanup()
@@ -68,6 +93,40 @@ func TestAppend(t *testing.T) {
 	}
 }
 
+func TestReplaceEntry(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	if err := Save("cfg", "proj", []Entry{{PID: 100, Command: "cmd1"}, {PID: 200, Command: "cmd2"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReplaceEntry("cfg", "proj", 100, Entry{PID: 300, Command: "cmd1", Restarts: 1}); err != nil {
+		t.Fatalf("ReplaceEntry() error: %v", err)
+	}
+	loaded, err := Load("cfg", "proj")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].PID != 300 || loaded[0].Restarts != 1 || loaded[1].PID != 200 {
+		t.Errorf("loaded = %+v, want [300 (restarts 1), 200]", loaded)
+	}
+
+	if err := ReplaceEntry("cfg", "proj", 999, Entry{PID: 400, Command: "cmd3"}); !errors.Is(err, ErrNotTracked) {
+		t.Fatalf("ReplaceEntry() of an unknown PID error = %v, want ErrNotTracked", err)
+	}
+	loaded, _ = Load("cfg", "proj")
+	if len(loaded) != 2 {
+		t.Errorf("unknown old PID should not be appended, got %+v", loaded)
+	}
+	if err := ReplaceEntry("cfg", "gone", 100, Entry{PID: 500}); !errors.Is(err, ErrNotTracked) {
+		t.Fatalf("ReplaceEntry() in an untracked project error = %v, want ErrNotTracked", err)
+	}
+	if _, err := Load("cfg", "gone"); !os.IsNotExist(err) {
+		t.Errorf("ReplaceEntry() should not create a PID file, Load() error = %v", err)
+	}
+}
+
 func TestLoadNonexistent(t *testing.T) {
 	cleanup := withTempBaseDir(t)
 	defer cleanup()
@@ -319,3 +378,71 @@ func TestKillAllCleansUpLogs(t *testing.T) {
 		t.Errorf("log directory should be removed after KillAll, err = %v", err)
 	}
 }
+
+func TestKillProjectsWithCallback(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	if err := Save("cfg", "web", []Entry{{PID: 999999999, Command: "fake web"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save("cfg", "api", []Entry{{PID: 999999998, Command: "fake api"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var stopped []string
+	err := KillProjectsWithCallback("cfg", []string{"web", "missing"}, func(project, command string, pid int) {
+		stopped = append(stopped, project)
+	}, false)
+	if err != nil {
+		t.Fatalf("KillProjectsWithCallback() error: %v", err)
+	}
+	if len(stopped) != 1 || stopped[0] != "web" {
+		t.Errorf("stopped = %v, want [web]", stopped)
+	}
+
+	all, err := LoadAll("cfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := all["web"]; ok {
+		t.Error("web entries should be removed")
+	}
+	if _, ok := all["api"]; !ok {
+		t.Error("api entries should be kept")
+	}
+
+	if err := KillProjectsWithCallback("cfg", []string{"api"}, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := Dir("cfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("PID directory should be removed once empty, err = %v", err)
+	}
+}
+
+func TestKillProjectsKeepLogs(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	if err := Save("cfg", "web", []Entry{{PID: 999999999, Command: "fake web"}}); err != nil {
+		t.Fatal(err)
+	}
+	logPath, _ := ProcLogFilePath("cfg", "web", 999999999)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logPath, []byte("log"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := KillProjectsWithCallback("cfg", []string{"web"}, nil, true); err != nil {
+		t.Fatalf("KillProjectsWithCallback() error: %v", err)
+	}
+	if _, err := os.Stat(logPath); err != nil {
+		t.Errorf("log should be kept, err = %v", err)
+	}
+}
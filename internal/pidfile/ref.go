@@ -0,0 +1,185 @@
+package pidfile
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Ref is a tracked process together with the config and project it is
+// tracked under.
+type Ref struct {
+	Config  string
+	Project string
+	Entry   Entry
+}
+
+// String returns the identifier that refers to the process across
+// restarts, "<config>/<project>/<name>", or its PID when the command has no
+// name.
+func (r Ref) String() string {
+	if r.Entry.Name == "" {
+		return strconv.Itoa(r.Entry.PID)
+	}
+	return r.Config + "/" + r.Project + "/" + r.Entry.Name
+}
+
+// Refs returns every tracked process, sorted by config, project and PID.
+func Refs() ([]Ref, error) {
+	allConfigs, err := LoadAllConfigs()
+	if err != nil {
+		return nil, err
+	}
+	var refs []Ref
+	for cn, projects := range allConfigs {
+		for pn, entries := range projects {
+			for _, e := range entries {
+				refs = append(refs, Ref{Config: cn, Project: pn, Entry: e})
+			}
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if a.Config != b.Config {
+			return a.Config < b.Config
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Entry.PID < b.Entry.PID
+	})
+	return refs, nil
+}
+
+// Match returns the tracked processes an identifier refers to:
+//
+//   - a PID;
+//   - "<config>/<project>/<name>", a named command;
+//   - "<config>/<project>", the processes of a project;
+//   - "<project>", the processes of a project in any config.
+func Match(id string) ([]Ref, error) {
+	refs, err := Refs()
+	if err != nil {
+		return nil, err
+	}
+	var match func(Ref) bool
+	if pid, err := strconv.Atoi(id); err == nil {
+		match = func(r Ref) bool { return r.Entry.PID == pid }
+	} else {
+		parts := strings.Split(id, "/")
+		switch len(parts) {
+		case 1:
+			match = func(r Ref) bool { return r.Project == parts[0] }
+		case 2:
+			match = func(r Ref) bool { return r.Config == parts[0] && r.Project == parts[1] }
+		case 3:
+			match = func(r Ref) bool {
+				return r.Config == parts[0] && r.Project == parts[1] && r.Entry.Name == parts[2]
+			}
+		default:
+			return nil, fmt.Errorf("invalid process %q: use a PID, <config>/<project>/<name>, <config>/<project> or <project>", id)
+		}
+	}
+	var result []Ref
+	for _, r := range refs {
+		if match(r) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// Resolve returns the single tracked process an identifier refers to (see
+// Match). It fails when the identifier matches none or several processes.
+func Resolve(id string) (Ref, error) {
+	refs, err := Match(id)
+	if err != nil {
+		return Ref{}, err
+	}
+	switch len(refs) {
+	case 0:
+		if pid, err := strconv.Atoi(id); err == nil {
+			return Ref{}, fmt.Errorf("no tracked process with PID %d", pid)
+		}
+		return Ref{}, fmt.Errorf("no tracked process matches %q", id)
+	case 1:
+		return refs[0], nil
+	}
+	candidates := make([]string, len(refs))
+	for i, r := range refs {
+		candidates[i] = r.String()
+	}
+	return Ref{}, fmt.Errorf("%q matches %d processes; use one of: %s", id, len(refs), strings.Join(candidates, ", "))
+}
+
+// RefFilter narrows down tracked processes. Configs, Projects and Command
+// are glob patterns in which "*" matches any text, including "/", and "?"
+// matches one character. Empty fields match every process.
+type RefFilter struct {
+	Configs  []string
+	Projects []string
+	// Command is matched against the whole command line.
+	Command string
+	// Dead keeps only processes that are no longer running.
+	Dead bool
+}
+
+// IsEmpty reports whether the filter matches every process.
+func (f RefFilter) IsEmpty() bool {
+	return len(f.Configs) == 0 && len(f.Projects) == 0 && f.Command == "" && !f.Dead
+}
+
+// Filter returns the refs matched by f.
+func (f RefFilter) Filter(refs []Ref) []Ref {
+	var result []Ref
+	for _, r := range refs {
+		if f.matches(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (f RefFilter) matches(r Ref) bool {
+	if len(f.Configs) > 0 && !matchAnyGlob(f.Configs, r.Config) {
+		return false
+	}
+	if len(f.Projects) > 0 && !matchAnyGlob(f.Projects, r.Project) {
+		return false
+	}
+	if f.Command != "" && !MatchGlob(f.Command, r.Entry.Command) {
+		return false
+	}
+	return !f.Dead || r.Entry.Status() == StatusDead
+}
+
+func matchAnyGlob(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if MatchGlob(p, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob reports whether s matches the glob pattern as a whole. Unlike
+// path.Match, "*" also matches "/", since commands are not paths.
+func MatchGlob(pattern, s string) bool {
+	var re strings.Builder
+	re.WriteString("(?s)^")
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			re.WriteString(".*")
+		case '?':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	ok, _ := regexp.MatchString(re.String(), s)
+	return ok
+}
@@ -0,0 +1,132 @@
+package pidfile
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	if err := Save("shop", "web", []Entry{{PID: 101, Name: "dev", Command: "npm run dev"}, {PID: 102, Name: "worker", Command: "npm run worker"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save("shop", "api", []Entry{{PID: 201, Command: "go run ."}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save("blog", "web", []Entry{{PID: 301, Name: "dev", Command: "hugo server"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id      string
+		wantPID int
+		wantErr string
+	}{
+		{id: "101", wantPID: 101},
+		{id: "shop/web/worker", wantPID: 102},
+		{id: "blog/web/dev", wantPID: 301},
+		{id: "shop/api", wantPID: 201},
+		{id: "api", wantPID: 201},
+		{id: "999", wantErr: "no tracked process with PID 999"},
+		{id: "shop/web/missing", wantErr: "no tracked process matches"},
+		{id: "shop/web", wantErr: "use one of: shop/web/dev, shop/web/worker"},
+		{id: "web", wantErr: "matches 3 processes"},
+		{id: "a/b/c/d", wantErr: "invalid process"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			ref, err := Resolve(tt.id)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Resolve(%q) error = %v, want %q", tt.id, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) error: %v", tt.id, err)
+			}
+			if ref.Entry.PID != tt.wantPID {
+				t.Errorf("Resolve(%q) = PID %d, want %d", tt.id, ref.Entry.PID, tt.wantPID)
+			}
+		})
+	}
+}
+
+func TestRefString(t *testing.T) {
+	named := Ref{Config: "shop", Project: "web", Entry: Entry{PID: 101, Name: "dev"}}
+	if got := named.String(); got != "shop/web/dev" {
+		t.Errorf("String() = %q, want shop/web/dev", got)
+	}
+	unnamed := Ref{Config: "shop", Project: "api", Entry: Entry{PID: 201}}
+	if got := unnamed.String(); got != "201" {
+		t.Errorf("String() = %q, want 201", got)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"npm run *", "npm run dev", true},
+		{"npm run *", "pnpm run dev", false},
+		{"*serve*", "python -m http.server 8000", true},
+		{"web?", "web1", true},
+		{"web?", "web", false},
+		{"*", "./bin/app --dir /tmp", true},
+		{"go run .", "go run x", false},
+		{"Front*", "Frontend", true},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestRefFilter(t *testing.T) {
+	self := os.Getpid()
+	refs := []Ref{
+		{Config: "dev", Project: "Frontend", Entry: Entry{PID: self, Command: "npm run dev"}},
+		{Config: "dev", Project: "Backend", Entry: Entry{PID: self, Command: "go run ."}},
+		{Config: "prod", Project: "Frontend", Entry: Entry{PID: 201, Command: "npm run serve", Exit: &ExitStatus{Code: 1}}},
+	}
+	indexes := func(f RefFilter) []int {
+		var result []int
+		for _, r := range f.Filter(refs) {
+			for i := range refs {
+				if r.Config == refs[i].Config && r.Project == refs[i].Project {
+					result = append(result, i)
+				}
+			}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		filter RefFilter
+		want   []int
+	}{
+		{name: "empty", filter: RefFilter{}, want: []int{0, 1, 2}},
+		{name: "config and project", filter: RefFilter{Configs: []string{"dev"}, Projects: []string{"Frontend"}}, want: []int{0}},
+		{name: "project glob", filter: RefFilter{Projects: []string{"Front*", "Back*"}}, want: []int{0, 1, 2}},
+		{name: "command", filter: RefFilter{Command: "npm run *"}, want: []int{0, 2}},
+		{name: "dead", filter: RefFilter{Dead: true}, want: []int{2}},
+		{name: "no match", filter: RefFilter{Configs: []string{"staging"}}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indexes(tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !(RefFilter{}).IsEmpty() || (RefFilter{Dead: true}).IsEmpty() {
+		t.Error("IsEmpty() is wrong")
+	}
+}
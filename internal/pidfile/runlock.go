@@ -0,0 +1,169 @@
+package pidfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var errWouldBlock = errors.New("lock is held by another process")
+
+// runLockPollInterval is how often a waiting AcquireRunLock retries.
+var runLockPollInterval = 200 * time.Millisecond
+
+// LockOwner describes the mdc process holding a config's run lock. It is
+// written into the lock file, so that others can tell who holds it.
+type LockOwner struct {
+	PID       int       `json:"pid"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (o LockOwner) String() string {
+	return fmt.Sprintf("PID %d (%q, started %s)", o.PID, o.Command, o.StartedAt.Local().Format("2006-01-02 15:04:05"))
+}
+
+// LockedError is returned by AcquireRunLock when another mdc process holds
+// the config's run lock.
+type LockedError struct {
+	Config string
+	// Owner is nil when the lock file does not name its owner yet.
+	Owner *LockOwner
+	// Waited is how long AcquireRunLock waited before giving up.
+	Waited time.Duration
+}
+
+func (e *LockedError) Error() string {
+	owner := "another mdc process"
+	if e.Owner != nil {
+		owner = e.Owner.String()
+	}
+	if e.Waited > 0 {
+		return fmt.Sprintf("config %q is still locked by %s after waiting %s", e.Config, owner, e.Waited.Round(time.Second))
+	}
+	return fmt.Sprintf("config %q is locked by %s; use --wait to wait for it", e.Config, owner)
+}
+
+// RunLock is an exclusive lock on a config's environment, held by commands
+// that start or stop its processes for as long as they run. Like the lock on
+// the PID files, it is released by the operating system when its owner dies,
+// so a crashed mdc never leaves the config locked.
+type RunLock struct {
+	f *os.File
+}
+
+func runLockPath(configName string) (string, error) {
+	base, err := baseDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "."+configName+".run.lock"), nil
+}
+
+// AcquireRunLock takes the run lock of a config for this process. When another
+// process holds it, it returns a *LockedError right away or, with wait,
+// retries until the lock is free or timeout (when positive) has elapsed.
+// The owner named in a lock file that is not held is stale, e.g. left by an
+// mdc that crashed or exited with an error, and is replaced.
+func AcquireRunLock(configName string, wait bool, timeout time.Duration) (*RunLock, error) {
+	path, err := runLockPath(configName)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errWouldBlock) {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		waited := time.Since(start)
+		if !wait || (timeout > 0 && waited >= timeout) {
+			locked := &LockedError{Config: configName, Owner: readLockOwner(f)}
+			if wait {
+				locked.Waited = waited
+			}
+			_ = f.Close()
+			return nil, locked
+		}
+		time.Sleep(runLockPollInterval)
+	}
+
+	owner := LockOwner{PID: os.Getpid(), Command: commandLine(), StartedAt: time.Now()}
+	if err := writeLockOwner(f, &owner); err != nil {
+		_ = unlockFile(f)
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return &RunLock{f: f}, nil
+}
+
+// Release clears the owner from the lock file and releases the lock.
+func (l *RunLock) Release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := writeLockOwner(l.f, nil)
+	if unlockErr := unlockFile(l.f); err == nil {
+		err = unlockErr
+	}
+	if closeErr := l.f.Close(); err == nil {
+		err = closeErr
+	}
+	l.f = nil
+	return err
+}
+
+// commandLine returns the command line of this mdc process, e.g.
+// "mdc up dev --only web".
+func commandLine() string {
+	return strings.Join(append([]string{"mdc"}, os.Args[1:]...), " ")
+}
+
+// readLockOwner reads the owner from the lock file, or returns nil when the
+// file is empty or being written.
+func readLockOwner(f *os.File) *LockOwner {
+	data, err := io.ReadAll(io.NewSectionReader(f, 0, 1<<20))
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	var owner LockOwner
+	if json.Unmarshal(data, &owner) != nil || owner.PID == 0 {
+		return nil
+	}
+	return &owner
+}
+
+// writeLockOwner replaces the contents of the lock file with owner, or
+// empties it for nil. The file itself is never removed, since others may
+// be waiting on it.
+func writeLockOwner(f *os.File, owner *LockOwner) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if owner == nil {
+		return nil
+	}
+	data, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(data, 0)
+	return err
+}
@@ -0,0 +1,110 @@
+package pidfile
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireRunLock(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	lock, err := AcquireRunLock("test", false, 0)
+	if err != nil {
+		t.Fatalf("AcquireRunLock failed: %v", err)
+	}
+
+	// A second lock file description conflicts, as another process would.
+	_, err = AcquireRunLock("test", false, 0)
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError, got %v", err)
+	}
+	if locked.Owner == nil || locked.Owner.PID != os.Getpid() {
+		t.Fatalf("expected the owner to be PID %d, got %+v", os.Getpid(), locked.Owner)
+	}
+	if !strings.Contains(err.Error(), "--wait") {
+		t.Errorf("expected the error to mention --wait, got %q", err)
+	}
+
+	// Other configs are not affected.
+	other, err := AcquireRunLock("other", false, 0)
+	if err != nil {
+		t.Fatalf("AcquireRunLock of another config failed: %v", err)
+	}
+	_ = other.Release()
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	again, err := AcquireRunLock("test", false, 0)
+	if err != nil {
+		t.Fatalf("AcquireRunLock after Release failed: %v", err)
+	}
+	_ = again.Release()
+}
+
+func TestAcquireRunLockWait(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+	oldInterval := runLockPollInterval
+	runLockPollInterval = 10 * time.Millisecond
+	defer func() { runLockPollInterval = oldInterval }()
+
+	lock, err := AcquireRunLock("test", false, 0)
+	if err != nil {
+		t.Fatalf("AcquireRunLock failed: %v", err)
+	}
+
+	_, err = AcquireRunLock("test", true, 50*time.Millisecond)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Waited < 50*time.Millisecond {
+		t.Fatalf("expected LockedError after waiting, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "after waiting") {
+		t.Errorf("expected the error to mention the wait, got %q", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = lock.Release()
+	}()
+	waited, err := AcquireRunLock("test", true, 5*time.Second)
+	if err != nil {
+		t.Fatalf("expected the lock once released, got %v", err)
+	}
+	_ = waited.Release()
+}
+
+func TestAcquireRunLockStaleOwner(t *testing.T) {
+	cleanup := withTempBaseDir(t)
+	defer cleanup()
+
+	// An owner left behind by an mdc that crashed does not hold the lock.
+	path, err := runLockPath("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"pid":999999,"command":"mdc up test","started_at":"2024-01-01T00:00:00Z"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := AcquireRunLock("test", false, 0)
+	if err != nil {
+		t.Fatalf("AcquireRunLock with a stale owner failed: %v", err)
+	}
+	defer func() { _ = lock.Release() }()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	owner := readLockOwner(f)
+	if owner == nil || owner.PID != os.Getpid() {
+		t.Fatalf("expected the owner to be replaced by PID %d, got %+v", os.Getpid(), owner)
+	}
+}
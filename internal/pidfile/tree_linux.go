@@ -0,0 +1,35 @@
+package pidfile
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// processTable reads all processes from /proc.
+func processTable() (map[int]procInfo, error) {
+	dirs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	table := make(map[int]procInfo, len(dirs))
+	for _, d := range dirs {
+		pid, err := strconv.Atoi(d.Name())
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join("/proc", d.Name(), "stat"))
+		if err != nil {
+			// The process exited while the table was read.
+			continue
+		}
+		comm, fields := splitStat(string(data))
+		if len(fields) < 3 {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		pgid, _ := strconv.Atoi(fields[2])
+		table[pid] = procInfo{ppid: ppid, pgid: pgid, zombie: fields[0] == "Z" || fields[0] == "X", comm: comm}
+	}
+	return table, nil
+}
@@ -0,0 +1,38 @@
+//go:build !linux && !windows
+
+package pidfile
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// processTable reads all processes from ps(1).
+func processTable() (map[int]procInfo, error) {
+	out, err := exec.Command("ps", "-axo", "pid=,ppid=,pgid=,stat=,comm=").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parsePS(string(out)), nil
+}
+
+func parsePS(out string) map[int]procInfo {
+	table := make(map[int]procInfo)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		pgid, _ := strconv.Atoi(fields[2])
+		comm := filepath.Base(strings.Join(fields[4:], " "))
+		table[pid] = procInfo{ppid: ppid, pgid: pgid, zombie: strings.HasPrefix(fields[3], "Z"), comm: comm}
+	}
+	return table
+}
@@ -0,0 +1,137 @@
+//go:build !windows
+
+package pidfile
+
+import (
+	"sort"
+	"syscall"
+	"time"
+)
+
+// procInfo is a row of the process table.
+type procInfo struct {
+	ppid   int
+	pgid   int
+	zombie bool
+	comm   string
+}
+
+var signalsByName = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+// descendants returns the PIDs of all descendants of the given roots.
+func descendants(table map[int]procInfo, roots ...int) []int {
+	children := make(map[int][]int)
+	for pid, info := range table {
+		children[info.ppid] = append(children[info.ppid], pid)
+	}
+	var result []int
+	seen := make(map[int]bool)
+	queue := append([]int(nil), roots...)
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		for _, child := range children[pid] {
+			if !seen[child] {
+				seen[child] = true
+				result = append(result, child)
+				queue = append(queue, child)
+			}
+		}
+	}
+	return result
+}
+
+// killTree sends sig to a process, its descendants and the process groups
+// they lead, waits up to timeout for all of them to exit and kills the rest.
+// Background commands run under the exec helper, whose child starts a new
+// session, so signalling the recorded process group alone would miss the
+// actual command. The tree is rescanned while waiting because processes whose
+// parent died are reparented and can no longer be found from the root.
+func killTree(pid int, sigName string, timeout time.Duration) []Survivor {
+	sig, ok := signalsByName[sigName]
+	if !ok {
+		sig = syscall.SIGTERM
+	}
+	tracked := map[int]string{pid: ""}
+
+	// scan updates the tracked processes and returns those still alive.
+	scan := func() (map[int]procInfo, []int) {
+		table, err := processTable()
+		if err != nil {
+			// Without a process table only the root can be followed.
+			if IsRunning(pid) {
+				return nil, []int{pid}
+			}
+			return nil, nil
+		}
+		var alive []int
+		for p := range tracked {
+			if info, ok := table[p]; ok && !info.zombie {
+				alive = append(alive, p)
+			}
+		}
+		for _, d := range descendants(table, alive...) {
+			if _, ok := tracked[d]; !ok && !table[d].zombie {
+				alive = append(alive, d)
+			}
+			tracked[d] = table[d].comm
+		}
+		for _, p := range alive {
+			tracked[p] = table[p].comm
+		}
+		return table, alive
+	}
+
+	own := syscall.Getpgrp()
+	signalled := make(map[int]bool)
+	signal := func(table map[int]procInfo, alive []int, sig syscall.Signal) {
+		for _, p := range alive {
+			// The stop signal is sent once per process: some programs
+			// treat a repeated SIGINT as a request to quit immediately.
+			if sig != syscall.SIGKILL && signalled[p] {
+				continue
+			}
+			signalled[p] = true
+			// Only groups led by a process of the tree belong to it.
+			if info, ok := table[p]; ok && info.pgid == p && p != own {
+				_ = syscall.Kill(-p, sig)
+			}
+			_ = syscall.Kill(p, sig)
+		}
+	}
+
+	table, alive := scan()
+	signal(table, alive, sig)
+
+	deadline := time.Now().Add(timeout)
+	for len(alive) > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		table, alive = scan()
+		// Processes forked since the signal get it too.
+		signal(table, alive, sig)
+	}
+	if len(alive) == 0 {
+		return nil
+	}
+
+	survivors := make([]Survivor, 0, len(alive))
+	for _, p := range alive {
+		survivors = append(survivors, Survivor{PID: p, Command: tracked[p]})
+	}
+	sort.Slice(survivors, func(i, j int) bool { return survivors[i].PID < survivors[j].PID })
+
+	signal(table, alive, syscall.SIGKILL)
+	for end := time.Now().Add(time.Second); len(alive) > 0 && time.Now().Before(end); {
+		time.Sleep(50 * time.Millisecond)
+		_, alive = scan()
+	}
+	return survivors
+}
@@ -0,0 +1,122 @@
+//go:build !windows
+
+package pidfile
+
+import (
+	"os/exec"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDescendants(t *testing.T) {
+	table := map[int]procInfo{
+		1:  {ppid: 0},
+		10: {ppid: 1},
+		11: {ppid: 10},
+		12: {ppid: 10},
+		13: {ppid: 12},
+		20: {ppid: 1},
+	}
+	got := descendants(table, 10)
+	sort.Ints(got)
+	want := []int{11, 12, 13}
+	if len(got) != len(want) {
+		t.Fatalf("descendants() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("descendants() = %v, want %v", got, want)
+		}
+	}
+}
+
+// startTree starts a shell with two children in its own process group and
+// returns the shell and its children once they are running. The shell is
+// reaped as soon as it exits, so that it is not left as a zombie.
+func startTree(t *testing.T, script string) (*exec.Cmd, []int) {
+	t.Helper()
+	cmd := exec.Command("sh", "-c", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+	})
+	for i := 0; i < 50; i++ {
+		table, err := processTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if children := descendants(table, cmd.Process.Pid); len(children) >= 2 {
+			return cmd, children
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("children did not start")
+	return nil, nil
+}
+
+// alive reports whether a process is running. Unlike IsRunning, it does not
+// count zombies, which are left behind when nothing reaps the orphans.
+func alive(t *testing.T, pid int) bool {
+	t.Helper()
+	table, err := processTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, ok := table[pid]
+	return ok && !info.zombie
+}
+
+func TestKillEntryStopsProcessTree(t *testing.T) {
+	cmd, children := startTree(t, "sleep 300 & sleep 300 & wait")
+
+	entry := Entry{PID: cmd.Process.Pid, StopTimeout: 5 * time.Second, Identity: Identify(cmd.Process.Pid)}
+	survivors, err := KillEntry(entry)
+	if err != nil {
+		t.Fatalf("KillEntry() error: %v", err)
+	}
+	if len(survivors) != 0 {
+		t.Errorf("survivors = %v, want none", survivors)
+	}
+	for _, pid := range children {
+		if alive(t, pid) {
+			t.Errorf("descendant %d is still running", pid)
+		}
+	}
+}
+
+func TestKillEntryReportsSurvivors(t *testing.T) {
+	// Both the shell and its children ignore SIGTERM.
+	cmd, children := startTree(t, "trap '' TERM; sleep 300 & sleep 300 & wait")
+
+	entry := Entry{PID: cmd.Process.Pid, StopTimeout: 300 * time.Millisecond, Identity: Identify(cmd.Process.Pid)}
+	survivors, err := KillEntry(entry)
+	if err != nil {
+		t.Fatalf("KillEntry() error: %v", err)
+	}
+	got := make(map[int]string)
+	for _, s := range survivors {
+		got[s.PID] = s.Command
+	}
+	if _, ok := got[cmd.Process.Pid]; !ok {
+		t.Errorf("survivors = %v, want the shell", survivors)
+	}
+	for _, pid := range children {
+		if got[pid] != "sleep" {
+			t.Errorf("survivors = %v, want sleep %d", survivors, pid)
+		}
+		if alive(t, pid) {
+			t.Errorf("survivor %d was not killed", pid)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package pidfile
+
+import "time"
+
+// killTree only stops the process itself on Windows, where stop signals
+// and process trees are not supported.
+func killTree(pid int, _ string, timeout time.Duration) []Survivor {
+	_ = GracefulKill(pid, timeout)
+	return nil
+}
@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+
+	"mdc/internal/logger"
+)
+
+// dagNode holds the resolved dependency edges of a single project as indices
+// into the projectCommands slice it was built from.
+type dagNode struct {
+	deps       []int
+	dependents []int
+}
+
+// buildDAG resolves each project's depends_on into index-based edges.
+// When reverse is true the edges are flipped so that dependents are processed
+// before the projects they depend on, which is the order used by "down".
+// Dependencies on projects that are not part of pcs are ignored.
+func buildDAG(pcs []projectCommands, reverse bool) []dagNode {
+	index := make(map[string]int, len(pcs))
+	for i, pc := range pcs {
+		index[pc.Project.Name] = i
+	}
+
+	nodes := make([]dagNode, len(pcs))
+	for i, pc := range pcs {
+		for _, dep := range pc.Project.DependsOn {
+			j, ok := index[dep]
+			if !ok || j == i {
+				continue
+			}
+			from, to := i, j
+			if reverse {
+				from, to = j, i
+			}
+			nodes[from].deps = append(nodes[from].deps, to)
+			nodes[to].dependents = append(nodes[to].dependents, from)
+		}
+	}
+	return nodes
+}
+
+// topoOrder returns node indices ordered so that every node comes after all of
+// its dependencies. Ties are broken by definition order, so a graph without
+// edges keeps the original order. Nodes that are part of a cycle are omitted.
+func topoOrder(nodes []dagNode) []int {
+	pending := make([]int, len(nodes))
+	for i, n := range nodes {
+		pending[i] = len(n.deps)
+	}
+	done := make([]bool, len(nodes))
+	order := make([]int, 0, len(nodes))
+
+	for len(order) < len(nodes) {
+		next := -1
+		for i := range nodes {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		done[next] = true
+		order = append(order, next)
+		for _, d := range nodes[next].dependents {
+			pending[d]--
+		}
+	}
+	return order
+}
+
+type projectResult struct {
+	idx int
+	err error
+}
+
+// runDAG runs every project whose dependencies have completed concurrently.
+// When a project fails, all projects that transitively depend on it are
+// skipped; their errors are reported with the skipped flag set.
+func runDAG(pcs []projectCommands, nodes []dagNode, run func(pc projectCommands) error) (errs []error, skipped []bool) {
+	errs = make([]error, len(pcs))
+	pending := make([]int, len(nodes))
+	for i, n := range nodes {
+		pending[i] = len(n.deps)
+	}
+	skipped = make([]bool, len(nodes))
+
+	results := make(chan projectResult)
+	running := 0
+	start := func(idx int) {
+		running++
+		go func() {
+			results <- projectResult{idx: idx, err: run(pcs[idx])}
+		}()
+	}
+
+	for i := range nodes {
+		if pending[i] == 0 {
+			start(i)
+		}
+	}
+
+	for running > 0 {
+		r := <-results
+		running--
+
+		if r.err != nil {
+			errs[r.idx] = r.err
+			skipDependents(pcs, nodes, r.idx, skipped, errs)
+			continue
+		}
+
+		for _, d := range nodes[r.idx].dependents {
+			if skipped[d] {
+				continue
+			}
+			pending[d]--
+			if pending[d] == 0 {
+				start(d)
+			}
+		}
+	}
+	return errs, skipped
+}
+
+func skipDependents(pcs []projectCommands, nodes []dagNode, failed int, skipped []bool, errs []error) {
+	reason := fmt.Sprintf("dependency %q failed", pcs[failed].Project.Name)
+	if err := errs[failed]; errors.Is(err, errCanceled) || errors.Is(err, errNotStarted) {
+		reason = fmt.Sprintf("dependency %q was canceled", pcs[failed].Project.Name)
+	}
+	queue := append([]int{}, nodes[failed].dependents...)
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+		if skipped[d] {
+			continue
+		}
+		skipped[d] = true
+		logger.ProjectSkipped(pcs[d].Project.Name, reason)
+		errs[d] = fmt.Errorf("project %q: skipped because %s", pcs[d].Project.Name, reason)
+		queue = append(queue, nodes[d].dependents...)
+	}
+}
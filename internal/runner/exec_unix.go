@@ -0,0 +1,172 @@
+//go:build !windows
+
+package runner
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"mdc/internal/pidfile"
+
+	"github.com/creack/pty/v2"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// execReadyTimeout bounds how long the spawner waits for the helper to
+	// report that the command started.
+	execReadyTimeout = 5 * time.Second
+	// execDrainTimeout is how long the helper keeps copying output after the
+	// command exited, while descendants still hold the terminal open.
+	execDrainTimeout = time.Second
+)
+
+// execWinsize is the terminal size background commands see.
+var execWinsize = &pty.Winsize{Rows: 40, Cols: 160}
+
+// startLogged starts the exec helper ("mdc __exec"), which runs command in a
+// PTY and writes its output to logFile, and waits until the command has
+// started. The helper reports over a pipe instead of the spawner polling for
+// the log file.
+func startLogged(command, dir, logFile string, rotation pidfile.LogRotation, env []string) (*exec.Cmd, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the mdc executable: %w", err)
+	}
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = readyR.Close() }()
+
+	cmd := exec.Command(self, ExecHelperCommand, "-log", logFile,
+		"-max-size", strconv.FormatInt(rotation.MaxSize, 10),
+		"-max-files", strconv.Itoa(rotation.MaxFiles),
+		"-ready-fd", "3", "--", command)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.ExtraFiles = []*os.File{readyW}
+	setSysProcAttr(cmd)
+
+	err = cmd.Start()
+	_ = readyW.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	_ = readyR.SetReadDeadline(time.Now().Add(execReadyTimeout))
+	msg, err := io.ReadAll(readyR)
+	if err == nil && len(msg) == 0 {
+		return cmd, nil
+	}
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	if err != nil {
+		return nil, fmt.Errorf("background command did not start: %w", err)
+	}
+	return nil, fmt.Errorf("%s", strings.TrimSpace(string(msg)))
+}
+
+// RunExecHelper implements "mdc __exec": it runs a shell command in a PTY,
+// appends its output to the log with a timestamp on each line, and records
+// the exit status in "<pid>.exit" next to the log, where pid is the helper's
+// own PID (the one mdc tracks). The log is rotated once it exceeds
+// -max-size (see pidfile.LogWriter). It returns the command's exit code, or 128
+// plus the signal number when the command was killed by a signal.
+//
+// The helper does not forward signals: stopping a background process
+// signals its whole process tree, command included (see pidfile.KillEntry),
+// and a repeated SIGINT makes some programs quit without cleaning up. It
+// only survives them to record how the command ended.
+func RunExecHelper(args []string) int {
+	fs := flag.NewFlagSet(ExecHelperCommand, flag.ContinueOnError)
+	logFile := fs.String("log", "", "log file")
+	maxSize := fs.Int64("max-size", 0, "size at which the log is rotated")
+	maxFiles := fs.Int("max-files", 0, "number of rotated logs to keep")
+	readyFD := fs.Int("ready-fd", 0, "file descriptor to report the start on")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *logFile == "" || fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: mdc %s -log <file> [-max-size <bytes>] [-max-files <n>] [-ready-fd <fd>] -- <command>\n", ExecHelperCommand)
+		return 2
+	}
+
+	report := func(error) {}
+	if *readyFD > 0 {
+		// The command must not inherit the pipe, or the spawner would
+		// wait for it to exit.
+		syscall.CloseOnExec(*readyFD)
+		ready := os.NewFile(uintptr(*readyFD), "ready")
+		report = func(err error) {
+			if err != nil {
+				_, _ = fmt.Fprintln(ready, err)
+			}
+			_ = ready.Close()
+		}
+	}
+
+	rotation := pidfile.LogRotation{MaxSize: *maxSize, MaxFiles: *maxFiles}
+	logOut, err := pidfile.OpenLogWriter(*logFile, pidfile.TrackedLogPath(*logFile, os.Getpid()), rotation)
+	if err != nil {
+		report(fmt.Errorf("failed to open log: %w", err))
+		return 1
+	}
+	defer func() { _ = logOut.Close() }()
+
+	exitFile := pidfile.ExitFilePath(*logFile, os.Getpid())
+	// A status left by an earlier process with the same PID.
+	_ = os.Remove(exitFile)
+
+	// Caught rather than ignored: ignored signals stay ignored in the
+	// command.
+	signal.Notify(make(chan os.Signal, 1), syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	cmd := exec.Command("sh", "-c", fs.Arg(0))
+	ptmx, err := pty.StartWithSize(cmd, execWinsize)
+	if err != nil {
+		report(err)
+		return 1
+	}
+	report(nil)
+
+	copied := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(pidfile.NewTimestampWriter(logOut), ptmx)
+		close(copied)
+	}()
+
+	_ = cmd.Wait()
+	select {
+	case <-copied:
+	case <-time.After(execDrainTimeout):
+	}
+	_ = ptmx.Close()
+
+	status, code := exitStatusOf(cmd.ProcessState)
+	if err := pidfile.WriteExitStatus(exitFile, status); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record exit status: %v\n", err)
+	}
+	return code
+}
+
+func exitStatusOf(state *os.ProcessState) (pidfile.ExitStatus, int) {
+	status := pidfile.ExitStatus{Code: state.ExitCode(), ExitedAt: time.Now()}
+	code := status.Code
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		status.Signal = unix.SignalName(ws.Signal())
+		if status.Signal == "" {
+			status.Signal = fmt.Sprintf("signal %d", ws.Signal())
+		}
+		code = 128 + int(ws.Signal())
+	}
+	return status, code
+}
@@ -0,0 +1,128 @@
+//go:build !windows
+
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"mdc/internal/pidfile"
+)
+
+func readExitFile(t *testing.T, logFile string, pid int) pidfile.ExitStatus {
+	t.Helper()
+	data, err := os.ReadFile(pidfile.ExitFilePath(logFile, pid))
+	if err != nil {
+		t.Fatalf("exit status not recorded: %v", err)
+	}
+	var s pidfile.ExitStatus
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestExecHelperRecordsExitCode(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "exit.log")
+
+	bp, err := SpawnBackgroundProcess("echo failing; exit 3", dir, SpawnOptions{LogFile: logFile})
+	if err != nil {
+		t.Fatalf("SpawnBackgroundProcess() error: %v", err)
+	}
+	<-bp.Done()
+	if bp.ExitCode() != 3 {
+		t.Errorf("ExitCode() = %d, want 3", bp.ExitCode())
+	}
+	if s := readExitFile(t, logFile, bp.PID); s.Code != 3 || s.Signal != "" || s.ExitedAt.IsZero() {
+		t.Errorf("exit status = %+v, want code 3", s)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, rest, ok := pidfile.SplitLogLine(string(data))
+	if !ok || ts.IsZero() || !strings.HasPrefix(rest, "failing") {
+		t.Errorf("log line should be timestamped, got %q", data)
+	}
+}
+
+func TestExecHelperRecordsSignal(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "signal.log")
+
+	bp, err := SpawnBackgroundProcess("sleep 60", dir, SpawnOptions{LogFile: logFile})
+	if err != nil {
+		t.Fatalf("SpawnBackgroundProcess() error: %v", err)
+	}
+	if survivors, err := pidfile.KillEntry(pidfile.Entry{PID: bp.PID}); err != nil || len(survivors) > 0 {
+		t.Fatalf("KillEntry() = %v, %v", survivors, err)
+	}
+	<-bp.Done()
+	if s := readExitFile(t, logFile, bp.PID); s.Signal != "SIGTERM" || s.Code != -1 {
+		t.Errorf("exit status = %+v, want killed by SIGTERM", s)
+	}
+}
+
+func TestExecHelperRotatesLog(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "_pending.log")
+
+	// The output starts after the spawner has renamed the pending log.
+	bp, err := SpawnBackgroundProcess("sleep 0.5; for i in $(seq 1 50); do echo $i; sleep 0.01; done", dir, SpawnOptions{
+		LogFile:  logFile,
+		Rotation: pidfile.LogRotation{MaxSize: 200, MaxFiles: 100},
+	})
+	if err != nil {
+		t.Fatalf("SpawnBackgroundProcess() error: %v", err)
+	}
+	path, err := pidfile.RenameProcLog(logFile, bp.PID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-bp.Done()
+
+	segments := pidfile.LogSegments(path)
+	if len(segments) < 3 {
+		t.Fatalf("got segments %v, want the log rotated", segments)
+	}
+	var lines []string
+	for _, segment := range segments {
+		data, err := os.ReadFile(segment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if _, rest, ok := pidfile.SplitLogLine(line); ok {
+				lines = append(lines, strings.TrimSpace(rest))
+			}
+		}
+	}
+	for i, line := range lines {
+		if line != strconv.Itoa(i+1) {
+			t.Fatalf("line %d = %q, want %d", i+1, line, i+1)
+		}
+	}
+	if len(lines) != 50 {
+		t.Errorf("rotated logs hold %d lines, want 50 without loss", len(lines))
+	}
+}
+
+func TestExecHelperReportsStartFailure(t *testing.T) {
+	dir := t.TempDir()
+	// A directory cannot be opened as the log.
+	logFile := filepath.Join(dir, "log-dir")
+	if err := os.Mkdir(logFile, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := SpawnBackgroundProcess("echo never", dir, SpawnOptions{LogFile: logFile, AppendLog: true})
+	if err == nil || !strings.Contains(err.Error(), "failed to open log") {
+		t.Errorf("SpawnBackgroundProcess() error = %v, want log open failure", err)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"mdc/internal/pidfile"
+)
+
+// startLogged on Windows runs a plain shell command with stdout/stderr
+// redirected to the log file, since there is no PTY to run it in. ANSI color
+// codes will not be preserved, the exit status is not recorded and the log
+// is not rotated while it is written.
+func startLogged(command, dir, logFile string, _ pidfile.LogRotation, env []string) (*exec.Cmd, error) {
+	redirect := fmt.Sprintf("%s >> %q 2>&1", command, logFile)
+	cmd := exec.Command("cmd", "/c", redirect)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdin = nil
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	setSysProcAttr(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// RunExecHelper is not available on Windows.
+func RunExecHelper(_ []string) int {
+	fmt.Fprintf(os.Stderr, "mdc %s is not supported on Windows\n", ExecHelperCommand)
+	return 1
+}
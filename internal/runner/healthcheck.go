@@ -0,0 +1,212 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+)
+
+const (
+	maxProbeTimeout = 5 * time.Second
+	logTailLines    = 20
+)
+
+// waitForHealthy probes hc until it passes, the retry limit is reached or the
+// timeout elapses. logPaths are the proc logs searched by log_match checks.
+// If pid is non-zero and the process exits while waiting, the check fails
+// immediately. On failure the tail of the proc logs is printed. env is
+// passed to command probes. Waiting stops when ctx is canceled.
+func waitForHealthy(ctx context.Context, p config.Project, hc *config.HealthCheck, logPaths []string, pid int, env map[string]string) error {
+	desc := hc.String()
+	logger.Waiting(p.Name, desc)
+
+	start := time.Now()
+	deadline := start.Add(hc.TimeoutDuration())
+	interval := hc.IntervalDuration()
+
+	for attempt := 1; ; attempt++ {
+		err := probe(p, hc, logPaths, deadline, env)
+		if err == nil {
+			logger.Healthy(p.Name, desc, time.Since(start))
+			return nil
+		}
+
+		var reason string
+		switch {
+		case pid > 0 && !pidfile.IsRunning(pid):
+			reason = fmt.Sprintf("process %d exited before becoming ready (last error: %v)", pid, err)
+		case hc.Retries > 0 && attempt >= hc.Retries:
+			reason = fmt.Sprintf("gave up after %d attempts: %v", attempt, err)
+		case time.Now().Add(interval).After(deadline):
+			reason = fmt.Sprintf("timed out after %s: %v", hc.TimeoutDuration(), err)
+		}
+		if reason != "" {
+			healthErr := fmt.Errorf("health check %s %s", desc, reason)
+			logger.Unhealthy(p.Name, desc, healthErr)
+			for _, path := range logPaths {
+				if tail := TailFile(path, logTailLines); tail != "" {
+					logger.Output(p.Name, tail)
+				}
+			}
+			return healthErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("health check %s %w: %v", desc, errCanceled, context.Cause(ctx))
+		case <-time.After(interval):
+		}
+	}
+}
+
+func probe(p config.Project, hc *config.HealthCheck, logPaths []string, deadline time.Time, env map[string]string) error {
+	timeout := time.Until(deadline)
+	if timeout > maxProbeTimeout {
+		timeout = maxProbeTimeout
+	}
+	if timeout <= 0 {
+		timeout = time.Millisecond
+	}
+
+	switch {
+	case hc.TCP != "":
+		return probeTCP(hc.TCP, timeout)
+	case hc.HTTP != "":
+		return probeHTTP(hc.HTTP, hc.Status, timeout)
+	case hc.Command != "":
+		return probeCommand(hc.Command, p.Path, timeout, env)
+	case hc.LogMatch != "":
+		return probeLog(hc.LogMatch, logPaths)
+	}
+	return fmt.Errorf("no probe configured")
+}
+
+func probeTCP(addr string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func probeHTTP(url string, status int, timeout time.Duration) error {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if status != 0 {
+		if resp.StatusCode != status {
+			return fmt.Errorf("status %d, want %d", resp.StatusCode, status)
+		}
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func probeCommand(command, dir string, timeout time.Duration, env map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = config.Environ(env)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// probeLog searches the proc logs for pattern, including their rotated
+// segments, newest first: a chatty process may have rotated the line out of
+// its current log by the time it is checked.
+func probeLog(pattern string, logPaths []string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	for _, path := range logPaths {
+		segments := pidfile.LogSegments(path)
+		for i := len(segments) - 1; i >= 0; i-- {
+			data, err := os.ReadFile(segments[i])
+			if err != nil {
+				continue
+			}
+			if re.MatchString(pidfile.StripLogTimestamps(ansiEscape.ReplaceAllString(string(data), ""))) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no log line matched /%s/", pattern)
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+// projectLogPaths returns the proc logs searched by a project-level
+// log_match check: those of the project's background processes started
+// since the given time, or still running from before (kept by if_running).
+// Logs left by earlier runs could hold a stale match and are not searched.
+func projectLogPaths(configName, projectName string, since time.Time) []string {
+	entries, _ := pidfile.Load(configName, projectName)
+	var paths []string
+	for _, e := range entries {
+		if e.StartedAt.Before(since) && e.Status() != pidfile.StatusRunning {
+			continue
+		}
+		if path, err := pidfile.ProcLogFilePath(configName, projectName, e.PID); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// TailFile returns the last n lines of the proc log at path without their
+// timestamps, or an empty string if it cannot be read.
+func TailFile(path string, n int) string {
+	const maxRead = 64 * 1024
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	offset := fi.Size() - maxRead
+	if offset < 0 {
+		offset = 0
+	}
+	buf := make([]byte, fi.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil {
+		return ""
+	}
+
+	content := strings.ReplaceAll(strings.TrimRight(string(buf), "\r\n"), "\r\n", "\n")
+	content = pidfile.StripLogTimestamps(content)
+	lines := strings.Split(content, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
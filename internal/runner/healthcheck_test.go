@@ -0,0 +1,261 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+)
+
+func TestProbeTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+
+	if err := probeTCP(addr, time.Second); err != nil {
+		t.Errorf("probeTCP() on open port error: %v", err)
+	}
+
+	_ = ln.Close()
+	if err := probeTCP(addr, time.Second); err == nil {
+		t.Error("probeTCP() on closed port should fail")
+	}
+}
+
+func TestProbeHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/teapot" {
+			w.WriteHeader(http.StatusTeapot)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		status  int
+		wantErr bool
+	}{
+		{name: "2xx without status", path: "/"},
+		{name: "exact status match", path: "/teapot", status: http.StatusTeapot},
+		{name: "4xx without status", path: "/teapot", wantErr: true},
+		{name: "status mismatch", path: "/", status: http.StatusNoContent, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := probeHTTP(srv.URL+tt.path, tt.status, time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("probeHTTP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProbeCommand(t *testing.T) {
+	dir := t.TempDir()
+	if err := probeCommand("true", dir, time.Second, nil); err != nil {
+		t.Errorf("probeCommand(true) error: %v", err)
+	}
+	err := probeCommand("echo not-ready >&2; exit 1", dir, time.Second, nil)
+	if err == nil {
+		t.Fatal("probeCommand() expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "not-ready") {
+		t.Errorf("error = %q, want containing command output", err.Error())
+	}
+}
+
+func TestProbeLog(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "1.log")
+	if err := os.WriteFile(logFile, []byte("starting\r\n\x1b[32mready\x1b[0m on port 3000\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := probeLog(`ready on port \d+`, []string{logFile}); err != nil {
+		t.Errorf("probeLog() error: %v", err)
+	}
+	if err := probeLog(`listening`, []string{logFile}); err == nil {
+		t.Error("probeLog() should fail when no line matches")
+	}
+
+	// The ready line was rotated out of the current log.
+	rotated := filepath.Join(dir, "2.log")
+	if err := os.WriteFile(rotated+".1", []byte("listening on :8080\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rotated, []byte("GET /health 200\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := probeLog(`listening on :\d+`, []string{rotated}); err != nil {
+		t.Errorf("probeLog() should search rotated segments: %v", err)
+	}
+}
+
+func TestTailFile(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "tail.log")
+	var content strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&content, "line%d\r\n", i)
+	}
+	if err := os.WriteFile(logFile, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Split(TailFile(logFile, 3), "\n")
+	if len(got) != 3 {
+		t.Fatalf("TailFile() returned %d lines, want 3: %q", len(got), got)
+	}
+	if got[0] != "line28" || got[2] != "line30" {
+		t.Errorf("TailFile() = %q, want line28..line30", got)
+	}
+	if TailFile(filepath.Join(dir, "missing.log"), 3) != "" {
+		t.Error("TailFile() on missing file should return empty string")
+	}
+}
+
+func TestWaitForHealthyTimeoutPrintsLogTail(t *testing.T) {
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	t.Cleanup(func() { logger.SetOutput(os.Stderr) })
+
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "1.log")
+	if err := os.WriteFile(logFile, []byte("Error: EADDRINUSE\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := config.Project{Name: "web", Path: dir}
+	hc := &config.HealthCheck{
+		LogMatch: "ready",
+		Interval: config.Duration(20 * time.Millisecond),
+		Timeout:  config.Duration(100 * time.Millisecond),
+	}
+	err := waitForHealthy(context.Background(), p, hc, []string{logFile}, 0, nil)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("error = %q, want containing 'timed out'", err.Error())
+	}
+	if !strings.Contains(stripANSI(buf.String()), "Error: EADDRINUSE") {
+		t.Errorf("output should contain the log tail: %q", buf.String())
+	}
+}
+
+func TestWaitForHealthyRetries(t *testing.T) {
+	p := config.Project{Name: "svc", Path: t.TempDir()}
+	hc := &config.HealthCheck{
+		Command:  "false",
+		Interval: config.Duration(10 * time.Millisecond),
+		Retries:  3,
+	}
+	err := waitForHealthy(context.Background(), p, hc, nil, 0, nil)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "gave up after 3 attempts") {
+		t.Errorf("error = %q, want containing 'gave up after 3 attempts'", err.Error())
+	}
+}
+
+func TestRunBackgroundHealthCheck(t *testing.T) {
+	dir := t.TempDir()
+	pidDir := filepath.Join(t.TempDir(), "pids")
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = pidDir
+	defer func() { pidfile.BaseDir = oldBaseDir }()
+
+	t.Run("ready", func(t *testing.T) {
+		cfg := &config.Config{
+			ExecutionMode: "sequential",
+			Projects: []config.Project{{
+				Name: "web",
+				Path: dir,
+				Commands: config.Commands{Up: []config.CommandItem{{
+					Command:     "sleep 0.2; echo server ready; sleep 60",
+					Background:  true,
+					HealthCheck: &config.HealthCheck{LogMatch: "server ready", Interval: config.Duration(50 * time.Millisecond), Timeout: config.Duration(5 * time.Second)},
+				}}},
+			}},
+		}
+		if err := Run(cfg, "up", "test-health"); err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		_ = pidfile.KillAll("test-health")
+	})
+
+	t.Run("process crashes", func(t *testing.T) {
+		cfg := &config.Config{
+			ExecutionMode: "parallel",
+			Projects: []config.Project{{
+				Name: "web",
+				Path: dir,
+				Commands: config.Commands{Up: []config.CommandItem{{
+					Command:     "echo boom; exit 1",
+					Background:  true,
+					HealthCheck: &config.HealthCheck{TCP: "127.0.0.1:1", Interval: config.Duration(50 * time.Millisecond), Timeout: config.Duration(10 * time.Second)},
+				}}},
+			}},
+		}
+		start := time.Now()
+		err := Run(cfg, "up", "test-health-crash")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "exited before becoming ready") {
+			t.Errorf("error = %q, want containing 'exited before becoming ready'", err.Error())
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Error("a crashed process should fail the health check before the timeout")
+		}
+		_ = pidfile.KillAll("test-health-crash")
+	})
+	t.Run("project log_match ignores stale logs", func(t *testing.T) {
+		// A log left by a process of an earlier run already matches.
+		logDir, err := pidfile.ProcLogDir("test-health-stale")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(logDir, "web"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(logDir, "web", "1.log"), []byte("server ready\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := &config.Config{
+			ExecutionMode: "sequential",
+			Projects: []config.Project{{
+				Name:        "web",
+				Path:        dir,
+				HealthCheck: &config.HealthCheck{LogMatch: "server ready", Interval: config.Duration(50 * time.Millisecond), Timeout: config.Duration(500 * time.Millisecond)},
+				Commands: config.Commands{Up: []config.CommandItem{{
+					Command:    "echo starting; sleep 60",
+					Background: true,
+				}}},
+			}},
+		}
+		err = Run(cfg, "up", "test-health-stale")
+		_ = pidfile.KillAll("test-health-stale")
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("Run() error = %v, want the health check to time out", err)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+
+	"mdc/internal/logger"
+)
+
+// ErrInterrupted is the cause of the cancellation of a run interrupted with
+// Ctrl-C; see WithInterrupts.
+var ErrInterrupted = errors.New("interrupted")
+
+// WithInterrupts returns a context that is canceled with ErrInterrupted on
+// the first SIGINT. Running foreground commands receive the SIGINT in turn
+// and are waited for, up to cancelGracePeriod (see setCancelGroup), and no
+// further commands are started. Any further
+// SIGINT kills the commands that are still running, along with their
+// children. stop restores the default handling of SIGINT.
+func WithInterrupts(parent context.Context) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		for n := 1; ; n++ {
+			select {
+			case <-sigCh:
+			case <-done:
+				return
+			}
+			if n == 1 {
+				logger.Interrupted()
+				cancel(ErrInterrupted)
+				continue
+			}
+			logger.ForceKill()
+			killCanceling()
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigCh)
+		close(done)
+		cancel(nil)
+	}
+}
+
+// canceling holds the process groups of the foreground commands that were
+// signalled because their run was canceled and have not exited yet.
+var canceling = struct {
+	sync.Mutex
+	groups map[int]bool
+}{groups: make(map[int]bool)}
+
+func markCanceling(pgid int) {
+	canceling.Lock()
+	defer canceling.Unlock()
+	canceling.groups[pgid] = true
+}
+
+// forgetCanceling is called once the command leading the group has exited,
+// so that a reused PID is never killed.
+func forgetCanceling(pgid int) {
+	canceling.Lock()
+	defer canceling.Unlock()
+	delete(canceling.groups, pgid)
+}
+
+// killCanceling kills the given process groups that are still canceling,
+// or all of them when none are given.
+func killCanceling(pgids ...int) {
+	canceling.Lock()
+	defer canceling.Unlock()
+	if len(pgids) == 0 {
+		for pgid := range canceling.groups {
+			pgids = append(pgids, pgid)
+		}
+	}
+	for _, pgid := range pgids {
+		if canceling.groups[pgid] {
+			signalGroup(pgid, true)
+		}
+	}
+}
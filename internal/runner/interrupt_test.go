@@ -0,0 +1,132 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/pidfile"
+)
+
+func TestWithInterrupts(t *testing.T) {
+	ctx, stop := WithInterrupts(context.Background())
+	defer stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not canceled by SIGINT")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, ErrInterrupted) {
+		t.Errorf("cause = %v, want ErrInterrupted", cause)
+	}
+}
+
+func TestRunInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	pidDir := t.TempDir()
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = pidDir
+	defer func() { pidfile.BaseDir = oldBaseDir }()
+
+	cfg := &config.Config{
+		ExecutionMode: "parallel",
+		Projects: []config.Project{
+			{
+				Name: "db",
+				Path: dir,
+				Commands: config.Commands{
+					Up:   []config.CommandItem{{Command: "touch db-up.txt"}},
+					Down: []config.CommandItem{{Command: "touch db-down.txt"}},
+				},
+			},
+			{
+				Name: "api",
+				Path: dir,
+				Commands: config.Commands{
+					// The shell must receive the SIGINT and is then waited for.
+					Up:   []config.CommandItem{{Command: "trap 'sleep 0.2; touch api-int.txt; exit 1' INT; sleep 10 & wait"}, {Command: "touch api-next.txt"}},
+					Down: []config.CommandItem{{Command: "touch api-down.txt"}},
+				},
+			},
+			{
+				Name:      "web",
+				Path:      dir,
+				DependsOn: []string{"api"},
+				Commands: config.Commands{
+					Up:   []config.CommandItem{{Command: "touch web-up.txt"}},
+					Down: []config.CommandItem{{Command: "touch web-down.txt"}},
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(300*time.Millisecond, func() { cancel(ErrInterrupted) })
+	var offered []string
+	err := RunContext(ctx, cfg, "up", "test-config", Options{ConfirmRollback: func(projects []string) bool {
+		offered = projects
+		return true
+	}})
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("err = %v, want ErrInterrupted", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "api-int.txt")); err != nil {
+		t.Errorf("the running command should receive SIGINT and be waited for: %v", err)
+	}
+	for _, name := range []string{"api-next.txt", "web-up.txt", "web-down.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist after the interrupt", name)
+		}
+	}
+	if !slices.Equal(offered, []string{"db", "api"}) {
+		t.Errorf("rollback offered for %v, want [db api]", offered)
+	}
+	for _, name := range []string{"db-down.txt", "api-down.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("confirmed rollback should run down: %v", err)
+		}
+	}
+}
+
+func TestRunInterruptedForceKill(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{
+			{
+				Name:     "api",
+				Path:     dir,
+				Commands: config.Commands{Up: []config.CommandItem{{Command: "trap '' INT; sleep 10"}}},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(200*time.Millisecond, func() { cancel(ErrInterrupted) })
+	// A second Ctrl-C.
+	time.AfterFunc(500*time.Millisecond, func() { killCanceling() })
+	start := time.Now()
+	err := RunContext(ctx, cfg, "up", "test-config", Options{})
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("err = %v, want ErrInterrupted", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("run took %s; the command was not killed", elapsed)
+	}
+	if !strings.Contains(stripANSI(err.Error()), "interrupted") {
+		t.Errorf("err = %q", err)
+	}
+}
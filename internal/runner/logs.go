@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ComposeLogsOptions are passed on to "docker compose logs".
+type ComposeLogsOptions struct {
+	Follow bool
+	// Tail is the number of lines to show per container (0 shows all).
+	Tail int
+	// Since is passed as is, so it accepts anything docker accepts
+	// (durations such as "10m" or timestamps).
+	Since   string
+	NoColor bool
+}
+
+func (o ComposeLogsOptions) args() []string {
+	args := []string{"logs"}
+	if o.Follow {
+		args = append(args, "--follow")
+	}
+	if o.Tail > 0 {
+		args = append(args, "--tail", strconv.Itoa(o.Tail))
+	}
+	if o.Since != "" {
+		args = append(args, "--since", o.Since)
+	}
+	if o.NoColor {
+		args = append(args, "--no-color")
+	}
+	return args
+}
+
+// StreamComposeLogs runs "docker compose logs" in dir and copies its output
+// to w until it exits or ctx is cancelled. A directory without a compose
+// file is not an error: there is simply nothing to show.
+func StreamComposeLogs(ctx context.Context, dir string, opts ComposeLogsOptions, w io.Writer) error {
+	err := execComposeLogs(ctx, dir, w, "docker", append([]string{"compose"}, opts.args()...)...)
+	if err != nil && isCommandNotFound(err) {
+		err = execComposeLogs(ctx, dir, w, "docker-compose", opts.args()...)
+	}
+	return err
+}
+
+func execComposeLogs(ctx context.Context, dir string, w io.Writer, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = w
+	cmd.WaitDelay = time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err == nil || ctx.Err() != nil || isNoComposeConfigError(stderr.String()) {
+		return nil
+	}
+	return fmt.Errorf("%w (stderr: %s)", err, strings.TrimSpace(stderr.String()))
+}
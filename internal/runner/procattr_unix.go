@@ -3,10 +3,70 @@
 package runner
 
 import (
+	"context"
+	"errors"
+	"os"
 	"os/exec"
 	"syscall"
+	"time"
+
+	"golang.org/x/term"
 )
 
 func setSysProcAttr(cmd *exec.Cmd) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 }
+
+// setCancelGroup runs cmd, created with exec.CommandContext(ctx), in its own
+// process group, so that the children of the shell stop with it. With pty,
+// the command runs in a session of its own whose terminal is the PTY on its
+// stdin. When ctx is interrupted (see WithInterrupts), the group receives
+// SIGINT, like from Ctrl-C in a terminal. When it is canceled otherwise, the
+// group receives SIGTERM, and SIGKILL if it is still running after
+// cancelGracePeriod. Wait returns at the latest cancelGracePeriod after the
+// cancellation, even if children still hold the output open.
+//
+// A command that reads from the terminal without a PTY stays in the
+// process group of mdc instead: outside the foreground process group of the
+// terminal, it would be stopped by SIGTTIN. It then receives Ctrl-C from the
+// terminal itself, and only the shell is signalled otherwise.
+func setCancelGroup(ctx context.Context, cmd *exec.Cmd, pty bool) {
+	cmd.WaitDelay = cancelGracePeriod
+	switch {
+	case pty:
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	case term.IsTerminal(int(os.Stdin.Fd())):
+		cmd.Cancel = func() error {
+			if errors.Is(context.Cause(ctx), ErrInterrupted) {
+				return nil
+			}
+			return cmd.Process.Signal(syscall.SIGTERM)
+		}
+		return
+	default:
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		markCanceling(pgid)
+		if errors.Is(context.Cause(ctx), ErrInterrupted) {
+			return syscall.Kill(-pgid, syscall.SIGINT)
+		}
+		time.AfterFunc(cancelGracePeriod, func() { killCanceling(pgid) })
+		return syscall.Kill(-pgid, syscall.SIGTERM)
+	}
+}
+
+// leadsGroup reports whether cmd was started in a process group of its own.
+func leadsGroup(cmd *exec.Cmd) bool {
+	return cmd.SysProcAttr != nil && (cmd.SysProcAttr.Setpgid || cmd.SysProcAttr.Setsid)
+}
+
+// signalGroup sends SIGTERM, or SIGKILL with force, to a process group.
+func signalGroup(pgid int, force bool) {
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	_ = syscall.Kill(-pgid, sig)
+}
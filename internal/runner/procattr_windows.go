@@ -2,9 +2,46 @@
 
 package runner
 
-import "os/exec"
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+)
 
 func setSysProcAttr(cmd *exec.Cmd) {
 	// On Windows, child processes are already independent from the parent
 	// process group by default. No additional configuration is needed.
 }
+
+// setCancelGroup lets the cancellation of ctx kill cmd, created with
+// exec.CommandContext(ctx). Windows has no process groups to signal, so
+// children of the shell may outlive it; on Ctrl-C, they receive the console
+// interrupt themselves.
+func setCancelGroup(ctx context.Context, cmd *exec.Cmd, _ bool) {
+	cmd.Cancel = func() error {
+		markCanceling(cmd.Process.Pid)
+		if errors.Is(context.Cause(ctx), ErrInterrupted) {
+			return nil
+		}
+		return cmd.Process.Kill()
+	}
+	cmd.WaitDelay = cancelGracePeriod
+}
+
+// leadsGroup reports whether cmd was started in a process group of its own,
+// which is never the case on Windows.
+func leadsGroup(_ *exec.Cmd) bool {
+	return false
+}
+
+// signalGroup kills the process with force. Without process groups, there
+// is nothing else to signal.
+func signalGroup(pid int, force bool) {
+	if !force {
+		return
+	}
+	if p, err := os.FindProcess(pid); err == nil {
+		_ = p.Kill()
+	}
+}
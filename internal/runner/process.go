@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+)
+
+// StopProcess stops a tracked background process. The entry is untracked
+// first so that a running supervisor does not restart it.
+func StopProcess(configName, projectName string, entry pidfile.Entry) {
+	logger.Stop(projectName, entry.Command, entry.PID)
+	pidfile.RecordStop(configName, projectName, entry)
+	if err := pidfile.RemoveEntry(configName, projectName, entry.PID); err != nil {
+		logger.Warn(projectName, fmt.Sprintf("failed to remove PID entry: %v", err))
+	}
+	killEntry(projectName, entry)
+	logger.Stopped(projectName)
+}
+
+// killEntry stops the entry's process tree unless its PID now belongs to
+// another process, and reports processes that had to be killed.
+func killEntry(projectName string, entry pidfile.Entry) {
+	survivors, err := pidfile.KillEntry(entry)
+	if errors.Is(err, pidfile.ErrStale) {
+		logger.Warn(projectName, fmt.Sprintf("PID %d: %v", entry.PID, err))
+	}
+	if len(survivors) > 0 {
+		logger.Warn(projectName, pidfile.SurvivorMessage(entry, survivors))
+	}
+}
+
+// RestartProcess stops a tracked background process and starts its command
+// again with the same directory and environment. It returns the new entry.
+func RestartProcess(configName, projectName string, entry pidfile.Entry) (pidfile.Entry, error) {
+	logger.Stop(projectName, entry.Command, entry.PID)
+	pidfile.RecordGone(configName, projectName, entry)
+	if err := pidfile.RemoveEntry(configName, projectName, entry.PID); err != nil {
+		logger.Warn(projectName, fmt.Sprintf("failed to remove old PID entry: %v", err))
+	}
+	killEntry(projectName, entry)
+
+	tmpLog, _ := pidfile.ProcLogTmpPath(configName, projectName)
+	bp, err := SpawnBackgroundProcess(entry.Command, entry.Dir, SpawnOptions{
+		LogFile:  tmpLog,
+		Env:      entry.Env,
+		Rotation: entry.Rotation,
+	})
+	if err != nil {
+		logger.Error(projectName, entry.Command, err)
+		return pidfile.Entry{}, fmt.Errorf("failed to restart %q: %w", entry.Command, err)
+	}
+	if _, err := pidfile.RenameProcLog(tmpLog, bp.PID); err != nil {
+		logger.Warn(projectName, fmt.Sprintf("log rename failed: %v", err))
+	}
+
+	next := entry
+	next.PID = bp.PID
+	next.Identity = pidfile.Identify(bp.PID)
+	next.StartedAt = time.Now()
+	next.Exit = nil
+	if err := pidfile.Append(configName, projectName, next); err != nil {
+		logger.Warn(projectName, fmt.Sprintf("failed to save new PID entry: %v", err))
+	}
+	pidfile.RecordRestart(configName, projectName, entry.PID, next)
+	logger.Background(projectName, entry.Command, next.PID)
+	return next, nil
+}
@@ -54,7 +54,9 @@ func execWithPTY(cmd *exec.Cmd, buffered bool) (output string, err error) {
 	}
 
 	go func() {
-		_, _ = io.Copy(ptmx, os.Stdin)
+		// Hiding ptmx.ReadFrom keeps io.Copy from holding ptmx while it
+		// waits for input, which would block the deferred Close.
+		_, _ = io.Copy(struct{ io.Writer }{ptmx}, os.Stdin)
 	}()
 	_, _ = io.Copy(os.Stdout, ptmx)
 
@@ -3,10 +3,14 @@
 package runner
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"strings"
 	"testing"
+
+	"github.com/creack/pty/v2"
+	"golang.org/x/term"
 )
 
 func TestIsTerminal(t *testing.T) {
@@ -79,3 +83,46 @@ func TestHasPTYSupport(t *testing.T) {
 		t.Error("hasPTYSupport() should be true on Unix")
 	}
 }
+
+func TestForegroundCommandInPTYOwnsTerminal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// /dev/tty only opens for a process with a controlling terminal.
+	cmd := newForegroundCommand(ctx, "echo via-tty > /dev/tty", t.TempDir(), true)
+	output, err := execWithPTY(cmd, true)
+	if err != nil {
+		t.Fatalf("execWithPTY() error: %v (output %q)", err, output)
+	}
+	if !strings.Contains(output, "via-tty") {
+		t.Errorf("output = %q, want the PTY as the controlling terminal", output)
+	}
+	if !leadsGroup(cmd) {
+		t.Error("a command in a PTY should lead its own process group")
+	}
+}
+
+func TestForegroundCommandReadingTerminalKeepsGroup(t *testing.T) {
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		t.Skipf("pty open: %v", err)
+	}
+	defer func() { _ = ptmx.Close(); _ = tty.Close() }()
+	oldStdin := os.Stdin
+	os.Stdin = tty
+	defer func() { os.Stdin = oldStdin }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if cmd := newForegroundCommand(ctx, "cat", t.TempDir(), false); leadsGroup(cmd) {
+		t.Error("a command reading the terminal must stay in its foreground process group")
+	}
+
+	os.Stdin = oldStdin
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	if cmd := newForegroundCommand(ctx, "cat", t.TempDir(), false); !leadsGroup(cmd) {
+		t.Error("a command that does not read the terminal should lead its own process group")
+	}
+}
@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+)
+
+// Restart restarts the projects of cfg one at a time, dependencies first, so
+// that a project is back up before the projects that depend on it restart.
+// A project with restart commands runs them and then restarts its tracked
+// background processes; any other project runs its down commands, has its
+// background processes stopped and runs its up commands.
+//
+// The first failure stops the restart. With keepGoing, the remaining
+// projects are restarted anyway, except those that depend on a failed one.
+func Restart(cfg *config.Config, configName string, keepGoing bool) error {
+	pcs := make([]projectCommands, len(cfg.Projects))
+	for i, p := range cfg.Projects {
+		if len(p.Commands.Restart) == 0 && len(p.Commands.Up) == 0 {
+			return fmt.Errorf("project %q: no commands defined for %q", p.Name, "up")
+		}
+		env, err := cfg.ProjectEnv(p)
+		if err != nil {
+			return err
+		}
+		pcs[i] = projectCommands{Project: p, HealthCheck: p.HealthCheck, Env: env, Rotation: pidfile.RotationOf(cfg.Logs)}
+	}
+
+	nodes := buildDAG(pcs, false)
+	order := topoOrder(nodes)
+	errs := make([]error, len(pcs))
+	skipped := make([]bool, len(pcs))
+	results := make([]logger.ProjectResult, 0, len(order))
+
+	for i, idx := range order {
+		pc := pcs[idx]
+		if skipped[idx] {
+			results = append(results, logger.ProjectResult{Name: pc.Project.Name, State: logger.StateSkipped, Detail: errs[idx].Error()})
+			continue
+		}
+		err := restartProject(pc, configName, cfg.Logs.KeepStopped)
+		if err == nil {
+			results = append(results, logger.ProjectResult{Name: pc.Project.Name, State: logger.StateCompleted})
+			continue
+		}
+		errs[idx] = err
+		results = append(results, logger.ProjectResult{Name: pc.Project.Name, State: logger.StateFailed, Detail: err.Error()})
+		if !keepGoing {
+			for _, rest := range order[i+1:] {
+				results = append(results, logger.ProjectResult{Name: pcs[rest].Project.Name, State: logger.StateNotStarted})
+			}
+			logger.Summary("restart", results)
+			return err
+		}
+		skipDependents(pcs, nodes, idx, skipped, errs)
+	}
+	logger.Summary("restart", results)
+
+	var failures []string
+	for _, err := range errs {
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("some projects failed:\n  %s", strings.Join(failures, "\n  "))
+	}
+	return nil
+}
+
+// restartProject restarts a single project; see Restart.
+func restartProject(pc projectCommands, configName string, keepLogs bool) error {
+	if err := validateProjectPath(pc.Project); err != nil {
+		return err
+	}
+	buffered := bufferOutput()
+	ctx := context.Background()
+
+	if restart := pc.Project.Commands.Restart; len(restart) > 0 {
+		start := time.Now()
+		// Only the processes running before the restart commands are
+		// restarted; background commands in restart start them only once.
+		entries, err := pidfile.Load(configName, pc.Project.Name)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("project %q: %w", pc.Project.Name, err)
+		}
+		pc.Commands = restart
+		if _, err := runCommands(ctx, pc, configName, buffered); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if _, err := RestartProcess(configName, pc.Project.Name, e); err != nil {
+				return fmt.Errorf("project %q: %w", pc.Project.Name, err)
+			}
+		}
+		return finishProject(ctx, pc, configName, start)
+	}
+
+	pc.Commands = pc.Project.Commands.Down
+	if _, err := runCommands(ctx, pc, configName, buffered); err != nil {
+		return err
+	}
+	if err := pidfile.KillProjectsWithCallback(configName, []string{pc.Project.Name}, logger.Stop, keepLogs); err != nil {
+		return fmt.Errorf("project %q: failed to stop background processes: %w", pc.Project.Name, err)
+	}
+	pc.Commands = pc.Project.Commands.Up
+	_, err := runProject(ctx, pc, configName, buffered)
+	return err
+}
@@ -0,0 +1,182 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mdc/internal/config"
+	"mdc/internal/pidfile"
+)
+
+func TestRestart(t *testing.T) {
+	dir := t.TempDir()
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = t.TempDir()
+	defer func() { pidfile.BaseDir = oldBaseDir }()
+
+	cfg := &config.Config{
+		ExecutionMode: "parallel",
+		Projects: []config.Project{
+			{
+				Name:      "api",
+				Path:      dir,
+				DependsOn: []string{"db"},
+				Commands: config.Commands{
+					Up:      []config.CommandItem{{Command: "sleep 60", Background: true}},
+					Restart: []config.CommandItem{{Command: "echo api-restart >> order.txt"}},
+				},
+			},
+			{
+				Name: "db",
+				Path: dir,
+				Commands: config.Commands{
+					Up:   []config.CommandItem{{Command: "echo db-up >> order.txt"}, {Command: "sleep 60", Background: true}},
+					Down: []config.CommandItem{{Command: "echo db-down >> order.txt"}},
+				},
+			},
+		},
+	}
+	if err := Run(cfg, "up", "test-restart"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	before, err := pidfile.LoadAll("test-restart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = pidfile.KillAll("test-restart") }()
+	if err := os.Remove(filepath.Join(dir, "order.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Restart(cfg, "test-restart", false); err != nil {
+		t.Fatalf("Restart() error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "order.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(strings.Fields(string(data)), ","); got != "db-down,db-up,api-restart" {
+		t.Errorf("restart order = %s, want db-down,db-up,api-restart", got)
+	}
+
+	after, err := pidfile.LoadAll("test-restart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, project := range []string{"api", "db"} {
+		if len(after[project]) != 1 {
+			t.Fatalf("%s has %d entries after restart, want 1", project, len(after[project]))
+		}
+		if after[project][0].PID == before[project][0].PID {
+			t.Errorf("%s background process was not restarted", project)
+		}
+		if pidfile.IsRunning(before[project][0].PID) {
+			t.Errorf("%s old background process is still running", project)
+		}
+	}
+}
+
+func TestRestartStartsBackgroundRestartCommandOnce(t *testing.T) {
+	dir := t.TempDir()
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = t.TempDir()
+	defer func() { pidfile.BaseDir = oldBaseDir }()
+
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{{
+			Name: "api",
+			Path: dir,
+			Commands: config.Commands{
+				Up:      []config.CommandItem{{Command: "sleep 60", Background: true}},
+				Restart: []config.CommandItem{{Command: "echo start >> starts.txt; sleep 60", Background: true}},
+			},
+		}},
+	}
+	if err := Run(cfg, "up", "test-restart-bg"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	defer func() { _ = pidfile.KillAll("test-restart-bg") }()
+
+	if err := Restart(cfg, "test-restart-bg", false); err != nil {
+		t.Fatalf("Restart() error: %v", err)
+	}
+
+	entries, err := pidfile.Load("test-restart-bg", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("api has %d entries after restart, want 2", len(entries))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "starts.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "start"); n != 1 {
+		t.Errorf("background restart command started %d times, want 1", n)
+	}
+	for _, e := range entries {
+		if !pidfile.IsRunning(e.PID) {
+			t.Errorf("process %d (%s) is not running", e.PID, e.Command)
+		}
+	}
+}
+
+func TestRestartFailure(t *testing.T) {
+	cfg := func(dir string) *config.Config {
+		return &config.Config{
+			ExecutionMode: "sequential",
+			Projects: []config.Project{
+				{
+					Name:     "db",
+					Path:     dir,
+					Commands: config.Commands{Up: []config.CommandItem{{Command: "true"}}, Down: []config.CommandItem{{Command: "false"}}},
+				},
+				{
+					Name:      "api",
+					Path:      dir,
+					DependsOn: []string{"db"},
+					Commands:  config.Commands{Up: []config.CommandItem{{Command: "touch api.txt"}}},
+				},
+				{
+					Name:     "docs",
+					Path:     dir,
+					Commands: config.Commands{Up: []config.CommandItem{{Command: "touch docs.txt"}}},
+				},
+			},
+		}
+	}
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = t.TempDir()
+	defer func() { pidfile.BaseDir = oldBaseDir }()
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := Restart(cfg(dir), "test-restart", false); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		for _, name := range []string{"api.txt", "docs.txt"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+				t.Errorf("%s should not exist after the first failure", name)
+			}
+		}
+	})
+
+	t.Run("keep going", func(t *testing.T) {
+		dir := t.TempDir()
+		err := Restart(cfg(dir), "test-restart", true)
+		if err == nil || !strings.Contains(err.Error(), `project "api": skipped because dependency "db" failed`) {
+			t.Fatalf("error = %v, want api skipped", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "api.txt")); !os.IsNotExist(err) {
+			t.Error("api.txt should not exist when a dependency failed")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "docs.txt")); err != nil {
+			t.Errorf("independent project should still restart: %v", err)
+		}
+	})
+}
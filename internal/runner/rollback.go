@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"mdc/internal/config"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+)
+
+// startedProjects returns the projects of a run that were started,
+// including the ones that failed or were stopped half-way.
+func startedProjects(results []logger.ProjectResult) []string {
+	var started []string
+	for _, r := range results {
+		switch r.State {
+		case logger.StateCompleted, logger.StateFailed, logger.StateCanceled, logger.StateInterrupted:
+			started = append(started, r.Name)
+		}
+	}
+	return started
+}
+
+// rollback undoes a failed "up": the given projects run their down commands
+// in reverse dependency order and have their background processes stopped.
+// Failures do not stop the rollback; they are reported in its summary.
+func rollback(cfg *config.Config, configName string, projects []string) {
+	started := make(map[string]bool)
+	for _, name := range projects {
+		started[name] = true
+	}
+
+	var pcs []projectCommands
+	var names []string
+	for _, p := range cfg.Projects {
+		if started[p.Name] {
+			pcs = append(pcs, projectCommands{Project: p, Commands: p.Commands.Down})
+			names = append(names, p.Name)
+		}
+	}
+	logger.Rollback(names)
+
+	rolledBack := make([]logger.ProjectResult, 0, len(pcs))
+	for _, idx := range topoOrder(buildDAG(pcs, true)) {
+		err := rollbackProject(cfg, configName, pcs[idx])
+		res := logger.ProjectResult{Name: pcs[idx].Project.Name, State: logger.StateCompleted}
+		if err != nil {
+			res.State = logger.StateFailed
+			res.Detail = err.Error()
+		}
+		rolledBack = append(rolledBack, res)
+	}
+	logger.Summary("rollback", rolledBack)
+}
+
+// rollbackProject runs the down commands of a project and stops its
+// background processes, even when the down commands fail.
+func rollbackProject(cfg *config.Config, configName string, pc projectCommands) error {
+	env, err := cfg.ProjectEnv(pc.Project)
+	if err == nil {
+		pc.Env = env
+		err = validateProjectPath(pc.Project)
+	}
+	if err == nil {
+		_, err = runCommands(context.Background(), pc, configName, bufferOutput())
+	}
+	if killErr := pidfile.KillProjectsWithCallback(configName, []string{pc.Project.Name}, logger.Stop, cfg.Logs.KeepStopped); killErr != nil && err == nil {
+		err = fmt.Errorf("project %q: failed to stop background processes: %w", pc.Project.Name, killErr)
+	}
+	return err
+}
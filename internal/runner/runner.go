@@ -2,12 +2,13 @@ package runner
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"sync"
 	"time"
 
 	"mdc/internal/config"
@@ -15,9 +16,42 @@ import (
 	"mdc/internal/pidfile"
 )
 
+// cancelGracePeriod is how long a canceled foreground command has to exit
+// after SIGTERM before it is killed.
+const cancelGracePeriod = 5 * time.Second
+
+// errCanceled marks the errors of commands and projects that were stopped
+// before they finished, and errNotStarted those of projects that were never
+// started, because the run was canceled.
+var (
+	errCanceled   = errors.New("canceled")
+	errNotStarted = errors.New("not started")
+)
+
+// Options configures RunContext.
+type Options struct {
+	// FailFast cancels the commands of the other projects as soon as a
+	// project fails in parallel mode. Sequential runs always stop at the
+	// first failure.
+	FailFast bool
+	// RollbackOnFailure runs the down commands and stops the background
+	// processes of the projects that were started when "up" fails.
+	RollbackOnFailure bool
+	// ConfirmRollback, when set, is asked whether to roll back the projects
+	// that were started when "up" is interrupted without RollbackOnFailure.
+	ConfirmRollback func(projects []string) bool
+}
+
 type projectCommands struct {
 	Project  config.Project
 	Commands []config.CommandItem
+	// HealthCheck is the project-level readiness check, only set for "up".
+	HealthCheck *config.HealthCheck
+	// Env holds the config- and project-level environment variables.
+	Env map[string]string
+	// Rotation holds the limits at which the logs of background commands
+	// are rotated.
+	Rotation pidfile.LogRotation
 }
 
 func DryRun(cfg *config.Config, action string) error {
@@ -36,6 +70,14 @@ func DryRun(cfg *config.Config, action string) error {
 			invalidPaths = append(invalidPaths, fmt.Sprintf("project %q: %s", pc.Project.Name, pc.Project.Path))
 		}
 		logger.DryRunProject(pc.Project.Name, pc.Project.Path, pc.Commands, warning)
+		for _, item := range pc.Commands {
+			if _, err := config.CommandEnv(pc.Env, item); err != nil {
+				return fmt.Errorf("project %q: %w", pc.Project.Name, err)
+			}
+		}
+	}
+	for _, name := range skippedProjects(cfg, action) {
+		logger.ProjectSkipped(name, fmt.Sprintf("no %q commands", action))
 	}
 
 	if len(invalidPaths) > 0 {
@@ -45,177 +87,572 @@ func DryRun(cfg *config.Config, action string) error {
 }
 
 func Run(cfg *config.Config, action string, configName string) error {
+	return RunContext(context.Background(), cfg, action, configName, Options{})
+}
+
+// RunContext is Run with options. Canceling ctx stops the commands that are
+// running and skips the rest; a run interrupted by WithInterrupts returns
+// ErrInterrupted once the summary is printed.
+func RunContext(ctx context.Context, cfg *config.Config, action string, configName string, opts Options) error {
 	pcs, err := commandsForAction(cfg, action)
 	if err != nil {
 		return err
 	}
 
+	reverse := action == config.ActionDown
+
+	var results []logger.ProjectResult
+	for _, name := range skippedProjects(cfg, action) {
+		reason := fmt.Sprintf("no %q commands", action)
+		logger.ProjectSkipped(name, reason)
+		results = append(results, logger.ProjectResult{Name: name, State: logger.StateSkipped, Detail: reason})
+	}
+
+	var ran []logger.ProjectResult
 	switch cfg.ExecutionMode {
 	case "sequential":
-		return runSequential(pcs, configName)
+		ran, err = runSequential(ctx, pcs, configName, reverse)
 	case "parallel":
-		return runParallel(pcs, configName)
+		ran, err = runParallel(ctx, pcs, configName, reverse, opts.FailFast)
 	default:
 		return fmt.Errorf("unknown execution_mode: %q", cfg.ExecutionMode)
 	}
+	results = append(ran, results...)
+
+	if len(results) > 0 {
+		logger.Summary(action, results)
+	}
+	interrupted := errors.Is(context.Cause(ctx), ErrInterrupted)
+	if interrupted {
+		err = ErrInterrupted
+	}
+	if err != nil && action == config.ActionUp {
+		started := startedProjects(ran)
+		switch {
+		case len(started) == 0:
+		case opts.RollbackOnFailure:
+			rollback(cfg, configName, started)
+		case interrupted && opts.ConfirmRollback != nil && opts.ConfirmRollback(started):
+			rollback(cfg, configName, started)
+		}
+	}
+	return err
 }
 
+// commandsForAction returns the commands of the action for every project.
+// Every project must define up and down, while projects without commands for
+// a named action are left out (see skippedProjects).
 func commandsForAction(cfg *config.Config, action string) ([]projectCommands, error) {
-	result := make([]projectCommands, len(cfg.Projects))
-	for i, p := range cfg.Projects {
-		var cmds []config.CommandItem
-		switch action {
-		case "up":
-			cmds = p.Commands.Up
-		case "down":
-			cmds = p.Commands.Down
-		default:
-			return nil, fmt.Errorf("unknown action: %q", action)
-		}
+	builtin := action == config.ActionUp || action == config.ActionDown
+	if !builtin && len(cfg.ProjectsWithAction(action)) == 0 {
+		return nil, fmt.Errorf("unknown action: %q", action)
+	}
+
+	result := make([]projectCommands, 0, len(cfg.Projects))
+	for _, p := range cfg.Projects {
+		cmds := p.Commands.ForAction(action)
 		if len(cmds) == 0 {
+			if !builtin {
+				continue
+			}
 			return nil, fmt.Errorf("project %q: no commands defined for %q", p.Name, action)
 		}
-		result[i] = projectCommands{Project: p, Commands: cmds}
+		env, err := cfg.ProjectEnv(p)
+		if err != nil {
+			return nil, err
+		}
+		pc := projectCommands{Project: p, Commands: cmds, Env: env, Rotation: pidfile.RotationOf(cfg.Logs)}
+		if action == config.ActionUp {
+			pc.HealthCheck = p.HealthCheck
+		}
+		result = append(result, pc)
 	}
 	return result, nil
 }
 
-func runSequential(pcs []projectCommands, configName string) error {
-	for _, pc := range pcs {
-		if err := validateProjectPath(pc.Project); err != nil {
-			return err
+// skippedProjects returns the projects that do not define a named action.
+func skippedProjects(cfg *config.Config, action string) []string {
+	defined := make(map[string]bool)
+	for _, name := range cfg.ProjectsWithAction(action) {
+		defined[name] = true
+	}
+	var skipped []string
+	for _, p := range cfg.Projects {
+		if !defined[p.Name] {
+			skipped = append(skipped, p.Name)
 		}
-		for _, item := range pc.Commands {
-			if err := execCommand(pc.Project, item, configName, false); err != nil {
-				return err
+	}
+	return skipped
+}
+
+// bufferOutput reports whether commands that would stream their output to
+// the terminal must buffer it instead. Raw command output would corrupt a
+// structured event stream, so it is buffered whenever the logger emits one.
+func bufferOutput() bool {
+	return logger.Structured()
+}
+
+// runSequential processes projects one at a time in dependency order.
+// Projects without depends_on keep their definition order.
+func runSequential(ctx context.Context, pcs []projectCommands, configName string, reverse bool) ([]logger.ProjectResult, error) {
+	order := topoOrder(buildDAG(pcs, reverse))
+	results := make([]logger.ProjectResult, 0, len(order))
+
+	for i, idx := range order {
+		pc := pcs[idx]
+		var running []string
+		err := validateProjectPath(pc.Project)
+		if err == nil {
+			running, err = runProject(ctx, pc, configName, bufferOutput())
+		}
+		if err != nil {
+			state := logger.StateFailed
+			if errors.Is(err, errCanceled) {
+				state = canceledState(ctx)
 			}
+			results = append(results, logger.ProjectResult{Name: pc.Project.Name, State: state, Detail: err.Error()})
+			for _, rest := range order[i+1:] {
+				results = append(results, logger.ProjectResult{Name: pcs[rest].Project.Name, State: logger.StateNotStarted})
+			}
+			return results, err
 		}
-		logger.ProjectDone(pc.Project.Name)
+		results = append(results, logger.ProjectResult{Name: pc.Project.Name, State: logger.StateCompleted, Detail: alreadyRunningDetail(running)})
 	}
-	return nil
+	return results, nil
 }
 
-func runParallel(pcs []projectCommands, configName string) error {
+// runParallel schedules projects as a dependency graph: every project whose
+// dependencies have completed runs concurrently with the others. With
+// failFast, the first failure cancels the projects that are running, and
+// those that have not started yet are not started.
+func runParallel(ctx context.Context, pcs []projectCommands, configName string, reverse, failFast bool) ([]logger.ProjectResult, error) {
 	for _, pc := range pcs {
 		if err := validateProjectPath(pc.Project); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	var wg sync.WaitGroup
-	errs := make([]error, len(pcs))
-
+	index := make(map[string]int, len(pcs))
 	for i, pc := range pcs {
-		wg.Add(1)
-		go func(idx int, pc projectCommands) {
-			defer wg.Done()
-			errs[idx] = runProjectBuffered(pc, configName)
-		}(i, pc)
+		index[pc.Project.Name] = i
 	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Each project writes only its own element.
+	running := make([][]string, len(pcs))
+	errs, skipped := runDAG(pcs, buildDAG(pcs, reverse), func(pc projectCommands) error {
+		if ctx.Err() != nil {
+			return fmt.Errorf("project %q %w: %v", pc.Project.Name, errNotStarted, context.Cause(ctx))
+		}
+		var err error
+		running[index[pc.Project.Name]], err = runProject(ctx, pc, configName, true)
+		if err != nil && failFast && !errors.Is(err, errCanceled) {
+			cancel(fmt.Errorf("project %q failed", pc.Project.Name))
+		}
+		return err
+	})
 
-	wg.Wait()
-
+	results := make([]logger.ProjectResult, len(pcs))
 	var failures []string
-	for _, err := range errs {
-		if err != nil {
-			failures = append(failures, err.Error())
+	for i, err := range errs {
+		results[i] = logger.ProjectResult{Name: pcs[i].Project.Name, State: logger.StateCompleted, Detail: alreadyRunningDetail(running[i])}
+		if err == nil {
+			continue
+		}
+		results[i].Detail = err.Error()
+		switch {
+		case skipped[i]:
+			results[i].State = logger.StateSkipped
+		case errors.Is(err, errNotStarted):
+			results[i].State = logger.StateNotStarted
+			continue
+		case errors.Is(err, errCanceled):
+			results[i].State = canceledState(ctx)
+			continue
+		default:
+			results[i].State = logger.StateFailed
 		}
+		failures = append(failures, err.Error())
 	}
 	if len(failures) > 0 {
-		return fmt.Errorf("some projects failed:\n  %s", strings.Join(failures, "\n  "))
+		return results, fmt.Errorf("some projects failed:\n  %s", strings.Join(failures, "\n  "))
 	}
-	return nil
+	return results, nil
+}
+
+// canceledState is the summary state of a project stopped because ctx was
+// canceled.
+func canceledState(ctx context.Context) string {
+	if errors.Is(context.Cause(ctx), ErrInterrupted) {
+		return logger.StateInterrupted
+	}
+	return logger.StateCanceled
+}
+
+// runProject executes the project's commands in order and, when configured,
+// waits for the project-level health check to pass. It returns the
+// background commands that were skipped because they were already running.
+func runProject(ctx context.Context, pc projectCommands, configName string, buffered bool) ([]string, error) {
+	start := time.Now()
+	running, err := runCommands(ctx, pc, configName, buffered)
+	if err != nil {
+		return running, err
+	}
+	return running, finishProject(ctx, pc, configName, start)
 }
 
-func runProjectBuffered(pc projectCommands, configName string) error {
+// runCommands executes the project's commands in order. Background commands
+// that are already running are handled according to their if_running
+// policy; the ones that were skipped are returned.
+func runCommands(ctx context.Context, pc projectCommands, configName string, buffered bool) ([]string, error) {
+	var running []string
+	var tracked *trackedProcs
 	for _, item := range pc.Commands {
-		if err := execCommand(pc.Project, item, configName, true); err != nil {
-			return err
+		if ctx.Err() != nil {
+			return running, fmt.Errorf("project %q %w: %v", pc.Project.Name, errCanceled, context.Cause(ctx))
+		}
+		env, err := config.CommandEnv(pc.Env, item)
+		if err != nil {
+			return running, fmt.Errorf("project %q: %w", pc.Project.Name, err)
+		}
+		if item.Background {
+			if tracked == nil {
+				tracked = loadTrackedProcs(configName, pc.Project)
+			}
+			skip, err := applyIfRunning(pc.Project, item, configName, tracked)
+			if err != nil {
+				return running, err
+			}
+			if skip != "" {
+				running = append(running, skip)
+				continue
+			}
+		}
+		if err := execCommand(ctx, pc.Project, item, env, pc.Rotation, configName, buffered); err != nil {
+			return running, err
+		}
+	}
+	return running, nil
+}
+
+// applyIfRunning applies the command's if_running policy when the same
+// command of the project is already running from the project directory. For
+// a skipped command, it returns a description of the running process.
+func applyIfRunning(p config.Project, item config.CommandItem, configName string, tracked *trackedProcs) (string, error) {
+	running := tracked.matching(item.Command, p.Path)
+	if len(running) == 0 {
+		return "", nil
+	}
+	switch item.IfRunningPolicy() {
+	case config.IfRunningRecreate:
+		for _, e := range running {
+			tracked.claim(e)
+			StopProcess(configName, p.Name, e)
+		}
+		return "", nil
+	case config.IfRunningFail:
+		err := fmt.Errorf("already running (PID %d)", running[0].PID)
+		logger.Error(p.Name, item.Command, err)
+		return "", fmt.Errorf("project %q: background command %q is %w; use --recreate to restart it", p.Name, item.Command, err)
+	default:
+		tracked.claim(running[0])
+		logger.AlreadyRunning(p.Name, item.Command, running[0].PID)
+		return fmt.Sprintf("%s (PID %d)", item.Command, running[0].PID), nil
+	}
+}
+
+// trackedProcs holds the processes of a project that were running before
+// its commands started. Each process is claimed by at most one command, so
+// that a command listed twice is started twice.
+type trackedProcs struct {
+	entries []pidfile.Entry
+	claimed map[int]bool
+}
+
+func loadTrackedProcs(configName string, p config.Project) *trackedProcs {
+	t := &trackedProcs{claimed: make(map[int]bool)}
+	entries, err := pidfile.Load(configName, p.Name)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warn(p.Name, fmt.Sprintf("failed to check for running processes: %v", err))
+	}
+	for _, e := range entries {
+		if e.Status() == pidfile.StatusRunning {
+			t.entries = append(t.entries, e)
+		}
+	}
+	return t
+}
+
+// matching returns the unclaimed processes started with the command from dir.
+func (t *trackedProcs) matching(command, dir string) []pidfile.Entry {
+	var result []pidfile.Entry
+	for _, e := range t.entries {
+		if e.Command == command && e.Dir == dir && !t.claimed[e.PID] {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func (t *trackedProcs) claim(e pidfile.Entry) {
+	t.claimed[e.PID] = true
+}
+
+// alreadyRunningDetail describes the skipped background commands of a
+// project for the summary.
+func alreadyRunningDetail(running []string) string {
+	if len(running) == 0 {
+		return ""
+	}
+	return "already running: " + strings.Join(running, ", ")
+}
+
+// finishProject waits for the project-level health check, if any, and
+// reports the project as done. A log_match check searches the logs of the
+// background processes started since start (see projectLogPaths).
+func finishProject(ctx context.Context, pc projectCommands, configName string, start time.Time) error {
+	if pc.HealthCheck != nil {
+		logPaths := projectLogPaths(configName, pc.Project.Name, start)
+		if err := waitForHealthy(ctx, pc.Project, pc.HealthCheck, logPaths, 0, pc.Env); err != nil {
+			return fmt.Errorf("project %q: %w", pc.Project.Name, err)
 		}
 	}
 	logger.ProjectDone(pc.Project.Name)
 	return nil
 }
 
-func execCommand(p config.Project, item config.CommandItem, configName string, buffered bool) error {
-	logger.Start(p.Name, item.Command)
-
+// execCommand runs a command of the project. A foreground command is
+// stopped, along with its children, when ctx is canceled or its timeout
+// expires (see newForegroundCommand), and retried according to its
+// policy. With continue_on_error, a command that still fails is reported
+// but does not fail the project.
+func execCommand(ctx context.Context, p config.Project, item config.CommandItem, env map[string]string, rotation pidfile.LogRotation, configName string, buffered bool) error {
+	var err error
 	if item.Background {
-		return execBackgroundCommand(p, item, configName)
+		logger.Start(p.Name, item.Command)
+		err = execBackgroundCommand(ctx, p, item, env, rotation, configName)
+	} else {
+		err = execForegroundAttempts(ctx, p, item, env, buffered)
+	}
+	if err != nil && item.ContinuesOnError() && !errors.Is(err, errCanceled) {
+		logger.Warn(p.Name, fmt.Sprintf("continuing after %q failed (continue_on_error)", item.Command))
+		return nil
+	}
+	return err
+}
+
+// execForegroundAttempts runs a foreground command until it succeeds or
+// has used up its retries, waiting longer before every retry.
+func execForegroundAttempts(ctx context.Context, p config.Project, item config.CommandItem, env map[string]string, buffered bool) error {
+	attempts := item.MaxRetries() + 1
+	for attempt := 1; ; attempt++ {
+		if attempts > 1 {
+			logger.StartAttempt(p.Name, item.Command, attempt, attempts)
+		} else {
+			logger.Start(p.Name, item.Command)
+		}
+		err := execForegroundCommand(ctx, p, item, env, buffered)
+		var failure *commandError
+		if !errors.As(err, &failure) {
+			return err
+		}
+		failure.attempts = attempt
+		if attempt == attempts {
+			return err
+		}
+
+		delay := item.RetryBackoff(attempt)
+		logger.Retrying(p.Name, item.Command, attempt+1, attempts, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			cause := context.Cause(ctx)
+			logger.Canceled(p.Name, item.Command, cause)
+			return fmt.Errorf("project %q: command %q %w: %v", p.Name, item.Command, errCanceled, cause)
+		}
 	}
+}
+
+// execForegroundCommand runs a single attempt of a foreground command and
+// waits for its health check, if any.
+func execForegroundCommand(ctx context.Context, p config.Project, item config.CommandItem, env map[string]string, buffered bool) error {
+	parent := ctx
+	if timeout := item.TimeoutDuration(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, &timeoutError{timeout: timeout})
+		defer cancel()
+	}
+
+	usePTY := hasPTYSupport() && isTerminal(os.Stdout)
+	cmd := newForegroundCommand(ctx, item.Command, p.Path, usePTY)
+	cmd.Env = config.Environ(env)
 
-	cmd := newShellCommand(item.Command, p.Path)
+	var err error
+	if usePTY {
+		err = execForegroundPTY(ctx, p, item, cmd, buffered)
+	} else {
+		err = execForegroundStd(ctx, p, item, cmd, buffered)
+	}
+	if cmd.Process != nil && ctx.Err() != nil && leadsGroup(cmd) {
+		// Children that outlive a canceled shell, such as its background
+		// jobs, which ignore SIGINT, are stopped with it.
+		forgetCanceling(cmd.Process.Pid)
+		signalGroup(cmd.Process.Pid, false)
+	}
+	if err != nil {
+		return err
+	}
 
-	if hasPTYSupport() && isTerminal(os.Stdout) {
-		return execForegroundPTY(p, item, cmd, buffered)
+	if item.HealthCheck != nil {
+		if err := waitForHealthy(parent, p, item.HealthCheck, nil, 0, env); err != nil {
+			return fmt.Errorf("project %q: command %q: %w", p.Name, item.Command, err)
+		}
 	}
-	return execForegroundStd(p, item, cmd, buffered)
+	return nil
 }
 
-func execBackgroundCommand(p config.Project, item config.CommandItem, configName string) error {
+func execBackgroundCommand(ctx context.Context, p config.Project, item config.CommandItem, env map[string]string, rotation pidfile.LogRotation, configName string) error {
 	tmpLog, _ := pidfile.ProcLogTmpPath(configName, p.Name)
-	pid, err := StartBackgroundProcess(item.Command, p.Path, tmpLog)
+	bp, err := SpawnBackgroundProcess(item.Command, p.Path, SpawnOptions{LogFile: tmpLog, Env: env, Rotation: rotation})
 	if err != nil {
 		logger.Error(p.Name, item.Command, err)
 		return fmt.Errorf("project %q: background command %q failed to start: %w", p.Name, item.Command, err)
 	}
+	pid := bp.PID
 
-	if _, err := pidfile.RenameProcLog(tmpLog, pid); err != nil {
+	logPath := tmpLog
+	if finalPath, err := pidfile.RenameProcLog(tmpLog, pid); err != nil {
 		logger.Warn(p.Name, fmt.Sprintf("log rename failed: %v", err))
+	} else if finalPath != "" {
+		logPath = finalPath
 	}
 
-	if err := pidfile.Append(configName, p.Name, pidfile.Entry{
-		PID:     pid,
-		Command: item.Command,
-		Dir:     p.Path,
-	}); err != nil {
+	entry := pidfile.Entry{
+		PID:         pid,
+		Name:        item.Name,
+		Command:     item.Command,
+		Dir:         p.Path,
+		Env:         env,
+		Rotation:    rotation,
+		StopSignal:  item.StopSignalName(),
+		StopTimeout: item.StopGracePeriod(),
+		StartedAt:   time.Now(),
+		Identity:    pidfile.Identify(pid),
+	}
+	if err := pidfile.Append(configName, p.Name, entry); err != nil {
 		return fmt.Errorf("project %q: failed to save PID: %w", p.Name, err)
 	}
+	pidfile.RecordStart(configName, p.Name, entry)
 	logger.Background(p.Name, item.Command, pid)
+
+	if item.HealthCheck != nil {
+		var logPaths []string
+		if logPath != "" {
+			logPaths = []string{logPath}
+		}
+		if err := waitForHealthy(ctx, p, item.HealthCheck, logPaths, pid, env); err != nil {
+			return fmt.Errorf("project %q: background command %q: %w", p.Name, item.Command, err)
+		}
+	}
 	return nil
 }
 
+// ExecHelperCommand is the hidden mdc subcommand that runs a background
+// command with its output logged; see RunExecHelper.
+const ExecHelperCommand = "__exec"
+
 // StartBackgroundProcess starts a detached background process and returns its PID.
-// If logFile is non-empty, the command is wrapped with the `script` utility so
-// that the child runs inside a PTY. This preserves ANSI color codes in the log.
-func StartBackgroundProcess(command, dir, logFile string) (int, error) {
+// If logFile is non-empty, the command runs under the exec helper, which
+// gives it a PTY so that ANSI color codes are preserved in the log, and
+// rotates the log according to rotation.
+func StartBackgroundProcess(command, dir, logFile string, rotation pidfile.LogRotation) (int, error) {
+	bp, err := SpawnBackgroundProcess(command, dir, SpawnOptions{LogFile: logFile, Rotation: rotation})
+	if err != nil {
+		return 0, err
+	}
+	return bp.PID, nil
+}
+
+// BackgroundProcess is a detached process started by this mdc instance.
+// Because mdc is its parent, the exit status can be observed.
+type BackgroundProcess struct {
+	PID   int
+	done  chan struct{}
+	state *os.ProcessState
+}
+
+// Done is closed when the process exits.
+func (b *BackgroundProcess) Done() <-chan struct{} {
+	return b.done
+}
+
+// ExitCode returns the exit code after Done is closed, or -1 if the process
+// was terminated by a signal.
+func (b *BackgroundProcess) ExitCode() int {
+	if b.state == nil {
+		return -1
+	}
+	return b.state.ExitCode()
+}
+
+// SpawnOptions configures SpawnBackgroundProcess.
+type SpawnOptions struct {
+	// LogFile receives the output of the process when non-empty.
+	LogFile string
+	// AppendLog continues an existing LogFile instead of truncating it.
+	AppendLog bool
+	// Env holds variables added to the inherited environment.
+	Env map[string]string
+	// Rotation holds the limits at which the exec helper rotates LogFile.
+	Rotation pidfile.LogRotation
+}
+
+// SpawnBackgroundProcess is like StartBackgroundProcess but returns a handle
+// that reports when the process exits.
+func SpawnBackgroundProcess(command, dir string, opts SpawnOptions) (*BackgroundProcess, error) {
+	logFile := opts.LogFile
 	var cmd *exec.Cmd
 	if logFile != "" {
 		if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
-			return 0, fmt.Errorf("failed to create log directory: %w", err)
+			return nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+		if !opts.AppendLog {
+			// The log is written in append mode; start from an empty file.
+			if err := os.Remove(logFile); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to reset log file: %w", err)
+			}
+		}
+		var err error
+		if cmd, err = startLogged(command, dir, logFile, opts.Rotation, config.Environ(opts.Env)); err != nil {
+			return nil, err
 		}
-		cmd = newScriptCommand(command, dir, logFile)
 	} else {
 		cmd = newShellCommand(command, dir)
 		cmd.Stdin = nil
 		cmd.Stdout = nil
 		cmd.Stderr = nil
-	}
-	setSysProcAttr(cmd)
-
-	if err := cmd.Start(); err != nil {
-		return 0, err
-	}
-
-	if logFile != "" {
-		waitForFile(logFile, 2*time.Second)
-	}
-
-	return cmd.Process.Pid, nil
-}
-
-// waitForFile polls until the file at path exists or the timeout elapses.
-func waitForFile(path string, timeout time.Duration) {
-	deadline := time.Now().Add(timeout)
-	for time.Now().Before(deadline) {
-		if _, err := os.Stat(path); err == nil {
-			return
+		cmd.Env = config.Environ(opts.Env)
+		setSysProcAttr(cmd)
+		if err := cmd.Start(); err != nil {
+			return nil, err
 		}
-		time.Sleep(20 * time.Millisecond)
 	}
+
+	bp := &BackgroundProcess{PID: cmd.Process.Pid, done: make(chan struct{})}
+	// Reap the child if it exits while mdc is still running, so that
+	// IsRunning does not report a zombie as alive.
+	go func() {
+		_ = cmd.Wait()
+		bp.state = cmd.ProcessState
+		close(bp.done)
+	}()
+	return bp, nil
 }
 
-func execForegroundPTY(p config.Project, item config.CommandItem, cmd *exec.Cmd, buffered bool) error {
+func execForegroundPTY(ctx context.Context, p config.Project, item config.CommandItem, cmd *exec.Cmd, buffered bool) error {
 	if !buffered {
 		logger.Border()
 	}
@@ -224,26 +661,20 @@ func execForegroundPTY(p config.Project, item config.CommandItem, cmd *exec.Cmd,
 		logger.Border()
 	}
 	if err != nil {
-		logger.Error(p.Name, item.Command, err)
-		if buffered && output != "" {
-			logger.Output(p.Name, output)
-		}
-		return fmt.Errorf("project %q: command %q failed: %w", p.Name, item.Command, err)
+		return commandFailed(ctx, p, item, err, output)
 	}
 	logger.Success(p.Name, item.Command)
 	return nil
 }
 
-func execForegroundStd(p config.Project, item config.CommandItem, cmd *exec.Cmd, buffered bool) error {
+func execForegroundStd(ctx context.Context, p config.Project, item config.CommandItem, cmd *exec.Cmd, buffered bool) error {
 	if buffered {
 		var stdoutBuf, stderrBuf bytes.Buffer
 		cmd.Stdout = &stdoutBuf
 		cmd.Stderr = &stderrBuf
 
 		if err := cmd.Run(); err != nil {
-			logger.Error(p.Name, item.Command, err)
-			logger.Output(p.Name, stderrBuf.String()+stdoutBuf.String())
-			return fmt.Errorf("project %q: command %q failed: %w", p.Name, item.Command, err)
+			return commandFailed(ctx, p, item, err, stderrBuf.String()+stdoutBuf.String())
 		}
 	} else {
 		logger.Border()
@@ -253,14 +684,74 @@ func execForegroundStd(p config.Project, item config.CommandItem, cmd *exec.Cmd,
 		logger.Border()
 
 		if err != nil {
-			logger.Error(p.Name, item.Command, err)
-			return fmt.Errorf("project %q: command %q failed: %w", p.Name, item.Command, err)
+			return commandFailed(ctx, p, item, err, "")
 		}
 	}
 	logger.Success(p.Name, item.Command)
 	return nil
 }
 
+// commandFailed reports a foreground command that failed, with its output
+// when it was buffered, and returns the error of the project: a
+// *commandError for a command that exited with an error or ran into its
+// timeout. A command stopped because ctx was canceled otherwise is reported
+// as canceled instead.
+func commandFailed(ctx context.Context, p config.Project, item config.CommandItem, err error, output string) error {
+	var timeout *timeoutError
+	if errors.As(context.Cause(ctx), &timeout) {
+		err = timeout
+	} else if ctx.Err() != nil {
+		cause := context.Cause(ctx)
+		logger.Canceled(p.Name, item.Command, cause)
+		logger.Output(p.Name, output)
+		return fmt.Errorf("project %q: command %q %w: %v", p.Name, item.Command, errCanceled, cause)
+	}
+	logger.Error(p.Name, item.Command, err)
+	logger.Output(p.Name, output)
+	return &commandError{project: p.Name, command: item.Command, err: err, attempts: 1}
+}
+
+// timeoutError is the cause of the cancellation of a command that ran
+// longer than its timeout.
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.timeout)
+}
+
+// commandError is the error of a project whose foreground command failed.
+// It tells a timeout from an exit code, and how many attempts were made.
+type commandError struct {
+	project  string
+	command  string
+	err      error
+	attempts int
+}
+
+func (e *commandError) Error() string {
+	var reason string
+	var timeout *timeoutError
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(e.err, &timeout):
+		reason = timeout.Error()
+	case errors.As(e.err, &exitErr) && exitErr.ExitCode() >= 0:
+		reason = fmt.Sprintf("failed with exit code %d", exitErr.ExitCode())
+	default:
+		reason = "failed: " + e.err.Error()
+	}
+	if e.attempts > 1 {
+		reason += fmt.Sprintf(" (after %d attempts)", e.attempts)
+	}
+	return fmt.Sprintf("project %q: command %q %s", e.project, e.command, reason)
+}
+
+func (e *commandError) Unwrap() error {
+	return e.err
+}
+
 func newShellCommand(cmdStr, dir string) *exec.Cmd {
 	cmd := exec.Command("sh", "-c", cmdStr)
 	cmd.Dir = dir
@@ -268,6 +759,22 @@ func newShellCommand(cmdStr, dir string) *exec.Cmd {
 	return cmd
 }
 
+// newForegroundCommand is newShellCommand for a foreground command that is
+// stopped when ctx is canceled, along with its children when it can get its
+// own process group (see setCancelGroup). pty tells whether it will run in a
+// PTY. Commands of runs that cannot be canceled stay in the foreground
+// process group of the terminal.
+func newForegroundCommand(ctx context.Context, cmdStr, dir string, pty bool) *exec.Cmd {
+	if ctx.Done() == nil {
+		return newShellCommand(cmdStr, dir)
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	setCancelGroup(ctx, cmd, pty)
+	return cmd
+}
+
 func validateProjectPath(p config.Project) error {
 	info, err := os.Stat(p.Path)
 	if err != nil {
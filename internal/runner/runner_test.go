@@ -2,12 +2,17 @@ package runner
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"mdc/internal/config"
 	"mdc/internal/logger"
@@ -24,6 +29,15 @@ func init() {
 	logger.SetOutput(os.Stderr)
 }
 
+func TestMain(m *testing.M) {
+	// Background commands run under the exec helper, which is this test
+	// binary here.
+	if len(os.Args) > 1 && os.Args[1] == ExecHelperCommand {
+		os.Exit(RunExecHelper(os.Args[2:]))
+	}
+	os.Exit(m.Run())
+}
+
 func TestCommandsForAction(t *testing.T) {
 	cfg := &config.Config{
 		ExecutionMode: "sequential",
@@ -360,7 +374,7 @@ func TestStartBackgroundProcessWithLogFile(t *testing.T) {
 	dir := t.TempDir()
 	logFile := filepath.Join(dir, "test.log")
 
-	pid, err := StartBackgroundProcess("echo hello-from-bg", dir, logFile)
+	pid, err := StartBackgroundProcess("echo hello-from-bg", dir, logFile, pidfile.LogRotation{})
 	if err != nil {
 		t.Fatalf("StartBackgroundProcess() error: %v", err)
 	}
@@ -385,7 +399,7 @@ func TestStartBackgroundProcessLogFileExistsOnReturn(t *testing.T) {
 	dir := t.TempDir()
 	logFile := filepath.Join(dir, "wait.log")
 
-	pid, err := StartBackgroundProcess("sleep 60", dir, logFile)
+	pid, err := StartBackgroundProcess("sleep 60", dir, logFile, pidfile.LogRotation{})
 	if err != nil {
 		t.Fatalf("StartBackgroundProcess() error: %v", err)
 	}
@@ -405,7 +419,7 @@ func TestStartBackgroundProcessLogFileRenamable(t *testing.T) {
 	dir := t.TempDir()
 	logFile := filepath.Join(dir, "_pending.log")
 
-	pid, err := StartBackgroundProcess("sleep 60", dir, logFile)
+	pid, err := StartBackgroundProcess("sleep 60", dir, logFile, pidfile.LogRotation{})
 	if err != nil {
 		t.Fatalf("StartBackgroundProcess() error: %v", err)
 	}
@@ -418,7 +432,7 @@ func TestStartBackgroundProcessLogFileRenamable(t *testing.T) {
 
 	renamed, err := pidfile.RenameProcLog(logFile, pid)
 	if err != nil {
-		t.Fatalf("RenameProcLog() should succeed after waitForFile: %v", err)
+		t.Fatalf("RenameProcLog() should succeed once the process has started: %v", err)
 	}
 	expected := filepath.Join(dir, fmt.Sprintf("%d.log", pid))
 	if renamed != expected {
@@ -432,7 +446,7 @@ func TestStartBackgroundProcessPreservesANSI(t *testing.T) {
 
 	// printf with ANSI escape: red "COLOR" reset
 	cmd := `printf '\033[31mCOLOR\033[0m\n'`
-	pid, err := StartBackgroundProcess(cmd, dir, logFile)
+	pid, err := StartBackgroundProcess(cmd, dir, logFile, pidfile.LogRotation{})
 	if err != nil {
 		t.Fatalf("StartBackgroundProcess() error: %v", err)
 	}
@@ -563,7 +577,7 @@ func TestDryRunInvalidPath(t *testing.T) {
 func TestStartBackgroundProcessWithoutLogFile(t *testing.T) {
 	dir := t.TempDir()
 
-	pid, err := StartBackgroundProcess("echo no-log", dir, "")
+	pid, err := StartBackgroundProcess("echo no-log", dir, "", pidfile.LogRotation{})
 	if err != nil {
 		t.Fatalf("StartBackgroundProcess() error: %v", err)
 	}
@@ -625,3 +639,656 @@ func TestRunMixedForegroundAndBackground(t *testing.T) {
 		_, _ = p.Wait()
 	}
 }
+
+func TestTopoOrder(t *testing.T) {
+	pcs := []projectCommands{
+		{Project: config.Project{Name: "web", DependsOn: []string{"api"}}},
+		{Project: config.Project{Name: "api", DependsOn: []string{"db"}}},
+		{Project: config.Project{Name: "db"}},
+		{Project: config.Project{Name: "docs"}},
+	}
+
+	names := func(order []int) []string {
+		var out []string
+		for _, i := range order {
+			out = append(out, pcs[i].Project.Name)
+		}
+		return out
+	}
+
+	t.Run("forward", func(t *testing.T) {
+		got := strings.Join(names(topoOrder(buildDAG(pcs, false))), ",")
+		if want := "db,api,web,docs"; got != want {
+			t.Errorf("topoOrder = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		got := strings.Join(names(topoOrder(buildDAG(pcs, true))), ",")
+		if want := "web,api,db,docs"; got != want {
+			t.Errorf("topoOrder = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("no dependencies keeps definition order", func(t *testing.T) {
+		flat := []projectCommands{
+			{Project: config.Project{Name: "a"}},
+			{Project: config.Project{Name: "b"}},
+		}
+		order := topoOrder(buildDAG(flat, true))
+		if len(order) != 2 || order[0] != 0 || order[1] != 1 {
+			t.Errorf("topoOrder = %v, want [0 1]", order)
+		}
+	})
+}
+
+func TestRunParallelDependsOn(t *testing.T) {
+	base := t.TempDir()
+	dbDir := filepath.Join(base, "db")
+	apiDir := filepath.Join(base, "api")
+	for _, d := range []string{dbDir, apiDir} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		ExecutionMode: "parallel",
+		Projects: []config.Project{
+			{
+				Name:      "api",
+				Path:      apiDir,
+				DependsOn: []string{"db"},
+				Commands: config.Commands{
+					Up: []config.CommandItem{{Command: "test -f ../db/ready.txt && touch started.txt"}},
+				},
+			},
+			{
+				Name: "db",
+				Path: dbDir,
+				Commands: config.Commands{
+					Up: []config.CommandItem{{Command: "sleep 0.2 && touch ready.txt"}},
+				},
+			},
+		},
+	}
+
+	if err := Run(cfg, "up", "test-config"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(apiDir, "started.txt")); err != nil {
+		t.Errorf("api should start after db completed: %v", err)
+	}
+}
+
+func TestRunParallelSkipsDependentsOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		ExecutionMode: "parallel",
+		Projects: []config.Project{
+			{
+				Name:     "db",
+				Path:     dir,
+				Commands: config.Commands{Up: []config.CommandItem{{Command: "false"}}},
+			},
+			{
+				Name:      "api",
+				Path:      dir,
+				DependsOn: []string{"db"},
+				Commands:  config.Commands{Up: []config.CommandItem{{Command: "touch api.txt"}}},
+			},
+			{
+				Name:      "web",
+				Path:      dir,
+				DependsOn: []string{"api"},
+				Commands:  config.Commands{Up: []config.CommandItem{{Command: "touch web.txt"}}},
+			},
+			{
+				Name:     "docs",
+				Path:     dir,
+				Commands: config.Commands{Up: []config.CommandItem{{Command: "touch docs.txt"}}},
+			},
+		},
+	}
+
+	err := Run(cfg, "up", "test-config")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), `project "api": skipped because dependency "db" failed`) {
+		t.Errorf("error = %q, want api skipped", err.Error())
+	}
+	if !strings.Contains(err.Error(), `project "web": skipped`) {
+		t.Errorf("error = %q, want web skipped", err.Error())
+	}
+	for _, name := range []string{"api.txt", "web.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist when a dependency failed", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs.txt")); err != nil {
+		t.Errorf("independent project should still run: %v", err)
+	}
+}
+
+func TestRunParallelFailFast(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		ExecutionMode: "parallel",
+		Projects: []config.Project{
+			{
+				Name:     "db",
+				Path:     dir,
+				Commands: config.Commands{Up: []config.CommandItem{{Command: "sleep 0.2; exit 1"}}},
+			},
+			{
+				Name: "api",
+				Path: dir,
+				// The child of the shell must be stopped with it.
+				Commands: config.Commands{Up: []config.CommandItem{{Command: "sh -c 'sleep 10; touch api.txt'"}}},
+			},
+			{
+				Name:      "web",
+				Path:      dir,
+				DependsOn: []string{"api"},
+				Commands:  config.Commands{Up: []config.CommandItem{{Command: "touch web.txt"}}},
+			},
+		},
+	}
+
+	start := time.Now()
+	err := RunContext(context.Background(), cfg, "up", "test-config", Options{FailFast: true})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("run took %s; the other projects were not canceled", elapsed)
+	}
+	if !strings.Contains(err.Error(), `project "db": command "sleep 0.2; exit 1" failed`) {
+		t.Errorf("error = %q, want the failure of db", err)
+	}
+	if strings.Contains(err.Error(), `project "api"`) {
+		t.Errorf("error = %q, canceled projects should not be reported as failures", err)
+	}
+	if !strings.Contains(err.Error(), `project "web": skipped because dependency "api" was canceled`) {
+		t.Errorf("error = %q, want web skipped", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	for _, name := range []string{"api.txt", "web.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist after cancellation", name)
+		}
+	}
+}
+
+func TestRunRollbackOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	pidDir := t.TempDir()
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = pidDir
+	defer func() { pidfile.BaseDir = oldBaseDir }()
+
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{
+			{
+				Name: "db",
+				Path: dir,
+				Commands: config.Commands{
+					Up: []config.CommandItem{
+						{Command: "touch db-up.txt"},
+						{Command: "sleep 30", Background: true},
+					},
+					Down: []config.CommandItem{{Command: "touch db-down.txt"}},
+				},
+			},
+			{
+				Name:      "api",
+				Path:      dir,
+				DependsOn: []string{"db"},
+				Commands: config.Commands{
+					Up:   []config.CommandItem{{Command: "exit 1"}},
+					Down: []config.CommandItem{{Command: "touch api-down.txt"}},
+				},
+			},
+			{
+				Name:      "web",
+				Path:      dir,
+				DependsOn: []string{"api"},
+				Commands: config.Commands{
+					Up:   []config.CommandItem{{Command: "touch web-up.txt"}},
+					Down: []config.CommandItem{{Command: "touch web-down.txt"}},
+				},
+			},
+		},
+	}
+
+	if err := RunContext(context.Background(), cfg, "up", "test-config", Options{RollbackOnFailure: true}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	for _, name := range []string{"db-down.txt", "api-down.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("started project should be rolled back: %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "web-down.txt")); !os.IsNotExist(err) {
+		t.Error("a project that was not started should not be rolled back")
+	}
+	if entries, _ := pidfile.Load("test-config", "db"); len(entries) != 0 {
+		t.Errorf("background processes should be stopped, got %+v", entries)
+	}
+}
+
+func TestRunNamedAction(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	defer logger.SetOutput(os.Stderr)
+
+	cfg := &config.Config{
+		ExecutionMode: "parallel",
+		Projects: []config.Project{
+			{
+				Name:     "db",
+				Path:     dir,
+				Commands: config.Commands{Actions: map[string][]config.CommandItem{"migrate": {{Command: "echo db >> order.txt"}}}},
+			},
+			{
+				Name:     "web",
+				Path:     dir,
+				Commands: config.Commands{Up: []config.CommandItem{{Command: "touch web.txt"}}},
+			},
+			{
+				Name:      "api",
+				Path:      dir,
+				DependsOn: []string{"db"},
+				Commands:  config.Commands{Actions: map[string][]config.CommandItem{"migrate": {{Command: "echo api >> order.txt"}}}},
+			},
+		},
+	}
+
+	if err := Run(cfg, "migrate", "test-config"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "order.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(strings.Fields(string(data)), ","); got != "db,api" {
+		t.Errorf("migrate order = %s, want db,api", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "web.txt")); !os.IsNotExist(err) {
+		t.Error("up commands should not run for a named action")
+	}
+	if out := stripANSI(buf.String()); !strings.Contains(out, `[web] skipped — no "migrate" commands`) {
+		t.Errorf("summary does not report web as skipped:\n%s", out)
+	}
+
+	if err := Run(cfg, "seed", "test-config"); err == nil || !strings.Contains(err.Error(), `unknown action: "seed"`) {
+		t.Errorf("Run(seed) error = %v, want unknown action", err)
+	}
+}
+
+func TestRunSequentialDownReverseOrder(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{
+			{
+				Name:     "db",
+				Path:     dir,
+				Commands: config.Commands{Down: []config.CommandItem{{Command: "echo db >> order.txt"}}},
+			},
+			{
+				Name:      "api",
+				Path:      dir,
+				DependsOn: []string{"db"},
+				Commands:  config.Commands{Down: []config.CommandItem{{Command: "echo api >> order.txt"}}},
+			},
+		},
+	}
+
+	if err := Run(cfg, "down", "test-config"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "order.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(string(data)); strings.Join(got, ",") != "api,db" {
+		t.Errorf("down order = %v, want [api db]", got)
+	}
+}
+
+func TestRunWithEnv(t *testing.T) {
+	dir := t.TempDir()
+	pidDir := t.TempDir()
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = pidDir
+	defer func() { pidfile.BaseDir = oldBaseDir }()
+
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Env:           map[string]string{"APP_ENV": "dev", "GREETING": "hello"},
+		Projects: []config.Project{
+			{
+				Name: "env-proj",
+				Path: dir,
+				Env:  map[string]string{"GREETING": "hi-${APP_ENV}"},
+				Commands: config.Commands{
+					Up: []config.CommandItem{
+						{Command: `echo "$GREETING $APP_ENV $TARGET" > fg.txt`, Env: map[string]string{"TARGET": "fg"}},
+						{Command: "sleep 60", Background: true, Env: map[string]string{"TARGET": "bg"}},
+					},
+				},
+			},
+		},
+	}
+
+	if err := Run(cfg, "up", "test-env"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	defer func() { _ = pidfile.KillAll("test-env") }()
+
+	data, err := os.ReadFile(filepath.Join(dir, "fg.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "hi-dev dev fg" {
+		t.Errorf("foreground env = %q, want %q", got, "hi-dev dev fg")
+	}
+
+	entries, err := pidfile.Load("test-env", "env-proj")
+	if err != nil {
+		t.Fatalf("pidfile.Load() error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 PID entry, got %d", len(entries))
+	}
+	if entries[0].Env["TARGET"] != "bg" || entries[0].Env["GREETING"] != "hi-dev" {
+		t.Errorf("Entry.Env = %v, want resolved environment", entries[0].Env)
+	}
+}
+
+func TestSpawnBackgroundProcessEnv(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "env.log")
+
+	bp, err := SpawnBackgroundProcess(`echo "value=$MDC_SPAWN_VAR"`, dir, SpawnOptions{
+		LogFile: logFile,
+		Env:     map[string]string{"MDC_SPAWN_VAR": "42"},
+	})
+	if err != nil {
+		t.Fatalf("SpawnBackgroundProcess() error: %v", err)
+	}
+	<-bp.Done()
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "value=42") {
+		t.Errorf("log = %q, want containing value=42", string(data))
+	}
+	if bp.ExitCode() != 0 {
+		t.Errorf("ExitCode() = %d, want 0", bp.ExitCode())
+	}
+}
+
+func TestRunSequentialJSONOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	logger.SetRenderer(logger.JSONRenderer{})
+	defer func() {
+		logger.SetOutput(os.Stderr)
+		logger.SetRenderer(logger.TextRenderer{})
+	}()
+
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{
+			{
+				Name: "json-proj",
+				Path: dir,
+				Commands: config.Commands{
+					Up: []config.CommandItem{
+						{Command: "echo hello"},
+						{Command: "echo oops >&2; exit 2"},
+					},
+				},
+			},
+		},
+	}
+
+	if err := Run(cfg, "up", "test-json"); err == nil {
+		t.Fatal("Run() should fail")
+	}
+
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e struct {
+			Event    string `json:"event"`
+			ExitCode *int   `json:"exit_code"`
+			Output   string `json:"output"`
+		}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("non-JSON line in output: %q", line)
+		}
+		types = append(types, e.Event)
+		if e.Event == "failure" && (e.ExitCode == nil || *e.ExitCode != 2) {
+			t.Errorf("failure exit_code = %v, want 2", e.ExitCode)
+		}
+		if e.Event == "output" && !strings.Contains(e.Output, "oops") {
+			t.Errorf("output event = %q, want command output", e.Output)
+		}
+	}
+	want := []string{"start", "success", "start", "failure", "output", "summary"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", types, want)
+	}
+}
+
+func TestRunBackgroundCommandAlreadyRunning(t *testing.T) {
+	dir := t.TempDir()
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = t.TempDir()
+	defer func() { pidfile.BaseDir = oldBaseDir }()
+	defer func() { _ = pidfile.KillAll("test-idempotent") }()
+
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	defer logger.SetOutput(os.Stderr)
+
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{
+			{
+				Name: "web",
+				Path: dir,
+				Commands: config.Commands{
+					Up: []config.CommandItem{
+						{Command: "sleep 60", Background: true},
+						{Command: "sleep 60", Background: true},
+					},
+				},
+			},
+		},
+	}
+	pids := func() []int {
+		entries, err := pidfile.Load("test-idempotent", "web")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []int
+		for _, e := range entries {
+			result = append(result, e.PID)
+		}
+		return result
+	}
+
+	if err := Run(cfg, "up", "test-idempotent"); err != nil {
+		t.Fatalf("first Run() error: %v", err)
+	}
+	first := pids()
+	if len(first) != 2 {
+		t.Fatalf("got %d entries after the first up, want 2", len(first))
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		buf.Reset()
+		if err := Run(cfg, "up", "test-idempotent"); err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if got := pids(); !reflect.DeepEqual(got, first) {
+			t.Errorf("entries = %v, want %v unchanged", got, first)
+		}
+		if out := stripANSI(buf.String()); !strings.Contains(out, "completed — already running: sleep 60 (PID") {
+			t.Errorf("summary does not report the skipped commands:\n%s", out)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		cfg.SetIfRunning(config.IfRunningFail)
+		err := Run(cfg, "up", "test-idempotent")
+		if err == nil || !strings.Contains(err.Error(), "is already running") {
+			t.Fatalf("Run() error = %v, want already running", err)
+		}
+		if got := pids(); !reflect.DeepEqual(got, first) {
+			t.Errorf("entries = %v, want %v unchanged", got, first)
+		}
+	})
+
+	t.Run("recreate", func(t *testing.T) {
+		cfg.SetIfRunning(config.IfRunningRecreate)
+		if err := Run(cfg, "up", "test-idempotent"); err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		got := pids()
+		if len(got) != 2 {
+			t.Fatalf("got %d entries after recreate, want 2", len(got))
+		}
+		for _, pid := range first {
+			if slices.Contains(got, pid) || pidfile.IsRunning(pid) {
+				t.Errorf("PID %d was not replaced", pid)
+			}
+		}
+	})
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestRunRetries(t *testing.T) {
+	dir := t.TempDir()
+	// Fails twice, then succeeds.
+	flaky := `n=$(cat count 2>/dev/null || echo 0); echo $((n+1)) > count; [ "$n" -ge 2 ]`
+	policy := config.ExecPolicy{Retries: ptr(3), RetryDelay: ptr(config.Duration(10 * time.Millisecond))}
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{
+			{
+				Name:     "api",
+				Path:     dir,
+				Commands: config.Commands{Up: []config.CommandItem{{Command: flaky, ExecPolicy: policy}}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	defer logger.SetOutput(os.Stderr)
+
+	if err := Run(cfg, "up", "test-config"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	count, _ := os.ReadFile(filepath.Join(dir, "count"))
+	if got := strings.TrimSpace(string(count)); got != "3" {
+		t.Errorf("command ran %s times, want 3", got)
+	}
+	out := stripANSI(buf.String())
+	for _, want := range []string{"(attempt 1/4)", "Retrying: " + flaky + " in 10ms (attempt 2/4)", "Retrying: " + flaky + " in 20ms (attempt 3/4)", "(attempt 3/4)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "attempt 4/4") {
+		t.Errorf("command retried after it succeeded:\n%s", out)
+	}
+}
+
+func TestRunCommandTimeout(t *testing.T) {
+	dir := t.TempDir()
+	policy := config.ExecPolicy{
+		Timeout:    ptr(config.Duration(200 * time.Millisecond)),
+		Retries:    ptr(1),
+		RetryDelay: ptr(config.Duration(10 * time.Millisecond)),
+	}
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{
+			{
+				Name: "api",
+				Path: dir,
+				// The child of the shell must be stopped with it.
+				Commands: config.Commands{Up: []config.CommandItem{
+					{Command: "sh -c 'sleep 10; touch late.txt'", ExecPolicy: policy},
+					{Command: "touch next.txt"},
+				}},
+			},
+		},
+	}
+
+	start := time.Now()
+	err := Run(cfg, "up", "test-config")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("run took %s; the command was not stopped at its timeout", elapsed)
+	}
+	want := `project "api": command "sh -c 'sleep 10; touch late.txt'" timed out after 200ms (after 2 attempts)`
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want containing %q", err, want)
+	}
+	for _, name := range []string{"late.txt", "next.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist after the timeout", name)
+		}
+	}
+}
+
+func TestRunContinueOnError(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{
+			{
+				Name: "api",
+				Path: dir,
+				Commands: config.Commands{Up: []config.CommandItem{
+					{Command: "exit 3", ExecPolicy: config.ExecPolicy{ContinueOnError: ptr(true)}},
+					{Command: "touch next.txt"},
+					{Command: "exit 4"},
+					{Command: "touch last.txt"},
+				}},
+			},
+		},
+	}
+
+	err := Run(cfg, "up", "test-config")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if want := `project "api": command "exit 4" failed with exit code 4`; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want containing %q", err, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "next.txt")); err != nil {
+		t.Errorf("the project should go on after a command with continue_on_error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "last.txt")); !os.IsNotExist(err) {
+		t.Error("last.txt should not exist after a failure without continue_on_error")
+	}
+}
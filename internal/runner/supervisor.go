@@ -0,0 +1,292 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+)
+
+// Backoff bounds for restarts; variables so that tests can shorten them.
+var (
+	initialRestartDelay = time.Second
+	maxRestartDelay     = time.Minute
+)
+
+type policyKey struct {
+	project string
+	command string
+}
+
+// supervisedProcess is a tracked background process watched by Supervise.
+type supervisedProcess struct {
+	project string
+	entry   pidfile.Entry
+	item    config.CommandItem
+	// proc is set for processes started by the supervisor itself. The exit
+	// code of processes adopted from "mdc up" is read from the status file
+	// recorded by the exec helper.
+	proc *BackgroundProcess
+	// exitCode is the last observed exit code, or -1 when unknown.
+	exitCode int
+	// restartAt is the scheduled restart time; zero while the process runs.
+	restartAt time.Time
+}
+
+// Supervise watches the background processes of configName and restarts
+// them according to each command's restart policy until ctx is cancelled or
+// no process is left to supervise: none is tracked any more (e.g. after
+// "mdc down"), or all have been stopped, have exited for good or have
+// reached their max_restarts.
+func Supervise(ctx context.Context, cfg *config.Config, configName string, interval time.Duration) error {
+	policies := make(map[policyKey]config.CommandItem)
+	for _, p := range cfg.Projects {
+		for _, item := range p.Commands.Up {
+			if item.Background && item.RestartPolicy() != config.RestartNo {
+				policies[policyKey{p.Name, item.Command}] = item
+			}
+		}
+	}
+	if len(policies) == 0 {
+		return fmt.Errorf("no background commands with a restart policy in config %q", configName)
+	}
+
+	watched := make(map[int]*supervisedProcess)
+	// finished holds the processes that are no longer supervised although
+	// they are still tracked, e.g. after reaching max_restarts.
+	finished := make(map[int]bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		projects, err := pidfile.LoadAll(configName)
+		if err != nil {
+			return err
+		}
+		if len(projects) == 0 {
+			logger.SupervisorExit(configName, "no tracked processes left")
+			return nil
+		}
+
+		for project, entries := range projects {
+			for _, e := range entries {
+				if _, ok := watched[e.PID]; ok || finished[e.PID] {
+					continue
+				}
+				item, ok := policies[policyKey{project, e.Command}]
+				if !ok {
+					continue
+				}
+				watched[e.PID] = &supervisedProcess{project: project, entry: e, item: item, exitCode: -1}
+			}
+		}
+
+		for pid, sp := range watched {
+			if !isTracked(projects, sp.project, pid) {
+				// Stopped or replaced by another mdc command.
+				delete(watched, pid)
+				continue
+			}
+			if next := superviseOne(configName, sp); next != sp {
+				delete(watched, pid)
+				if next != nil {
+					watched[next.entry.PID] = next
+				} else {
+					finished[pid] = true
+				}
+			}
+		}
+		if len(watched) == 0 {
+			logger.SupervisorExit(configName, "no processes left to supervise")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.SupervisorExit(configName, "interrupted")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// superviseOne advances the state of a single process. It returns sp when
+// nothing changed, nil when the process is no longer supervised, or the
+// replacement after a restart.
+func superviseOne(configName string, sp *supervisedProcess) *supervisedProcess {
+	if sp.restartAt.IsZero() {
+		exited, code, known := sp.exitStatus(configName)
+		if !exited {
+			return sp
+		}
+		logger.ProcessExited(sp.project, sp.entry.PID)
+
+		if !shouldRestart(sp.item.RestartPolicy(), code, known) {
+			logger.Warn(sp.project, fmt.Sprintf("%q exited with code %d; not restarting (restart: %s)", sp.entry.Command, code, sp.item.RestartPolicy()))
+			return nil
+		}
+		limit := sp.item.RestartLimit()
+		if sp.entry.Restarts >= limit {
+			logger.Warn(sp.project, fmt.Sprintf("%q reached max restarts (%d); giving up", sp.entry.Command, limit))
+			return nil
+		}
+
+		sp.exitCode = code
+		delay := restartDelay(sp.entry.Restarts)
+		sp.restartAt = time.Now().Add(delay)
+		logger.Restarting(sp.project, sp.entry.Command, sp.entry.Restarts+1, limit, delay)
+		return sp
+	}
+
+	if time.Now().Before(sp.restartAt) {
+		return sp
+	}
+
+	next, err := restartSupervised(configName, sp)
+	if err != nil {
+		logger.Error(sp.project, sp.entry.Command, err)
+		return nil
+	}
+	return next
+}
+
+// exitStatus reports whether the process has exited and, when known, its code.
+func (sp *supervisedProcess) exitStatus(configName string) (exited bool, code int, known bool) {
+	if sp.proc != nil {
+		select {
+		case <-sp.proc.Done():
+			return true, sp.proc.ExitCode(), sp.proc.ExitCode() >= 0
+		default:
+			return false, 0, false
+		}
+	}
+	if sp.entry.Status() == pidfile.StatusRunning {
+		return false, 0, false
+	}
+	if exit, ok := sp.entry.LastExit(configName, sp.project); ok && exit.Signal == "" {
+		return true, exit.Code, true
+	}
+	return true, -1, false
+}
+
+// shouldRestart applies a restart policy. An unknown exit status (adopted
+// processes or signals) counts as a failure.
+func shouldRestart(policy string, code int, known bool) bool {
+	switch policy {
+	case config.RestartAlways:
+		return true
+	case config.RestartOnFailure:
+		return !known || code != 0
+	default:
+		return false
+	}
+}
+
+// restartDelay returns the exponential backoff delay before the given restart.
+func restartDelay(restarts int) time.Duration {
+	delay := initialRestartDelay
+	for i := 0; i < restarts && delay < maxRestartDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRestartDelay {
+		delay = maxRestartDelay
+	}
+	return delay
+}
+
+// restartSupervised starts a new instance of the command, continuing the
+// previous proc log, and replaces the PID entry. It holds the run lock of
+// the config meanwhile, so that "mdc down" or "mdc proc stop" cannot stop the
+// process between the check and the swap; while another mdc command holds
+// the lock, the restart is postponed to the next tick by returning sp.
+func restartSupervised(configName string, sp *supervisedProcess) (*supervisedProcess, error) {
+	lock, err := pidfile.AcquireRunLock(configName, false, 0)
+	var locked *pidfile.LockedError
+	if errors.As(err, &locked) {
+		return sp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = lock.Release() }()
+
+	// Re-check right before restarting: the entry may have been removed by
+	// "mdc proc stop" or "mdc down" since the last tick.
+	entries, err := pidfile.Load(configName, sp.project)
+	if err != nil {
+		return nil, nil
+	}
+	prev, ok := findEntry(entries, sp.entry.PID)
+	if !ok {
+		return nil, nil
+	}
+	pidfile.RecordGone(configName, sp.project, prev)
+
+	logPath, appendLog := "", false
+	if oldLog, err := pidfile.ProcLogFilePath(configName, sp.project, sp.entry.PID); err == nil {
+		if f, err := os.OpenFile(oldLog, os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			_, _ = fmt.Fprintf(f, "\r\n[mdc] process %d exited (code %d); restarting (%d/%d)\r\n",
+				sp.entry.PID, sp.exitCode, sp.entry.Restarts+1, sp.item.RestartLimit())
+			_ = f.Close()
+			logPath, appendLog = oldLog, true
+		}
+	}
+	if logPath == "" {
+		logPath, _ = pidfile.ProcLogTmpPath(configName, sp.project)
+	}
+
+	bp, err := SpawnBackgroundProcess(sp.entry.Command, sp.entry.Dir, SpawnOptions{
+		LogFile:   logPath,
+		AppendLog: appendLog,
+		Env:       sp.entry.Env,
+		Rotation:  sp.entry.Rotation,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("restart failed: %w", err)
+	}
+	if _, err := pidfile.RenameProcLog(logPath, bp.PID); err != nil {
+		logger.Warn(sp.project, fmt.Sprintf("log rename failed: %v", err))
+	}
+
+	next := sp.entry
+	next.PID = bp.PID
+	next.Identity = pidfile.Identify(bp.PID)
+	next.StartedAt = time.Now()
+	next.Exit = nil
+	next.Restarts++
+	if err := pidfile.ReplaceEntry(configName, sp.project, sp.entry.PID, next); err != nil {
+		// Never leave a process running that no mdc command knows about.
+		killEntry(sp.project, next)
+		if errors.Is(err, pidfile.ErrNotTracked) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to save PID: %w", err)
+	}
+	pidfile.RecordRestart(configName, sp.project, sp.entry.PID, next)
+	logger.Background(sp.project, next.Command, next.PID)
+
+	return &supervisedProcess{project: sp.project, entry: next, item: sp.item, proc: bp, exitCode: -1}, nil
+}
+
+func isTracked(projects map[string][]pidfile.Entry, project string, pid int) bool {
+	return containsPID(projects[project], pid)
+}
+
+func containsPID(entries []pidfile.Entry, pid int) bool {
+	_, ok := findEntry(entries, pid)
+	return ok
+}
+
+func findEntry(entries []pidfile.Entry, pid int) (pidfile.Entry, bool) {
+	for _, e := range entries {
+		if e.PID == pid {
+			return e, true
+		}
+	}
+	return pidfile.Entry{}, false
+}
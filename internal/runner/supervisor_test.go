@@ -0,0 +1,253 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mdc/internal/config"
+	"mdc/internal/logger"
+	"mdc/internal/pidfile"
+)
+
+func TestShouldRestart(t *testing.T) {
+	tests := []struct {
+		policy string
+		code   int
+		known  bool
+		want   bool
+	}{
+		{policy: config.RestartNo, code: 1, known: true, want: false},
+		{policy: config.RestartOnFailure, code: 0, known: true, want: false},
+		{policy: config.RestartOnFailure, code: 1, known: true, want: true},
+		{policy: config.RestartOnFailure, code: -1, known: false, want: true},
+		{policy: config.RestartAlways, code: 0, known: true, want: true},
+	}
+	for _, tt := range tests {
+		if got := shouldRestart(tt.policy, tt.code, tt.known); got != tt.want {
+			t.Errorf("shouldRestart(%q, %d, %v) = %v, want %v", tt.policy, tt.code, tt.known, got, tt.want)
+		}
+	}
+}
+
+func TestRestartDelay(t *testing.T) {
+	tests := []struct {
+		restarts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{10, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := restartDelay(tt.restarts); got != tt.want {
+			t.Errorf("restartDelay(%d) = %s, want %s", tt.restarts, got, tt.want)
+		}
+	}
+}
+
+func TestSuperviseRestartsCrashedProcess(t *testing.T) {
+	dir := t.TempDir()
+	pidDir := filepath.Join(t.TempDir(), "pids")
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = pidDir
+	oldDelay := initialRestartDelay
+	initialRestartDelay = 10 * time.Millisecond
+	defer func() {
+		pidfile.BaseDir = oldBaseDir
+		initialRestartDelay = oldDelay
+	}()
+
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{{
+			Name: "worker",
+			Path: dir,
+			Commands: config.Commands{Up: []config.CommandItem{{
+				Command:     "echo run >> runs.txt; exit 1",
+				Background:  true,
+				Restart:     config.RestartOnFailure,
+				MaxRestarts: 2,
+			}}},
+		}},
+	}
+
+	if err := Run(cfg, "up", "test-supervise"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	defer func() { _ = pidfile.KillAll("test-supervise") }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := Supervise(ctx, cfg, "test-supervise", 20*time.Millisecond); err != nil {
+		t.Fatalf("Supervise() error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "runs.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := strings.Count(string(data), "run"); runs != 3 {
+		t.Errorf("command ran %d times, want 3 (initial + 2 restarts)", runs)
+	}
+
+	entries, err := pidfile.Load("test-supervise", "worker")
+	if err != nil {
+		t.Fatalf("pidfile.Load() error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 PID entry, got %d", len(entries))
+	}
+	if entries[0].Restarts != 2 {
+		t.Errorf("Restarts = %d, want 2", entries[0].Restarts)
+	}
+
+	logPath, _ := pidfile.ProcLogFilePath("test-supervise", "worker", entries[0].PID)
+	logData, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("restarted process should continue the previous log: %v", err)
+	}
+	if !strings.Contains(string(logData), "restarting (2/2)") {
+		t.Errorf("log should contain restart markers, got %q", string(logData))
+	}
+}
+
+func TestSuperviseWaitsForRunLock(t *testing.T) {
+	dir := t.TempDir()
+	pidDir := filepath.Join(t.TempDir(), "pids")
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = pidDir
+	oldDelay := initialRestartDelay
+	initialRestartDelay = 10 * time.Millisecond
+	defer func() {
+		pidfile.BaseDir = oldBaseDir
+		initialRestartDelay = oldDelay
+	}()
+
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{{
+			Name: "worker",
+			Path: dir,
+			Commands: config.Commands{Up: []config.CommandItem{{
+				Command:     "echo run >> runs.txt; exit 1",
+				Background:  true,
+				Restart:     config.RestartOnFailure,
+				MaxRestarts: 1,
+			}}},
+		}},
+	}
+	if err := Run(cfg, "up", "test-supervise-lock"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	defer func() { _ = pidfile.KillAll("test-supervise-lock") }()
+	runs := func() int {
+		data, _ := os.ReadFile(filepath.Join(dir, "runs.txt"))
+		return strings.Count(string(data), "run")
+	}
+
+	// Another mdc command, e.g. "mdc down", works on the config.
+	lock, err := pidfile.AcquireRunLock("test-supervise-lock", false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if err := Supervise(ctx, cfg, "test-supervise-lock", 20*time.Millisecond); err != nil {
+		t.Fatalf("Supervise() error: %v", err)
+	}
+	if n := runs(); n != 1 {
+		t.Errorf("command ran %d times while the run lock was held, want 1", n)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Supervise(ctx, cfg, "test-supervise-lock", 20*time.Millisecond); err != nil {
+		t.Fatalf("Supervise() error: %v", err)
+	}
+	if n := runs(); n != 2 {
+		t.Errorf("command ran %d times after the run lock was released, want 2", n)
+	}
+}
+
+func TestSuperviseExitsWhenNothingIsLeft(t *testing.T) {
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	t.Cleanup(func() { logger.SetOutput(os.Stdout) })
+
+	dir := t.TempDir()
+	pidDir := filepath.Join(t.TempDir(), "pids")
+	oldBaseDir := pidfile.BaseDir
+	pidfile.BaseDir = pidDir
+	oldDelay := initialRestartDelay
+	initialRestartDelay = 10 * time.Millisecond
+	defer func() {
+		pidfile.BaseDir = oldBaseDir
+		initialRestartDelay = oldDelay
+	}()
+
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{{
+			Name: "worker",
+			Path: dir,
+			Commands: config.Commands{Up: []config.CommandItem{{
+				Command:     "exit 1",
+				Background:  true,
+				Restart:     config.RestartOnFailure,
+				MaxRestarts: 1,
+			}}},
+		}},
+	}
+	if err := Run(cfg, "up", "test-supervise-done"); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	defer func() { _ = pidfile.KillAll("test-supervise-done") }()
+
+	// The process stays tracked after giving up, so only the supervisor
+	// noticing that nothing is left ends the run.
+	done := make(chan error, 1)
+	go func() { done <- Supervise(context.Background(), cfg, "test-supervise-done", 20*time.Millisecond) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Supervise() error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Supervise() did not exit after the process reached max_restarts")
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, "reached max restarts"); n != 1 {
+		t.Errorf("giving up reported %d times, want once: %q", n, out)
+	}
+	if !strings.Contains(out, "no processes left to supervise") {
+		t.Errorf("output should end with the exit reason: %q", out)
+	}
+}
+
+func TestSuperviseWithoutPolicies(t *testing.T) {
+	cfg := &config.Config{
+		ExecutionMode: "sequential",
+		Projects: []config.Project{{
+			Name:     "svc",
+			Path:     t.TempDir(),
+			Commands: config.Commands{Up: []config.CommandItem{{Command: "sleep 60", Background: true}}},
+		}},
+	}
+	err := Supervise(context.Background(), cfg, "test-none", time.Millisecond)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "no background commands with a restart policy") {
+		t.Errorf("error = %q", err.Error())
+	}
+}
@@ -14,6 +14,9 @@ import (
 )
 
 func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == runner.ExecHelperCommand {
+		os.Exit(runner.RunExecHelper(os.Args[2:]))
+	}
 	logger.SetOutput(os.Stderr)
 	os.Exit(m.Run())
 }